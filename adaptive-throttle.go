@@ -0,0 +1,120 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"net/http"
+	"sync"
+)
+
+// adaptiveThrottle - limits the concurrent requests of a client once
+// the server throttles them. The limit is halved on throttling and
+// raised by one after a streak of successful requests, it is lifted
+// once back at the concurrency throttling engaged at.
+type adaptiveThrottle struct {
+	// mutex is used for handling the concurrent requests.
+	sync.Mutex
+
+	// Signaled once requests finish or the limit changes.
+	cond *sync.Cond
+
+	// Allowed concurrent requests, '0' while not throttled.
+	limit int
+
+	// Concurrent requests when throttling engaged.
+	peak int
+
+	// Requests in flight.
+	inFlight int
+
+	// Successful requests since the limit last changed.
+	successes int
+
+	// Incremented on each decrease of the limit, requests sent before
+	// a decrease do not decrease it again.
+	generation int
+
+	// Called with the new limit whenever it changes.
+	onChange func(limit int)
+}
+
+// newAdaptiveThrottle - Provides a new throttle which does not limit
+// requests until the server throttles them.
+func newAdaptiveThrottle(onChange func(limit int)) *adaptiveThrottle {
+	t := &adaptiveThrottle{onChange: onChange}
+	t.cond = sync.NewCond(t)
+	return t
+}
+
+// Acquire - blocks until the request may be sent, returns the
+// generation to pass to Release.
+func (t *adaptiveThrottle) Acquire() int {
+	t.Lock()
+	defer t.Unlock()
+	for t.limit > 0 && t.inFlight >= t.limit {
+		t.cond.Wait()
+	}
+	t.inFlight++
+	return t.generation
+}
+
+// Release - ends a request of the given generation, statusCode is the
+// status of its response or '0' if it failed without one.
+func (t *adaptiveThrottle) Release(generation, statusCode int) {
+	t.Lock()
+	prevLimit := t.limit
+	switch {
+	case isThrottleStatus(statusCode):
+		// Halve the limit once per generation, all the requests in
+		// flight are likely throttled together.
+		if generation == t.generation {
+			if t.limit == 0 {
+				t.peak = t.inFlight
+				t.limit = t.inFlight
+			}
+			t.limit /= 2
+			if t.limit < 1 {
+				t.limit = 1
+			}
+			t.generation++
+		}
+		t.successes = 0
+	case statusCode != 0 && statusCode < http.StatusInternalServerError && t.limit > 0:
+		t.successes++
+		if t.successes >= throttleRampUpRequests {
+			t.successes = 0
+			t.limit++
+			if t.limit > t.peak {
+				t.limit = 0
+			}
+		}
+	}
+	t.inFlight--
+	limit := t.limit
+	t.cond.Broadcast()
+	t.Unlock()
+
+	if limit != prevLimit && t.onChange != nil {
+		t.onChange(limit)
+	}
+}
+
+// isThrottleStatus - verify if the server throttled the request, Amazon
+// S3 responds with '503 SlowDown', others with '429 Too Many Requests'.
+func isThrottleStatus(statusCode int) bool {
+	return statusCode == http.StatusServiceUnavailable || statusCode == 429
+}
@@ -0,0 +1,639 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Source - container for a source object of ComposeObject, optionally
+// restricted to a byte range and guarded by copy conditions.
+type Source struct {
+	bucketName string
+	objectName string
+
+	// Byte range of the source object, start is -1 if the whole
+	// object is to be copied.
+	start, end int64
+
+	// Copy conditions, sent as x-amz-copy-source-if-* headers.
+	conditions http.Header
+}
+
+// NewSource - instantiate a new source object for ComposeObject.
+func NewSource(bucketName, objectName string) (Source, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return Source{}, err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return Source{}, err
+	}
+	return Source{
+		bucketName: bucketName,
+		objectName: objectName,
+		start:      -1,
+		conditions: make(http.Header),
+	}, nil
+}
+
+// SetRange - restrict the source to the inclusive byte range start to
+// end of the source object.
+func (s *Source) SetRange(start, end int64) error {
+	if start < 0 || end < start {
+		return ErrInvalidArgument(fmt.Sprintf("Invalid range start %d, end %d.", start, end))
+	}
+	s.start, s.end = start, end
+	return nil
+}
+
+// SetMatchETagCond - copy only if the source object ETag matches.
+func (s *Source) SetMatchETagCond(etag string) error {
+	if etag == "" {
+		return ErrInvalidArgument("ETag cannot be empty.")
+	}
+	s.conditions.Set("x-amz-copy-source-if-match", etag)
+	return nil
+}
+
+// SetMatchETagExceptCond - copy only if the source object ETag does
+// not match.
+func (s *Source) SetMatchETagExceptCond(etag string) error {
+	if etag == "" {
+		return ErrInvalidArgument("ETag cannot be empty.")
+	}
+	s.conditions.Set("x-amz-copy-source-if-none-match", etag)
+	return nil
+}
+
+// SetModifiedSinceCond - copy only if the source object was modified
+// since modTime.
+func (s *Source) SetModifiedSinceCond(modTime time.Time) error {
+	if modTime.IsZero() {
+		return ErrInvalidArgument("Modified since cannot be empty.")
+	}
+	s.conditions.Set("x-amz-copy-source-if-modified-since", modTime.UTC().Format(http.TimeFormat))
+	return nil
+}
+
+// SetUnmodifiedSinceCond - copy only if the source object was not
+// modified since modTime.
+func (s *Source) SetUnmodifiedSinceCond(modTime time.Time) error {
+	if modTime.IsZero() {
+		return ErrInvalidArgument("Unmodified since cannot be empty.")
+	}
+	s.conditions.Set("x-amz-copy-source-if-unmodified-since", modTime.UTC().Format(http.TimeFormat))
+	return nil
+}
+
+// copySource - returns the value of the x-amz-copy-source header.
+func (s Source) copySource() string {
+	return "/" + s.bucketName + "/" + urlEncodePath(s.objectName)
+}
+
+// Destination - container for the destination object of ComposeObject
+// and CopyObject.
+type Destination struct {
+	bucketName  string
+	objectName  string
+	contentType string
+
+	// Set to replace the metadata of the source object on CopyObject,
+	// user metadata is sent as x-amz-meta-* headers.
+	isMetadataReplaced bool
+	userMetadata       map[string]string
+
+	// Set to replace the tags of the source object on CopyObject.
+	isTaggingReplaced bool
+	tags              map[string]string
+
+	// Set to copy the ACL of the source object on CopyObject.
+	isACLPreserved bool
+}
+
+// NewDestination - instantiate a new destination object for
+// ComposeObject. An empty contentType uses the content type of the
+// first source object.
+func NewDestination(bucketName, objectName, contentType string) (Destination, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return Destination{}, err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return Destination{}, err
+	}
+	return Destination{
+		bucketName:  bucketName,
+		objectName:  objectName,
+		contentType: contentType,
+	}, nil
+}
+
+// ReplaceMetadata - CopyObject replaces the metadata of the source
+// object with the content type of the destination and userMetadata,
+// instead of copying it. At least one of them must be set.
+//
+// User metadata keys are sent as x-amz-meta-* headers, the prefix is
+// added if missing.
+func (d *Destination) ReplaceMetadata(userMetadata map[string]string) error {
+	if d.contentType == "" && len(userMetadata) == 0 {
+		return ErrInvalidArgument("Content type or user metadata must be set to replace metadata.")
+	}
+	for key := range userMetadata {
+		if strings.TrimSpace(key) == "" {
+			return ErrInvalidArgument("User metadata key cannot be empty.")
+		}
+	}
+	d.isMetadataReplaced = true
+	d.userMetadata = userMetadata
+	return nil
+}
+
+// ReplaceTags - CopyObject sets tags on the destination instead of
+// copying the tags of the source object, ComposeObject sets them on the
+// composed object. At least one tag must be set, tags are validated
+// like PutObjectTagging does.
+func (d *Destination) ReplaceTags(tags map[string]string) error {
+	if len(tags) == 0 {
+		return ErrInvalidArgument("Tags must be set to replace tags.")
+	}
+	if err := isValidObjectTags(tags); err != nil {
+		return err
+	}
+	d.isTaggingReplaced = true
+	d.tags = tags
+	return nil
+}
+
+// PreserveACL - CopyObject sets the ACL of the source object on the
+// destination, S3 gives copies the default private ACL otherwise. The
+// ACL is read before and set after copying, with two more requests.
+func (d *Destination) PreserveACL() {
+	d.isACLPreserved = true
+}
+
+// metadataHeader - returns the metadata directive and the replacing
+// metadata headers of CopyObject.
+func (d Destination) metadataHeader() http.Header {
+	header := make(http.Header)
+	if !d.isMetadataReplaced {
+		header.Set("x-amz-metadata-directive", "COPY")
+		return header
+	}
+	header.Set("x-amz-metadata-directive", "REPLACE")
+	if d.contentType != "" {
+		header.Set("Content-Type", d.contentType)
+	}
+	for key, value := range d.userMetadata {
+		if !strings.HasPrefix(strings.ToLower(key), "x-amz-meta-") {
+			key = "x-amz-meta-" + key
+		}
+		header.Set(key, value)
+	}
+	return header
+}
+
+// copyPart - a single upload part copy request of ComposeObject.
+type copyPart struct {
+	source     Source
+	start, end int64
+}
+
+// calculateCopyParts - splits sources of the given sizes into copy
+// parts no larger than maxPartSize. Every source except the last one
+// must be at least minPartSize bytes as required for multipart parts.
+func calculateCopyParts(sources []Source, sizes []int64) ([]copyPart, error) {
+	var copyParts []copyPart
+	var totalSize int64
+	for i, source := range sources {
+		size := sizes[i]
+		if size < minPartSize && i < len(sources)-1 {
+			return nil, ErrInvalidArgument(fmt.Sprintf("Source %d (%s/%s) is %d bytes, all but the last source must be at least %d bytes.",
+				i+1, source.bucketName, source.objectName, size, minPartSize))
+		}
+		totalSize += size
+		if totalSize > maxMultipartPutObjectSize {
+			return nil, ErrEntityTooLarge(totalSize, maxMultipartPutObjectSize, "", "")
+		}
+
+		// Source offset to start copying from.
+		start := source.start
+		if start < 0 {
+			start = 0
+		}
+
+		// Split source into equally sized parts no larger than
+		// maxPartSize.
+		partsCount := size / maxPartSize
+		if size%maxPartSize != 0 || partsCount == 0 {
+			partsCount++
+		}
+		partSize := size / partsCount
+		for j := int64(0); j < partsCount; j++ {
+			partLength := partSize
+			if j == partsCount-1 {
+				partLength = size - j*partSize
+			}
+			copyParts = append(copyParts, copyPart{
+				source: source,
+				start:  start + j*partSize,
+				end:    start + j*partSize + partLength - 1,
+			})
+		}
+	}
+	if len(copyParts) > maxPartsCount {
+		return nil, ErrInvalidArgument(fmt.Sprintf("Composing sources needs %d parts, more than the maximum of %d parts.", len(copyParts), maxPartsCount))
+	}
+	return copyParts, nil
+}
+
+// ComposeObject - creates the destination object by concatenating
+// all the sources server side using multipart upload part copy,
+// without downloading any data.
+//
+// Each source may be restricted to a byte range and guarded by copy
+// conditions. All sources except the last one must be at least 5MiB
+// in size.
+func (c Client) ComposeObject(dest Destination, sources []Source) error {
+	// Input validation.
+	if err := isValidBucketName(dest.bucketName); err != nil {
+		return err
+	}
+	if err := isValidObjectName(dest.objectName); err != nil {
+		return err
+	}
+	if len(sources) == 0 {
+		return ErrInvalidArgument("At least one source is required.")
+	}
+	if len(sources) > maxPartsCount {
+		return ErrInvalidArgument(fmt.Sprintf("Cannot compose more than %d sources.", maxPartsCount))
+	}
+
+	// Gather the size of every source.
+	sizes := make([]int64, len(sources))
+	contentType := dest.contentType
+	for i, source := range sources {
+		objInfo, err := c.StatObject(source.bucketName, source.objectName)
+		if err != nil {
+			return err
+		}
+		if contentType == "" {
+			contentType = objInfo.ContentType
+		}
+		sizes[i] = objInfo.Size
+		if source.start >= 0 {
+			if source.end >= objInfo.Size {
+				return ErrInvalidArgument(fmt.Sprintf("Range %d-%d is beyond the size %d of source %s/%s.",
+					source.start, source.end, objInfo.Size, source.bucketName, source.objectName))
+			}
+			sizes[i] = source.end - source.start + 1
+		}
+	}
+
+	// Calculate all the copy parts.
+	copyParts, err := calculateCopyParts(sources, sizes)
+	if err != nil {
+		return err
+	}
+
+	// Set the content type of the destination.
+	customHeader := make(http.Header)
+	customHeader.Set("Content-Type", contentType)
+	if dest.isTaggingReplaced {
+		customHeader.Set("x-amz-tagging", encodeTags(dest.tags))
+	}
+	return c.copyObjectParts(dest, customHeader, copyParts)
+}
+
+// copyObjectParts - creates the destination object with metadata set
+// in customHeader from the copy parts using multipart upload part copy.
+func (c Client) copyObjectParts(dest Destination, customHeader http.Header, copyParts []copyPart) error {
+	// Initiate a new multipart upload.
+	initMultipartUploadResult, err := c.initiateMultipartUploadWithHeader(dest.bucketName, dest.objectName, customHeader)
+	if err != nil {
+		return err
+	}
+	uploadID := initMultipartUploadResult.UploadID
+
+	// Copy all the parts.
+	var complMultipartUpload completeMultipartUpload
+	for i, part := range copyParts {
+		var objPart objectPart
+		objPart, err = c.uploadPartCopy(dest.bucketName, dest.objectName, uploadID, i+1, part)
+		if err != nil {
+			// Abort the multipart upload, do not leave behind
+			// partially composed objects.
+			c.abortMultipartUpload(dest.bucketName, dest.objectName, uploadID)
+			return err
+		}
+		complMultipartUpload.Parts = append(complMultipartUpload.Parts, completePart{
+			PartNumber: objPart.PartNumber,
+			ETag:       objPart.ETag,
+		})
+	}
+
+	// Sort all completed parts.
+	sort.Sort(completedParts(complMultipartUpload.Parts))
+	_, err = c.completeMultipartUpload(dest.bucketName, dest.objectName, uploadID, complMultipartUpload)
+	if err != nil {
+		c.abortMultipartUpload(dest.bucketName, dest.objectName, uploadID)
+		return err
+	}
+	return nil
+}
+
+// uploadPartCopy - copies a byte range of a source object as a part
+// of a multipart upload.
+func (c Client) uploadPartCopy(bucketName, objectName, uploadID string, partNumber int, part copyPart) (objectPart, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return objectPart{}, err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return objectPart{}, err
+	}
+	if partNumber <= 0 {
+		return objectPart{}, ErrInvalidArgument("Part number cannot be negative or equal to zero.")
+	}
+	if uploadID == "" {
+		return objectPart{}, ErrInvalidArgument("UploadID cannot be empty.")
+	}
+
+	// Get resources properly escaped and lined up before using them in http request.
+	urlValues := make(url.Values)
+	// Set part number.
+	urlValues.Set("partNumber", strconv.Itoa(partNumber))
+	// Set upload id.
+	urlValues.Set("uploadId", uploadID)
+
+	// Set copy source, range and conditions.
+	customHeader := make(http.Header)
+	for k, v := range part.source.conditions {
+		customHeader[k] = v
+	}
+	source := part.source
+	if c.isObjectNameNormalized {
+		source.objectName = normalizeObjectName(source.objectName)
+	}
+	customHeader.Set("x-amz-copy-source", source.copySource())
+	// Empty sources are copied without a range.
+	if part.end >= part.start {
+		customHeader.Set("x-amz-copy-source-range", fmt.Sprintf("bytes=%d-%d", part.start, part.end))
+	}
+
+	// Execute PUT to copy the part.
+	resp, err := c.executeMethod("PUT", requestMetadata{
+		bucketName:   bucketName,
+		objectName:   objectName,
+		queryValues:  urlValues,
+		customHeader: customHeader,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return objectPart{}, err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return objectPart{}, httpRespToErrorResponse(resp, bucketName, objectName)
+		}
+	}
+
+	// Decode copy part result, errors may also be reported with a
+	// 200 OK status.
+	copyResult := copyObjectResult{}
+	err = xmlDecoder(resp.Body, &copyResult)
+	if err != nil {
+		return objectPart{}, err
+	}
+	if copyResult.ETag == "" {
+		return objectPart{}, ErrorResponse{
+			Code:       "InternalError",
+			Message:    "Copy part response is missing ETag. " + reportIssue,
+			BucketName: bucketName,
+			Key:        objectName,
+			RequestID:  resp.Header.Get("x-amz-request-id"),
+			HostID:     resp.Header.Get("x-amz-id-2"),
+		}
+	}
+
+	// Once successfully copied, return completed part.
+	objPart := objectPart{}
+	objPart.Size = part.end - part.start + 1
+	objPart.PartNumber = partNumber
+	// Trim off the odd double quotes from ETag in the beginning and end.
+	objPart.ETag = strings.TrimPrefix(copyResult.ETag, "\"")
+	objPart.ETag = strings.TrimSuffix(objPart.ETag, "\"")
+	return objPart, nil
+}
+
+// CopyObject - copies the source object to the destination server side
+// with a single request. Source objects larger than 5GiB are refused by
+// the server, they are then looked up with HEAD and copied with
+// multipart upload part copy instead, which needs permission to read
+// the source object metadata too. Use ComposeObject for byte ranges.
+//
+// Metadata of the source object, including its content type, is
+// copied unless the destination is set to replace it with
+// ReplaceMetadata. Copying an object onto itself, e.g. to fix its
+// content type in place, requires replacing its metadata. The ACL of
+// the source object is copied only if PreserveACL is set on the
+// destination.
+func (c Client) CopyObject(dest Destination, source Source) error {
+	// Input validation.
+	if err := isValidBucketName(dest.bucketName); err != nil {
+		return err
+	}
+	if err := isValidObjectName(dest.objectName); err != nil {
+		return err
+	}
+	if err := isValidBucketName(source.bucketName); err != nil {
+		return err
+	}
+	if err := isValidObjectName(source.objectName); err != nil {
+		return err
+	}
+	if source.start >= 0 {
+		return ErrInvalidArgument("Source range is not supported by CopyObject, use ComposeObject.")
+	}
+	if source.bucketName == dest.bucketName && source.objectName == dest.objectName && !dest.isMetadataReplaced {
+		return ErrInvalidArgument("Copying an object onto itself requires replacing its metadata.")
+	}
+
+	// Read the ACL of the source before copying, a failure leaves the
+	// destination untouched.
+	var acp AccessControlPolicy
+	if dest.isACLPreserved {
+		var err error
+		acp, err = c.GetObjectACLPolicy(source.bucketName, source.objectName)
+		if err != nil {
+			return err
+		}
+	}
+	if err := c.copyObject(dest, source); err != nil {
+		return err
+	}
+	if dest.isACLPreserved {
+		return c.SetObjectACLPolicy(dest.bucketName, dest.objectName, acp)
+	}
+	return nil
+}
+
+// copyObject - copies the source object to the destination, in parts
+// if it is larger than a single copy request allows.
+func (c Client) copyObject(dest Destination, source Source) error {
+	err := c.copyObjectSingle(dest, source)
+
+	// Objects larger than a single copy request allows are refused,
+	// their size is looked up only then to copy them in parts.
+	if code := ToErrorResponse(err).Code; code != "InvalidRequest" && code != "EntityTooLarge" {
+		return err
+	}
+	objInfo, objHeader, serr := c.statObject(source.bucketName, source.objectName, "")
+	if serr != nil || objInfo.Size <= maxSinglePutObjectSize {
+		return err
+	}
+	return c.copyObjectMultipart(dest, source, objInfo, objHeader)
+}
+
+// copyObjectSingle - copies the source object to the destination with
+// a single request.
+func (c Client) copyObjectSingle(dest Destination, source Source) error {
+	// Set copy source, conditions, metadata and tags.
+	customHeader := dest.metadataHeader()
+	for k, v := range source.conditions {
+		customHeader[k] = v
+	}
+	if dest.isTaggingReplaced {
+		customHeader.Set("x-amz-tagging-directive", "REPLACE")
+		customHeader.Set("x-amz-tagging", encodeTags(dest.tags))
+	} else {
+		customHeader.Set("x-amz-tagging-directive", "COPY")
+	}
+	if c.isObjectNameNormalized {
+		source.objectName = normalizeObjectName(source.objectName)
+	}
+	customHeader.Set("x-amz-copy-source", source.copySource())
+
+	// Execute PUT to copy the object.
+	resp, err := c.executeMethod("PUT", requestMetadata{
+		bucketName:   dest.bucketName,
+		objectName:   dest.objectName,
+		customHeader: customHeader,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return httpRespToErrorResponse(resp, dest.bucketName, dest.objectName)
+		}
+	}
+
+	// Decode copy object result, errors may also be reported with a
+	// 200 OK status.
+	copyResult := copyObjectResult{}
+	err = xmlDecoder(resp.Body, &copyResult)
+	if err != nil {
+		return err
+	}
+	if copyResult.ETag == "" {
+		return ErrorResponse{
+			Code:       "InternalError",
+			Message:    "Copy object response is missing ETag. " + reportIssue,
+			BucketName: dest.bucketName,
+			Key:        dest.objectName,
+			RequestID:  resp.Header.Get("x-amz-request-id"),
+			HostID:     resp.Header.Get("x-amz-id-2"),
+		}
+	}
+	return nil
+}
+
+// MoveObject - moves the source object to the destination, S3 has no
+// move operation. The source is copied with CopyObject, then removed
+// only once the copy succeeded.
+//
+// Metadata of the source object is kept unless the destination is set
+// to replace it with ReplaceMetadata, its ACL only if the destination
+// is set to PreserveACL. The move is not atomic, if the
+// source cannot be removed the error is returned and the object exists
+// at both the source and the destination.
+func (c Client) MoveObject(dest Destination, source Source) error {
+	// Removing the source after copying it onto itself would delete
+	// the object.
+	if source.bucketName == dest.bucketName && source.objectName == dest.objectName {
+		return ErrInvalidArgument("Source and destination of a move cannot be the same object.")
+	}
+	if err := c.CopyObject(dest, source); err != nil {
+		return err
+	}
+	return c.RemoveObject(source.bucketName, source.objectName)
+}
+
+// copyObjectMultipart - copies a source object larger than 5GiB in
+// parts of up to 5GiB. Multipart uploads do not copy metadata or tags,
+// the content type, user metadata and tags of the source are set on the
+// destination unless they are replaced.
+func (c Client) copyObjectMultipart(dest Destination, source Source, objInfo ObjectInfo, objHeader http.Header) error {
+	customHeader := make(http.Header)
+	if dest.isMetadataReplaced {
+		customHeader = dest.metadataHeader()
+		customHeader.Del("x-amz-metadata-directive")
+	} else {
+		customHeader.Set("Content-Type", objInfo.ContentType)
+		if objInfo.ContentEncoding != "" {
+			customHeader.Set("Content-Encoding", objInfo.ContentEncoding)
+		}
+		for k, v := range objHeader {
+			if strings.HasPrefix(strings.ToLower(k), "x-amz-meta-") {
+				customHeader[k] = v
+			}
+		}
+	}
+	tags := dest.tags
+	if !dest.isTaggingReplaced && objHeader.Get("x-amz-tagging-count") != "" && objHeader.Get("x-amz-tagging-count") != "0" {
+		var err error
+		if tags, err = c.GetObjectTagging(source.bucketName, source.objectName); err != nil {
+			return err
+		}
+	}
+	if len(tags) > 0 {
+		customHeader.Set("x-amz-tagging", encodeTags(tags))
+	}
+
+	// Fail the copy if the source changes between parts, unless the
+	// caller set its own conditions.
+	if len(source.conditions) == 0 && objInfo.ETag != "" {
+		// Conditions are shared with the caller's source, set
+		// them on a new header.
+		source.conditions = make(http.Header)
+		source.conditions.Set("x-amz-copy-source-if-match", objInfo.ETag)
+	}
+
+	copyParts, err := calculateCopyParts([]Source{source}, []int64{objInfo.Size})
+	if err != nil {
+		return err
+	}
+	return c.copyObjectParts(dest, customHeader, copyParts)
+}
@@ -38,6 +38,9 @@ type ObjectInfo struct {
 	Size         int64     `json:"size"`         // Size in bytes of the object.
 	ContentType  string    `json:"contentType"`  // A standard MIME type describing the format of the object data.
 
+	// Content encoding of the object data, for example 'gzip'.
+	ContentEncoding string `json:"contentEncoding"`
+
 	// Owner name.
 	Owner struct {
 		DisplayName string `json:"name"`
@@ -47,7 +50,31 @@ type ObjectInfo struct {
 	// The class of storage used to store the object.
 	StorageClass string `json:"storageClass"`
 
-	// Error
+	// Version id of the object, only set for versioned buckets.
+	VersionID string `json:"versionId" xml:"VersionId"`
+	// Set if this version is the latest version of the object, only
+	// set while listing object versions.
+	IsLatest bool `json:"isLatest"`
+	// Set if this version is a delete marker, only set while listing
+	// object versions.
+	IsDeleteMarker bool `json:"isDeleteMarker"`
+
+	// Expiration of the object by a bucket lifecycle rule, the raw
+	// 'x-amz-expiration' header, e.g. 'expiry-date="Fri, 23 Dec 2016
+	// 00:00:00 GMT", rule-id="cleanup"'. Only set by PutObjectWithInfo
+	// and StatObject.
+	Expiration string `json:"expiration,omitempty"`
+
+	// Time the object becomes stale for caches, from the 'Expires'
+	// header. Only set by StatObject.
+	Expires time.Time `json:"expires,omitempty"`
+
+	// Restore status of archived objects, nil unless restored or
+	// being restored. Only set by StatObject.
+	Restore *RestoreInfo `json:"restore,omitempty"`
+
+	// Error of listings, set on the last value sent before the
+	// listing channel is closed.
 	Err error `json:"-"`
 }
 
@@ -74,3 +101,19 @@ type ObjectMultipartInfo struct {
 	// Error
 	Err error
 }
+
+// BucketLocationError container for a bucket whose location could not
+// be resolved.
+type BucketLocationError struct {
+	BucketName string
+	Err        error
+}
+
+// IncompleteUploadsInfo container for the number and total size of
+// incomplete uploads.
+type IncompleteUploadsInfo struct {
+	// Number of incomplete uploads.
+	Count int
+	// Total size in bytes of the uploaded parts.
+	Size int64
+}
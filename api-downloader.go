@@ -0,0 +1,275 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Downloader - downloads objects to local files, splitting large
+// objects into ranges downloaded in parallel.
+//
+// Set the fields before the first Download, a Downloader may then be
+// used by concurrent downloads.
+type Downloader struct {
+	// Size of the ranges downloaded in parallel, '0' uses 16MiB.
+	ChunkSize int64
+
+	// Number of ranges downloaded in parallel, '0' and '1' download
+	// the object with a single request.
+	Concurrency int
+
+	// Client of all the downloads.
+	client Client
+}
+
+// NewDownloader - instantiate a new downloader using client for all
+// the requests, objects are downloaded with a single request by
+// default.
+func NewDownloader(client Client) *Downloader {
+	return &Downloader{
+		Concurrency: 1,
+		client:      client,
+	}
+}
+
+// fileChunkWriter - writes to file from offset onwards, chunks of a
+// file are written concurrently.
+type fileChunkWriter struct {
+	file   *os.File
+	offset int64
+}
+
+// Write - writes p at the current offset and advances it.
+func (w *fileChunkWriter) Write(p []byte) (n int, err error) {
+	n, err = w.file.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
+
+// Download - downloads objectName to filePath. Objects larger than
+// ChunkSize are split into ranges downloaded by Concurrency requests
+// in parallel, each written at its offset of a preallocated file.
+//
+// Servers not supporting ranges are read with a single request. Ranges
+// are requested with 'If-Range', the download is restarted from scratch
+// if the object is overwritten meanwhile. It fails with ErrObjectChanged
+// once the object keeps changing after 3 restarts.
+func (d *Downloader) Download(bucketName, objectName, filePath string) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return err
+	}
+	if d.Concurrency < 0 {
+		return ErrInvalidArgument("Concurrency cannot be negative.")
+	}
+	if d.ChunkSize < 0 {
+		return ErrInvalidArgument("Chunk size cannot be negative.")
+	}
+
+	c := d.client
+	chunkSize := d.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = downloadChunkSize
+	}
+
+	// Verify if destination already exists.
+	st, err := os.Stat(filePath)
+	if err == nil {
+		// If the destination exists and is a directory.
+		if st.IsDir() {
+			return ErrInvalidArgument("fileName is a directory.")
+		}
+	}
+
+	// Proceed if file does not exist. return for all other errors.
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	// Extract top level direcotry.
+	objectDir, _ := filepath.Split(filePath)
+	if objectDir != "" {
+		// Create any missing top level directories.
+		if err = os.MkdirAll(objectDir, 0700); err != nil {
+			return err
+		}
+	}
+
+	for restarts := 0; ; restarts++ {
+		// Small objects are downloaded as usual.
+		objectStat, err := c.StatObject(bucketName, objectName)
+		if err != nil {
+			return err
+		}
+		if d.Concurrency <= 1 || objectStat.Size <= chunkSize {
+			return c.FGetObject(bucketName, objectName, filePath)
+		}
+
+		// Restart the download from scratch if the object is
+		// overwritten meanwhile.
+		err = c.downloadFile(bucketName, objectName, filePath, objectStat, chunkSize, d.Concurrency)
+		if restarts < maxDownloadRestarts && ToErrorResponse(err).Code == "PreconditionFailed" {
+			continue
+		}
+		return err
+	}
+}
+
+// downloadFile - downloads the object to filePath through a temporary
+// file, which is removed if the download fails.
+func (c Client) downloadFile(bucketName, objectName, filePath string, objectStat ObjectInfo, chunkSize int64, concurrency int) error {
+	// Write to a temporary file "fileName.download.minio" before
+	// saving, chunks are written at their offsets of the preallocated
+	// file.
+	fileDownloadPath := filePath + objectStat.ETag + ".download.minio"
+	fileDownload, err := os.OpenFile(fileDownloadPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err = fileDownload.Truncate(objectStat.Size); err == nil {
+		err = c.downloadChunks(bucketName, objectName, objectStat, fileDownload, chunkSize, concurrency)
+	}
+
+	// Flush the data to disk before rename, otherwise a crash may
+	// leave behind a complete file with missing data.
+	if err == nil && !c.isFileSyncDisabled {
+		err = fileDownload.Sync()
+	}
+
+	// Close the file before rename, this is specifically needed for
+	// Windows users.
+	if cerr := fileDownload.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(fileDownloadPath)
+		return err
+	}
+
+	// Safely completed. Now commit by renaming to actual filename.
+	if err = os.Rename(fileDownloadPath, filePath); err != nil {
+		return err
+	}
+
+	// Flush the rename to disk.
+	if !c.isFileSyncDisabled {
+		return syncDir(filepath.Dir(filePath))
+	}
+	return nil
+}
+
+// downloadChunks - downloads the object into file with up to
+// concurrency ranged requests in parallel. The object is read with a
+// single request if the server ignores the range of the first chunk.
+func (c Client) downloadChunks(bucketName, objectName string, objectStat ObjectInfo, file *os.File, chunkSize int64, concurrency int) error {
+	// downloadChunk - writes the range of the object starting at
+	// offset, the whole object if the server ignores the range.
+	downloadChunk := func(offset int64) (isPartial bool, err error) {
+		length := chunkSize
+		if offset+length > objectStat.Size {
+			length = objectStat.Size - offset
+		}
+		reader, chunkStat, isPartial, err := c.getObjectPartial(bucketName, objectName, "", offset, length, objectStat.ETag)
+		if err != nil {
+			return false, err
+		}
+		defer reader.Close()
+
+		// All the chunks must be of the same object, servers return
+		// the whole object once it changed.
+		if chunkStat.ETag != objectStat.ETag {
+			return isPartial, ErrObjectChanged(objectStat.ETag, chunkStat.ETag, bucketName, objectName)
+		}
+		if !isPartial {
+			if offset != 0 {
+				return false, ErrAPINotSupported("Range requests are not supported consistently by the server.")
+			}
+			length = objectStat.Size
+		}
+		n, err := io.CopyN(&fileChunkWriter{file: file, offset: offset}, reader, length)
+		if err == io.EOF {
+			return isPartial, ErrUnexpectedEOF(n, length, bucketName, objectName)
+		}
+		return isPartial, err
+	}
+
+	// First chunk tells whether the server supports ranges, the object
+	// is already downloaded otherwise.
+	isPartial, err := downloadChunk(0)
+	if err != nil || !isPartial {
+		return err
+	}
+
+	// Rest of the chunks are downloaded by concurrency workers, the
+	// first error stops the download.
+	offsetCh := make(chan int64)
+	var mutex sync.Mutex
+	var downloadErr error
+	failed := func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return downloadErr != nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for offset := range offsetCh {
+				// Drain the remaining chunks once a download failed.
+				if failed() {
+					continue
+				}
+				if _, err := downloadChunk(offset); err != nil {
+					mutex.Lock()
+					if downloadErr == nil {
+						downloadErr = err
+					}
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+	for offset := chunkSize; offset < objectStat.Size && !failed(); offset += chunkSize {
+		offsetCh <- offset
+	}
+	close(offsetCh)
+	wg.Wait()
+	if downloadErr != nil {
+		return downloadErr
+	}
+
+	// Verify if we downloaded all the data.
+	st, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if st.Size() != objectStat.Size {
+		return ErrUnexpectedEOF(st.Size(), objectStat.Size, bucketName, objectName)
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 /* **** SAMPLE ERROR RESPONSE ****
@@ -50,6 +51,19 @@ type ErrorResponse struct {
 	Region string
 }
 
+// detailedErrorResponse - error response of Google Cloud Storage,
+// which carries additional details of the error.
+//
+// <Error>
+//   <Code>NoSuchKey</Code>
+//   <Message>The specified key does not exist.</Message>
+//   <Details>No such object: bucketName/objectName</Details>
+// </Error>
+type detailedErrorResponse struct {
+	ErrorResponse
+	Details string
+}
+
 // ToErrorResponse - Returns parsed ErrorResponse struct from body and
 // http headers.
 //
@@ -72,6 +86,17 @@ func ToErrorResponse(err error) ErrorResponse {
 	}
 }
 
+// SetErrorResponseParser - set a custom parser for error responses of
+// servers which do not follow the Amazon S3 error schema, e.g. older
+// Ceph RGW gateways. The parser is called with the status code,
+// headers and body of every error response and the ErrorResponse it
+// returns is reported by the API operations. Returning an
+// ErrorResponse with an empty Code keeps the default parsing, a nil
+// parser restores it for all responses.
+func (c *Client) SetErrorResponseParser(parser func(statusCode int, header http.Header, body []byte) ErrorResponse) {
+	c.errorResponseParser = parser
+}
+
 // Error - Returns HTTP error string
 func (e ErrorResponse) Error() string {
 	return e.Message
@@ -91,7 +116,17 @@ func httpRespToErrorResponse(resp *http.Response, bucketName, objectName string)
 		return ErrInvalidArgument(msg)
 	}
 	var errResp ErrorResponse
-	err := xmlDecoder(resp.Body, &errResp)
+	var detailedErrResp detailedErrorResponse
+	err := xmlDecoder(resp.Body, &detailedErrResp)
+	errResp = detailedErrResp.ErrorResponse
+	if detailedErrResp.Details != "" {
+		errResp.Message = strings.TrimSpace(errResp.Message + " " + detailedErrResp.Details)
+	}
+	// Redirects to the region of a bucket carry the region only as
+	// header.
+	if errResp.Region == "" {
+		errResp.Region = resp.Header.Get("x-amz-bucket-region")
+	}
 	// Xml decoding failed with no body, fall back to HTTP headers.
 	if err != nil {
 		switch resp.StatusCode {
@@ -126,6 +161,26 @@ func httpRespToErrorResponse(resp *http.Response, bucketName, objectName string)
 				HostID:     resp.Header.Get("x-amz-id-2"),
 				Region:     resp.Header.Get("x-amz-bucket-region"),
 			}
+		case http.StatusRequestedRangeNotSatisfiable:
+			errResp = ErrorResponse{
+				Code:       "InvalidRange",
+				Message:    "The requested range is not satisfiable.",
+				BucketName: bucketName,
+				Key:        objectName,
+				RequestID:  resp.Header.Get("x-amz-request-id"),
+				HostID:     resp.Header.Get("x-amz-id-2"),
+				Region:     resp.Header.Get("x-amz-bucket-region"),
+			}
+		case http.StatusPreconditionFailed:
+			errResp = ErrorResponse{
+				Code:       "PreconditionFailed",
+				Message:    "At least one of the pre-conditions you specified did not hold.",
+				BucketName: bucketName,
+				Key:        objectName,
+				RequestID:  resp.Header.Get("x-amz-request-id"),
+				HostID:     resp.Header.Get("x-amz-id-2"),
+				Region:     resp.Header.Get("x-amz-bucket-region"),
+			}
 		case http.StatusConflict:
 			errResp = ErrorResponse{
 				Code:       "Conflict",
@@ -146,9 +201,30 @@ func httpRespToErrorResponse(resp *http.Response, bucketName, objectName string)
 			}
 		}
 	}
+	// Amazon S3 does not tell denials for an unexpected bucket owner
+	// apart from others.
+	if errResp.Code == "AccessDenied" && resp.Request != nil {
+		if owner := resp.Request.Header.Get("x-amz-expected-bucket-owner"); owner != "" {
+			errResp.Message = strings.TrimSpace(errResp.Message + " The bucket may not be owned by the expected owner " + owner + ".")
+		}
+	}
 	return errResp
 }
 
+// httpRespToAPIErrorResponse is similar to httpRespToErrorResponse
+// but reports servers which do not implement the requested API as
+// ErrAPINotSupported.
+func httpRespToAPIErrorResponse(resp *http.Response, bucketName, objectName, apiName string) error {
+	err := httpRespToErrorResponse(resp, bucketName, objectName)
+	if resp != nil && resp.StatusCode == http.StatusNotImplemented {
+		return ErrAPINotSupported(apiName + " is not supported by the server.")
+	}
+	if ToErrorResponse(err).Code == "NotImplemented" {
+		return ErrAPINotSupported(apiName + " is not supported by the server.")
+	}
+	return err
+}
+
 // ErrEntityTooLarge - Input size is larger than supported maximum.
 func ErrEntityTooLarge(totalSize, maxObjectSize int64, bucketName, objectName string) error {
 	msg := fmt.Sprintf("Your proposed upload size ‘%d’ exceeds the maximum allowed object size ‘%d’ for single PUT operation.", totalSize, maxObjectSize)
@@ -183,6 +259,30 @@ func ErrUnexpectedEOF(totalRead, totalSize int64, bucketName, objectName string)
 	}
 }
 
+// ErrMultipartETagMismatch - ETag of a completed multipart upload
+// differs from the ETag computed from the uploaded parts.
+func ErrMultipartETagMismatch(expectedETag, etag, bucketName, objectName string) error {
+	msg := fmt.Sprintf("Multipart upload ETag ‘%s’ is not equal to the computed ETag ‘%s’ of the uploaded parts.", etag, expectedETag)
+	return ErrorResponse{
+		Code:       "BadDigest",
+		Message:    msg,
+		BucketName: bucketName,
+		Key:        objectName,
+	}
+}
+
+// ErrObjectChanged - ETag of an object changed while it was being
+// downloaded, the downloaded ranges are inconsistent.
+func ErrObjectChanged(expectedETag, etag, bucketName, objectName string) error {
+	msg := fmt.Sprintf("Object ETag changed from ‘%s’ to ‘%s’ during download.", expectedETag, etag)
+	return ErrorResponse{
+		Code:       "PreconditionFailed",
+		Message:    msg,
+		BucketName: bucketName,
+		Key:        objectName,
+	}
+}
+
 // ErrInvalidBucketName - Invalid bucket name response.
 func ErrInvalidBucketName(message string) error {
 	return ErrorResponse{
@@ -215,6 +315,25 @@ func ErrInvalidParts(expectedParts, uploadedParts int) error {
 // similar to object name response.
 var ErrInvalidObjectPrefix = ErrInvalidObjectName
 
+// ErrAPINotSupported - API not supported by the server response.
+func ErrAPINotSupported(message string) error {
+	return ErrorResponse{
+		Code:      "NotImplemented",
+		Message:   message,
+		RequestID: "minio",
+	}
+}
+
+// ErrEndpointUnreachable - Endpoint could not be reached response, for
+// DNS and connection failures.
+func ErrEndpointUnreachable(endpoint string, err error) error {
+	return ErrorResponse{
+		Code:      "EndpointUnreachable",
+		Message:   "Endpoint " + endpoint + " is unreachable: " + err.Error(),
+		RequestID: "minio",
+	}
+}
+
 // ErrInvalidArgument - Invalid argument response.
 func ErrInvalidArgument(message string) error {
 	return ErrorResponse{
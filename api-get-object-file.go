@@ -17,9 +17,11 @@
 package minio
 
 import (
+	"context"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 )
 
 // FGetObject - download contents of an object to a local file.
@@ -79,14 +81,40 @@ func (c Client) FGetObject(bucketName, objectName, filePath string) error {
 	}
 
 	// Seek to current position for incoming reader.
-	objectReader, objectStat, err := c.getObject(bucketName, objectName, st.Size(), 0)
+	offset := st.Size()
+	objectReader, readerStat, isPartial, err := c.getObjectPartial(bucketName, objectName, "", offset, 0, "")
 	if err != nil {
-		return err
+		filePart.Close()
+		return c.removeCancelledPart(filePartPath, err)
 	}
+	defer objectReader.Close()
 
-	// Write to the part file.
-	if _, err = io.CopyN(filePart, objectReader, objectStat.Size); err != nil {
-		return err
+	// Object size is the total size for ranged reads. Servers ignoring
+	// the range send the whole object, the part file is started over.
+	if !isPartial && offset > 0 {
+		if err = filePart.Truncate(0); err != nil {
+			filePart.Close()
+			return err
+		}
+		offset = 0
+	}
+	if readerStat.Size < 0 {
+		readerStat.Size = objectStat.Size
+	}
+
+	// Write the remaining object data to the part file.
+	if _, err = io.CopyN(filePart, objectReader, readerStat.Size-offset); err != nil {
+		filePart.Close()
+		return c.removeCancelledPart(filePartPath, err)
+	}
+
+	// Flush the data to disk before rename, otherwise a crash may
+	// leave behind a complete file with missing data.
+	if !c.isFileSyncDisabled {
+		if err = filePart.Sync(); err != nil {
+			filePart.Close()
+			return err
+		}
 	}
 
 	// Close the file before rename, this is specifically needed for Windows users.
@@ -99,6 +127,54 @@ func (c Client) FGetObject(bucketName, objectName, filePath string) error {
 		return err
 	}
 
+	// Flush the rename to disk.
+	if !c.isFileSyncDisabled {
+		if err = syncDir(filepath.Dir(filePath)); err != nil {
+			return err
+		}
+	}
+
 	// Return.
 	return nil
 }
+
+// FGetObjectWithContext - download contents of an object to a local
+// file like FGetObject, the download is cancelled once ctx is done. The
+// partially downloaded file is removed, the error of ctx is returned.
+func (c Client) FGetObjectWithContext(ctx context.Context, bucketName, objectName, filePath string) error {
+	if ctx == nil {
+		return ErrInvalidArgument("Context cannot be nil.")
+	}
+	return c.withContext(ctx).FGetObject(bucketName, objectName, filePath)
+}
+
+// removeCancelledPart - removes the part file of a download cancelled
+// by its context and returns the error of the context, downloads
+// failed otherwise keep it to resume from and return err.
+func (c Client) removeCancelledPart(filePartPath string, err error) error {
+	if c.ctx == nil || c.ctx.Err() == nil {
+		return err
+	}
+	if rerr := os.Remove(filePartPath); rerr != nil && !os.IsNotExist(rerr) {
+		return rerr
+	}
+	return c.ctx.Err()
+}
+
+// syncDir - flushes the entries of directory dirPath to disk. Windows
+// does not support syncing directories, renames are flushed with the
+// file system journal.
+func syncDir(dirPath string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	dir, err := os.Open(dirPath)
+	if err != nil {
+		return err
+	}
+	if err = dir.Sync(); err != nil {
+		dir.Close()
+		return err
+	}
+	return dir.Close()
+}
@@ -17,11 +17,14 @@
 package minio
 
 import (
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -63,7 +66,7 @@ func (c Client) GetBucketACL(bucketName string) (BucketACL, error) {
 	}
 
 	// Decode access control policy.
-	policy := accessControlPolicy{}
+	policy := AccessControlPolicy{}
 	err = xmlDecoder(resp.Body, &policy)
 	if err != nil {
 		return "", err
@@ -73,8 +76,8 @@ func (c Client) GetBucketACL(bucketName string) (BucketACL, error) {
 	// Cloud Storage. On Google Cloud Storage "private" canned ACL's
 	// policy do not have grant list. Treat it as a valid case, check
 	// for all other vendors.
-	if !isGoogleEndpoint(c.endpointURL) {
-		if policy.AccessControlList.Grant == nil {
+	if !c.isGoogleCompatible() {
+		if policy.Grants == nil {
 			errorResponse := ErrorResponse{
 				Code:       "InternalError",
 				Message:    "Access control Grant list is empty. " + reportIssue,
@@ -87,52 +90,136 @@ func (c Client) GetBucketACL(bucketName string) (BucketACL, error) {
 		}
 	}
 
-	// Boolean cues to indentify right canned acls.
-	var publicRead, publicWrite, authenticatedRead bool
-
-	// Handle grants.
-	grants := policy.AccessControlList.Grant
-	for _, g := range grants {
-		if g.Grantee.URI == "" && g.Permission == "FULL_CONTROL" {
-			continue
-		}
-		if g.Grantee.URI == "http://acs.amazonaws.com/groups/global/AuthenticatedUsers" && g.Permission == "READ" {
-			authenticatedRead = true
-			break
-		} else if g.Grantee.URI == "http://acs.amazonaws.com/groups/global/AllUsers" && g.Permission == "WRITE" {
-			publicWrite = true
-		} else if g.Grantee.URI == "http://acs.amazonaws.com/groups/global/AllUsers" && g.Permission == "READ" {
-			publicRead = true
-		}
+	// Summarize grants as canned acl.
+	if acl, ok := policy.cannedACL(); ok {
+		return acl, nil
 	}
 
-	// Verify if acl is authenticated read.
-	if authenticatedRead {
-		return BucketACL("authenticated-read"), nil
+	return "", ErrorResponse{
+		Code:       "NoSuchBucketPolicy",
+		Message:    "The specified bucket does not have a bucket policy.",
+		BucketName: bucketName,
+		RequestID:  "minio",
 	}
-	// Verify if acl is private.
-	if !publicWrite && !publicRead {
-		return BucketACL("private"), nil
+}
+
+// GetBucketACLPolicy - Get the full access control policy of a bucket,
+// with the owner and all the explicit grants.
+//
+// Use it to inspect grants to specific users, which GetBucketACL can
+// only summarize as a canned ACL.
+func (c Client) GetBucketACLPolicy(bucketName string) (AccessControlPolicy, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return AccessControlPolicy{}, err
 	}
-	// Verify if acl is public-read.
-	if !publicWrite && publicRead {
-		return BucketACL("public-read"), nil
+	return c.getAccessControlPolicy(bucketName, "")
+}
+
+// IsBucketPublic - reports whether anonymous users may list the bucket,
+// i.e. its ACL grants read access to all users. Private buckets are
+// reported as false without error.
+//
+// Only the ACL is inspected, access granted by a bucket policy is not
+// reported.
+func (c Client) IsBucketPublic(bucketName string) (bool, error) {
+	policy, err := c.GetBucketACLPolicy(bucketName)
+	if err != nil {
+		return false, err
 	}
-	// Verify if acl is public-read-write.
-	if publicRead && publicWrite {
-		return BucketACL("public-read-write"), nil
+	return policy.isPublicRead(), nil
+}
+
+// IsObjectPublic - reports whether anonymous users may read the object,
+// i.e. its ACL grants read access to all users. Private objects are
+// reported as false without error.
+//
+// Only the ACL of the object is inspected, access granted by a bucket
+// policy is not reported.
+func (c Client) IsObjectPublic(bucketName, objectName string) (bool, error) {
+	policy, err := c.GetObjectACLPolicy(bucketName, objectName)
+	if err != nil {
+		return false, err
 	}
+	return policy.isPublicRead(), nil
+}
 
+// GetObjectACL - Get the canned ACL of an object, objects have ACLs of
+// their own independent of the ACL of their bucket.
+//
+// Returned values are the canned ACLs of GetBucketACL. Grants which
+// cannot be summarized as a canned ACL are reported as ErrorResponse
+// with Code 'InvalidACL', use GetObjectACLPolicy to inspect them.
+func (c Client) GetObjectACL(bucketName, objectName string) (BucketACL, error) {
+	policy, err := c.GetObjectACLPolicy(bucketName, objectName)
+	if err != nil {
+		return "", err
+	}
+	if acl, ok := policy.cannedACL(); ok {
+		return acl, nil
+	}
 	return "", ErrorResponse{
-		Code:       "NoSuchBucketPolicy",
-		Message:    "The specified bucket does not have a bucket policy.",
+		Code:       "InvalidACL",
+		Message:    "The object ACL does not match any canned ACL.",
 		BucketName: bucketName,
-		RequestID:  "minio",
+		Key:        objectName,
 	}
 }
 
+// GetObjectACLPolicy - Get the full access control policy of an object,
+// with the owner and all the explicit grants. Missing objects are
+// reported as ErrorResponse with Code 'NoSuchKey'.
+func (c Client) GetObjectACLPolicy(bucketName, objectName string) (AccessControlPolicy, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return AccessControlPolicy{}, err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return AccessControlPolicy{}, err
+	}
+	return c.getAccessControlPolicy(bucketName, objectName)
+}
+
+// getAccessControlPolicy - get the access control policy of a bucket,
+// or of an object if objectName is set.
+func (c Client) getAccessControlPolicy(bucketName, objectName string) (AccessControlPolicy, error) {
+	// Set acl query.
+	urlValues := make(url.Values)
+	urlValues.Set("acl", "")
+
+	// Execute GET acl on bucketName or objectName.
+	resp, err := c.executeMethod("GET", requestMetadata{
+		bucketName:  bucketName,
+		objectName:  objectName,
+		queryValues: urlValues,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return AccessControlPolicy{}, err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return AccessControlPolicy{}, httpRespToErrorResponse(resp, bucketName, objectName)
+		}
+	}
+
+	// Decode access control policy.
+	policy := AccessControlPolicy{}
+	if err = xmlDecoder(resp.Body, &policy); err != nil {
+		return AccessControlPolicy{}, err
+	}
+	return policy, nil
+}
+
 // GetObject - returns an seekable, readable object.
 func (c Client) GetObject(bucketName, objectName string) (*Object, error) {
+	return c.GetObjectVersion(bucketName, objectName, "")
+}
+
+// GetObjectVersion - returns an seekable, readable object for a
+// specific version of an object in a versioned bucket. An empty
+// versionID reads the latest version.
+func (c Client) GetObjectVersion(bucketName, objectName, versionID string) (*Object, error) {
 	// Input validation.
 	if err := isValidBucketName(bucketName); err != nil {
 		return nil, err
@@ -142,7 +229,14 @@ func (c Client) GetObject(bucketName, objectName string) (*Object, error) {
 	}
 
 	// Start the request as soon Get is initiated.
-	httpReader, objectInfo, err := c.getObject(bucketName, objectName, 0, 0)
+	var httpReader io.ReadCloser
+	var objectInfo ObjectInfo
+	var err error
+	if c.readAheadSize > 0 {
+		httpReader, objectInfo, err = c.getObjectReadAhead(bucketName, objectName, versionID, 0, "")
+	} else {
+		httpReader, objectInfo, err = c.getObject(bucketName, objectName, versionID, 0, 0)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -164,13 +258,24 @@ func (c Client) GetObject(bucketName, objectName string) (*Object, error) {
 			select {
 			// When the done channel is closed exit our routine.
 			case <-doneCh:
+				if httpReader != nil {
+					closeStream(httpReader)
+				}
 				return
 			// Request message.
 			case req := <-reqCh:
 				// Offset changes fetch the new object at an Offset.
 				if req.DidOffsetChange {
+					// Release the connection of the previous stream.
+					if httpReader != nil {
+						closeStream(httpReader)
+					}
 					// Read from offset.
-					httpReader, _, err = c.getObject(bucketName, objectName, req.Offset, 0)
+					if c.readAheadSize > 0 {
+						httpReader, _, err = c.getObjectReadAhead(bucketName, objectName, versionID, req.Offset, objectInfo.ETag)
+					} else {
+						httpReader, _, err = c.getObject(bucketName, objectName, versionID, req.Offset, 0)
+					}
 					if err != nil {
 						resCh <- readResponse{
 							Error: err,
@@ -195,8 +300,191 @@ func (c Client) GetObject(bucketName, objectName string) (*Object, error) {
 			}
 		}
 	}()
+	// Concurrent ReadAt calls fetch their range with independent
+	// requests. Ranges must be of the same object, the whole object
+	// sent by servers ignoring the range only serves offset '0'.
+	etag := objectInfo.ETag
+	getRange := func(offset, length int64) (io.ReadCloser, error) {
+		rangeReader, rangeStat, isPartial, rerr := c.getObjectPartial(bucketName, objectName, versionID, offset, length, etag)
+		if rerr != nil {
+			return nil, rerr
+		}
+		if rangeStat.ETag != etag {
+			rangeReader.Close()
+			return nil, ErrObjectChanged(etag, rangeStat.ETag, bucketName, objectName)
+		}
+		if !isPartial && offset != 0 {
+			rangeReader.Close()
+			return nil, ErrAPINotSupported("Range requests are not supported consistently by the server.")
+		}
+		return rangeReader, nil
+	}
+	// Size of objects sent without Content-Length is looked up with
+	// HEAD once needed.
+	statObject := func() (ObjectInfo, error) {
+		objInfo, _, serr := c.statObject(bucketName, objectName, versionID)
+		return objInfo, serr
+	}
+
 	// Return the readerAt backed by routine.
-	return newObject(reqCh, resCh, doneCh, objectInfo), nil
+	return newObject(bucketName, reqCh, resCh, doneCh, objectInfo, getRange, statObject), nil
+}
+
+// GetObjectRange - returns the inclusive byte range start to end of an
+// object with a single ranged GET request.
+//
+// Returned reader yields end-start+1 bytes, or less if the object ends
+// before end. Size of the returned ObjectInfo is the total size of the
+// object. A range starting beyond the end of the object fails with an
+// ErrorResponse with Code 'InvalidRange'.
+func (c Client) GetObjectRange(bucketName, objectName string, start, end int64) (io.ReadCloser, ObjectInfo, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	if start < 0 || end < start {
+		return nil, ObjectInfo{}, ErrInvalidArgument(fmt.Sprintf("Invalid range start %d, end %d.", start, end))
+	}
+	return c.getObjectRange(bucketName, objectName, start, end, "")
+}
+
+// GetObjectRangeIfUnchanged - returns the inclusive byte range start to
+// end of an object, only if the object still has the given ETag.
+//
+// The range is requested with 'If-Range', a range of an object
+// overwritten meanwhile is never mixed with data read before. Such
+// requests fail with an ErrorResponse with Code 'PreconditionFailed',
+// restart reading the object from the beginning.
+func (c Client) GetObjectRangeIfUnchanged(bucketName, objectName string, start, end int64, etag string) (io.ReadCloser, ObjectInfo, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	if start < 0 || end < start {
+		return nil, ObjectInfo{}, ErrInvalidArgument(fmt.Sprintf("Invalid range start %d, end %d.", start, end))
+	}
+	if etag == "" {
+		return nil, ObjectInfo{}, ErrInvalidArgument("ETag cannot be empty.")
+	}
+	return c.getObjectRange(bucketName, objectName, start, end, etag)
+}
+
+// getObjectRange - returns the inclusive byte range start to end of an
+// object, also of servers ignoring the range. A non empty etag fails
+// with ErrObjectChanged if the object changed.
+func (c Client) getObjectRange(bucketName, objectName string, start, end int64, etag string) (io.ReadCloser, ObjectInfo, error) {
+	reader, objectStat, isPartial, err := c.getObjectPartial(bucketName, objectName, "", start, end-start+1, etag)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	if etag != "" && objectStat.ETag != etag {
+		reader.Close()
+		return nil, ObjectInfo{}, ErrObjectChanged(etag, objectStat.ETag, bucketName, objectName)
+	}
+	if !isPartial {
+		// Server does not support ranges, skip to the range of the
+		// whole object.
+		if _, err = io.CopyN(ioutil.Discard, reader, start); err != nil {
+			reader.Close()
+			if err == io.EOF {
+				return nil, ObjectInfo{}, ErrorResponse{
+					Code:       "InvalidRange",
+					Message:    fmt.Sprintf("Range start %d is beyond the object size %d.", start, objectStat.Size),
+					BucketName: bucketName,
+					Key:        objectName,
+				}
+			}
+			return nil, ObjectInfo{}, err
+		}
+		reader = limitedReadCloser{
+			Reader: io.LimitReader(reader, end-start+1),
+			Closer: reader,
+		}
+	}
+	return reader, objectStat, nil
+}
+
+// GetObjectDecompressed - returns a readable object which is
+// transparently decompressed if the object is stored with
+// 'Content-Encoding: gzip', other objects are returned as is.
+//
+// The size of decompressed objects is not known in advance, Stat()
+// reports their size as -1. Use GetObject for raw access to the
+// compressed data.
+func (c Client) GetObjectDecompressed(bucketName, objectName string) (*DecompressedObject, error) {
+	object, err := c.GetObject(bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+	objectInfo, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, err
+	}
+	if !isGzipEncoding(objectInfo.ContentEncoding) {
+		return &DecompressedObject{
+			object:     object,
+			reader:     object,
+			objectInfo: objectInfo,
+		}, nil
+	}
+	gzipReader, err := gzip.NewReader(object)
+	if err != nil {
+		object.Close()
+		return nil, err
+	}
+	// Decompressed size is unknown.
+	objectInfo.Size = -1
+	return &DecompressedObject{
+		object:     object,
+		reader:     gzipReader,
+		objectInfo: objectInfo,
+	}, nil
+}
+
+// isGzipEncoding - verify if content encoding is gzip.
+func isGzipEncoding(contentEncoding string) bool {
+	contentEncoding = strings.ToLower(strings.TrimSpace(contentEncoding))
+	return contentEncoding == "gzip" || contentEncoding == "x-gzip"
+}
+
+// DecompressedObject represents an open object which is decompressed
+// while reading, returned by GetObjectDecompressed.
+type DecompressedObject struct {
+	object     *Object
+	reader     io.Reader
+	objectInfo ObjectInfo
+}
+
+// Read reads up to len(b) bytes of decompressed data into b.
+func (d *DecompressedObject) Read(b []byte) (n int, err error) {
+	if d == nil {
+		return 0, ErrInvalidArgument("Object is nil")
+	}
+	return d.reader.Read(b)
+}
+
+// Stat returns the ObjectInfo structure describing the object, Size is
+// -1 for decompressed objects.
+func (d *DecompressedObject) Stat() (ObjectInfo, error) {
+	if d == nil {
+		return ObjectInfo{}, ErrInvalidArgument("Object is nil")
+	}
+	return d.objectInfo, nil
+}
+
+// Close - closes the underlying object.
+func (d *DecompressedObject) Close() (err error) {
+	if d == nil {
+		return ErrInvalidArgument("Object is nil")
+	}
+	return d.object.Close()
 }
 
 // Read response message container to reply back for the request.
@@ -213,17 +501,37 @@ type readRequest struct {
 	DidOffsetChange bool
 }
 
+// ErrAlreadyClosed is returned by all the operations of an Object once
+// it is closed, including further calls to Close. Callers closing an
+// object more than once, e.g. with defer, can ignore it.
+var ErrAlreadyClosed = errors.New("Object is already closed. Bad file descriptor.")
+
 // Object represents an open object. It implements Read, ReadAt,
 // Seeker, Close for a HTTP stream.
 type Object struct {
 	// Mutex.
 	mutex *sync.Mutex
 
+	// Held while the underlying stream is in use, ReadAt calls which
+	// find it busy fetch their range with an independent request.
+	streamToken chan struct{}
+	getRange    func(offset, length int64) (io.ReadCloser, error)
+
+	// Looks up the size of objects sent without Content-Length, their
+	// size is -1 until then.
+	statObject func() (ObjectInfo, error)
+
+	// Bucket of the object, to report errors.
+	bucketName string
+
 	// User allocated and defined.
-	reqCh      chan<- readRequest
-	resCh      <-chan readResponse
-	doneCh     chan<- struct{}
+	reqCh  chan<- readRequest
+	resCh  <-chan readResponse
+	doneCh chan<- struct{}
+	// Offset of the underlying stream, reads at any other offset
+	// fetch the object again from that offset.
 	prevOffset int64
+	// Offset of the next Read, not affected by ReadAt.
 	currOffset int64
 	objectInfo ObjectInfo
 
@@ -242,7 +550,9 @@ func (o *Object) Read(b []byte) (n int, err error) {
 		return 0, ErrInvalidArgument("Object is nil")
 	}
 
-	// Locking.
+	// Wait for the stream, then lock.
+	o.streamToken <- struct{}{}
+	defer func() { <-o.streamToken }()
 	o.mutex.Lock()
 	defer o.mutex.Unlock()
 
@@ -251,8 +561,9 @@ func (o *Object) Read(b []byte) (n int, err error) {
 		return 0, o.prevErr
 	}
 
-	// If current offset has reached Size limit, return EOF.
-	if o.currOffset >= o.objectInfo.Size {
+	// If current offset has reached Size limit, return EOF. Objects
+	// of unknown size are read until the stream ends.
+	if o.objectInfo.Size >= 0 && o.currOffset >= o.objectInfo.Size {
 		return 0, io.EOF
 	}
 
@@ -296,18 +607,35 @@ func (o *Object) Read(b []byte) (n int, err error) {
 	if dataMsg.Error == nil {
 		// If currOffset read is equal to objectSize
 		// We have reached end of file, we return io.EOF.
-		if o.currOffset >= o.objectInfo.Size {
+		if o.objectInfo.Size >= 0 && o.currOffset >= o.objectInfo.Size {
 			return dataMsg.Size, io.EOF
 		}
 		return dataMsg.Size, nil
 	}
 
+	// End of the stream of an object of unknown size tells its size,
+	// the object may still be read again after seeking.
+	if dataMsg.Error == io.EOF && o.objectInfo.Size < 0 {
+		o.objectInfo.Size = o.currOffset
+		return dataMsg.Size, io.EOF
+	}
+
+	// Stream ended before the size of the object, the server closed
+	// the connection early.
+	if dataMsg.Error == io.EOF && o.currOffset < o.objectInfo.Size {
+		o.prevErr = ErrUnexpectedEOF(o.currOffset, o.objectInfo.Size, o.bucketName, o.objectInfo.Key)
+		return dataMsg.Size, o.prevErr
+	}
+
 	// Save any error.
 	o.prevErr = dataMsg.Error
 	return dataMsg.Size, dataMsg.Error
 }
 
-// Stat returns the ObjectInfo structure describing object.
+// Stat returns the ObjectInfo structure describing object. Size is -1
+// for objects sent without Content-Length, e.g. with chunked transfer
+// encoding, until it is found by Seek, ReadAt or reading the object
+// to the end.
 func (o *Object) Stat() (ObjectInfo, error) {
 	if o == nil {
 		return ObjectInfo{}, ErrInvalidArgument("Object is nil")
@@ -327,72 +655,172 @@ func (o *Object) Stat() (ObjectInfo, error) {
 // off. It returns the number of bytes read and the error, if any.
 // ReadAt always returns a non-nil error when n < len(b). At end of
 // file, that error is io.EOF.
+//
+// ReadAt is safe for concurrent use and neither affects nor is
+// affected by the offset of Read and Seek. Data is read straight into
+// b with at most one request. A call at the offset of the object
+// stream continues the stream, any other call fetches exactly its
+// range with an independent ranged GET, so concurrent calls run in
+// parallel. Over HTTPS the default transport negotiates HTTP/2 and
+// multiplexes these requests on a single connection, which suits
+// serving many concurrent ranges of the same object, e.g. video.
 func (o *Object) ReadAt(b []byte, offset int64) (n int, err error) {
 	if o == nil {
 		return 0, ErrInvalidArgument("Object is nil")
 	}
 
-	// Locking.
+	// Ranges are bounded by the object size, look it up if unknown.
 	o.mutex.Lock()
-	defer o.mutex.Unlock()
-
-	// prevErr is error which was saved in previous operation.
-	if o.prevErr != nil || o.isClosed {
-		return 0, o.prevErr
+	err = o.resolveSize()
+	o.mutex.Unlock()
+	if err != nil {
+		return 0, err
 	}
 
-	// If offset is negative and offset is greater than or equal to
-	// object size we return EOF.
-	if offset < 0 || offset >= o.objectInfo.Size {
-		return 0, io.EOF
+	// Use the stream if it is free.
+	select {
+	case o.streamToken <- struct{}{}:
+	default:
+		return o.readAtRange(b, offset)
 	}
 
-	// Send current information over control channel to indicate we
-	// are ready.
-	reqMsg := readRequest{}
+	// Locking, released while waiting for data so that concurrent
+	// calls are not held up.
+	o.mutex.Lock()
 
-	// Send the offset and pointer to the buffer over the channel.
-	reqMsg.Buffer = b
+	// Reads at any other offset than the one of the stream, or after
+	// an error, are left to readAtRange.
+	if o.prevErr != nil || o.isClosed || offset != o.prevOffset {
+		o.mutex.Unlock()
+		<-o.streamToken
+		return o.readAtRange(b, offset)
+	}
+	defer func() { <-o.streamToken }()
 
-	// For ReadAt offset always changes, minor optimization where
-	// offset same as currOffset we don't change the offset.
-	reqMsg.DidOffsetChange = offset != o.currOffset
-	if reqMsg.DidOffsetChange {
-		// Set new offset.
-		reqMsg.Offset = offset
-		// Save new offset as current offset.
-		o.currOffset = offset
+	// If offset is greater than or equal to object size we return
+	// EOF.
+	if offset >= o.objectInfo.Size {
+		o.mutex.Unlock()
+		return 0, io.EOF
 	}
 
+	// Send the pointer to the buffer over the channel, the stream
+	// is read at its current offset.
+	reqMsg := readRequest{
+		Buffer: b,
+	}
+	o.mutex.Unlock()
+
 	// Send read request over the control channel.
 	o.reqCh <- reqMsg
 
 	// Get data over the response channel.
 	dataMsg := <-o.resCh
 
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
 	// Bytes read.
 	bytesRead := int64(dataMsg.Size)
 
-	// Update current offset.
-	o.currOffset += bytesRead
-
-	// Save current offset as previous offset before returning.
-	o.prevOffset = o.currOffset
+	// Save the stream offset, current offset of Read is left as is.
+	o.prevOffset = offset + bytesRead
 
 	if dataMsg.Error == nil {
-		// If currentOffset is equal to objectSize
+		// If offset read is equal to objectSize
 		// we have reached end of file, we return io.EOF.
-		if o.currOffset >= o.objectInfo.Size {
+		if o.prevOffset >= o.objectInfo.Size {
 			return dataMsg.Size, io.EOF
 		}
 		return dataMsg.Size, nil
 	}
 
+	// End of the stream is not saved, later reads fetch the object
+	// again. Streams ended before the size of the object were cut
+	// short by the server.
+	if dataMsg.Error == io.EOF {
+		if o.prevOffset < o.objectInfo.Size {
+			o.prevErr = ErrUnexpectedEOF(o.prevOffset, o.objectInfo.Size, o.bucketName, o.objectInfo.Key)
+			return dataMsg.Size, o.prevErr
+		}
+		return dataMsg.Size, io.EOF
+	}
+
 	// Save any error.
 	o.prevErr = dataMsg.Error
 	return dataMsg.Size, dataMsg.Error
 }
 
+// readAtRange - reads len(b) bytes at offset with an independent
+// ranged request, used by ReadAt while the object stream is busy.
+func (o *Object) readAtRange(b []byte, offset int64) (n int, err error) {
+	o.mutex.Lock()
+	prevErr, isClosed, size := o.prevErr, o.isClosed, o.objectInfo.Size
+	o.mutex.Unlock()
+	// Errors of the stream, including its end, do not affect
+	// independent ranges. Only closed or changed objects fail them.
+	if isClosed || ToErrorResponse(prevErr).Code == "PreconditionFailed" {
+		return 0, prevErr
+	}
+
+	if offset < 0 || offset >= size {
+		return 0, io.EOF
+	}
+	length := int64(len(b))
+	if offset+length > size {
+		length = size - offset
+	}
+	if length == 0 {
+		return 0, nil
+	}
+
+	rangeReader, err := o.getRange(offset, length)
+	if err != nil {
+		return 0, err
+	}
+	defer drainAndClose(rangeReader)
+
+	n, err = io.ReadFull(rangeReader, b[:length])
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// Range ended before its length, the server closed the
+		// connection early.
+		return n, ErrUnexpectedEOF(offset+int64(n), size, o.bucketName, o.objectInfo.Key)
+	}
+	if err == nil && offset+int64(n) >= size {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// resolveSize - looks up the size of an object sent without
+// Content-Length with HEAD, fails if the server does not report it
+// either. Must be called with the mutex held.
+func (o *Object) resolveSize() error {
+	if o.objectInfo.Size >= 0 {
+		return nil
+	}
+	if o.isClosed || ToErrorResponse(o.prevErr).Code == "PreconditionFailed" {
+		return o.prevErr
+	}
+	if o.statObject == nil {
+		return ErrAPINotSupported("Object size is unknown, the server did not send Content-Length.")
+	}
+	objInfo, err := o.statObject()
+	if err != nil {
+		return err
+	}
+	// The object changed since it was opened, its size does not
+	// apply to the stream.
+	if objInfo.ETag != o.objectInfo.ETag {
+		return ErrObjectChanged(o.objectInfo.ETag, objInfo.ETag, o.objectInfo.Key, o.objectInfo.Key)
+	}
+	if objInfo.Size < 0 {
+		return ErrAPINotSupported("Object size is unknown, the server did not send Content-Length.")
+	}
+	o.objectInfo.Size = objInfo.Size
+	return nil
+}
+
 // Seek sets the offset for the next Read or Write to offset,
 // interpreted according to whence: 0 means relative to the
 // origin of the file, 1 means relative to the current offset,
@@ -423,20 +851,17 @@ func (o *Object) Seek(offset int64, whence int) (n int64, err error) {
 		return 0, ErrInvalidArgument(fmt.Sprintf("Negative position not allowed for %d.", whence))
 	}
 
-	// Save current offset as previous offset.
-	o.prevOffset = o.currOffset
-
 	// Switch through whence.
 	switch whence {
 	default:
 		return 0, ErrInvalidArgument(fmt.Sprintf("Invalid whence %d", whence))
 	case 0:
-		if offset > o.objectInfo.Size {
+		if o.objectInfo.Size >= 0 && offset > o.objectInfo.Size {
 			return 0, io.EOF
 		}
 		o.currOffset = offset
 	case 1:
-		if o.currOffset+offset > o.objectInfo.Size {
+		if o.objectInfo.Size >= 0 && o.currOffset+offset > o.objectInfo.Size {
 			return 0, io.EOF
 		}
 		o.currOffset += offset
@@ -447,11 +872,15 @@ func (o *Object) Seek(offset int64, whence int) (n int64, err error) {
 		if offset > 0 {
 			return 0, io.EOF
 		}
+		// Seeking relative to the end needs the object size.
+		if err = o.resolveSize(); err != nil {
+			return 0, err
+		}
 		// Seeking to negative position not allowed for whence.
 		if o.objectInfo.Size+offset < 0 {
 			return 0, ErrInvalidArgument(fmt.Sprintf("Seeking at negative offset not allowed for %d", whence))
 		}
-		o.currOffset += offset
+		o.currOffset = o.objectInfo.Size + offset
 	}
 	// Return the effective offset.
 	return o.currOffset, nil
@@ -463,7 +892,9 @@ func (o *Object) Close() (err error) {
 	if o == nil {
 		return ErrInvalidArgument("Object is nil")
 	}
-	// Locking.
+	// Wait for pending reads on the stream, then lock.
+	o.streamToken <- struct{}{}
+	defer func() { <-o.streamToken }()
 	o.mutex.Lock()
 	defer o.mutex.Unlock()
 
@@ -476,21 +907,24 @@ func (o *Object) Close() (err error) {
 	close(o.doneCh)
 
 	// Save for future operations.
-	errMsg := "Object is already closed. Bad file descriptor."
-	o.prevErr = errors.New(errMsg)
+	o.prevErr = ErrAlreadyClosed
 	// Save here that we closed done channel successfully.
 	o.isClosed = true
 	return nil
 }
 
 // newObject instantiates a new *minio.Object*
-func newObject(reqCh chan<- readRequest, resCh <-chan readResponse, doneCh chan<- struct{}, objectInfo ObjectInfo) *Object {
+func newObject(bucketName string, reqCh chan<- readRequest, resCh <-chan readResponse, doneCh chan<- struct{}, objectInfo ObjectInfo, getRange func(offset, length int64) (io.ReadCloser, error), statObject func() (ObjectInfo, error)) *Object {
 	return &Object{
-		mutex:      &sync.Mutex{},
-		reqCh:      reqCh,
-		resCh:      resCh,
-		doneCh:     doneCh,
-		objectInfo: objectInfo,
+		mutex:       &sync.Mutex{},
+		streamToken: make(chan struct{}, 1),
+		getRange:    getRange,
+		statObject:  statObject,
+		bucketName:  bucketName,
+		reqCh:       reqCh,
+		resCh:       resCh,
+		doneCh:      doneCh,
+		objectInfo:  objectInfo,
 	}
 }
 
@@ -498,16 +932,81 @@ func newObject(reqCh chan<- readRequest, resCh <-chan readResponse, doneCh chan<
 //
 // Additionally this function also takes range arguments to download the specified
 // range bytes of an object. Setting offset and length = 0 will download the full object.
+// An empty versionID downloads the latest version of the object.
 //
 // For more information about the HTTP Range header.
 // go to http://www.w3.org/Protocols/rfc2616/rfc2616-sec14.html#sec14.35.
-func (c Client) getObject(bucketName, objectName string, offset, length int64) (io.ReadCloser, ObjectInfo, error) {
+func (c Client) getObject(bucketName, objectName, versionID string, offset, length int64) (io.ReadCloser, ObjectInfo, error) {
+	reader, objectStat, _, err := c.getObjectPartial(bucketName, objectName, versionID, offset, length, "")
+	return reader, objectStat, err
+}
+
+// getObjectReadAhead - same as getObject from offset to the end of the
+// object, but the object is read in ranges of the read-ahead size of
+// the client. The next range is fetched while the current one is read.
+// A non empty etag fails with ErrObjectChanged if the object changed.
+func (c Client) getObjectReadAhead(bucketName, objectName, versionID string, offset int64, etag string) (io.ReadCloser, ObjectInfo, error) {
+	reader, objectStat, isPartial, err := c.getObjectPartial(bucketName, objectName, versionID, offset, c.readAheadSize, etag)
+	if err != nil {
+		// Empty objects have no range to read.
+		if offset == 0 && ToErrorResponse(err).Code == "InvalidRange" {
+			return c.getObject(bucketName, objectName, versionID, 0, 0)
+		}
+		return nil, ObjectInfo{}, err
+	}
+	if etag != "" && objectStat.ETag != etag {
+		reader.Close()
+		return nil, ObjectInfo{}, ErrObjectChanged(etag, objectStat.ETag, bucketName, objectName)
+	}
+	if !isPartial {
+		// Server does not support ranges, skip to offset of the whole
+		// object.
+		if _, err = io.CopyN(ioutil.Discard, reader, offset); err != nil {
+			reader.Close()
+			return nil, ObjectInfo{}, err
+		}
+		return reader, objectStat, nil
+	}
+	// Nothing to read ahead after the last range.
+	if offset+c.readAheadSize >= objectStat.Size {
+		return reader, objectStat, nil
+	}
+
+	// Ranges read ahead must be of the same object.
+	etag = objectStat.ETag
+	getRange := func(offset, length int64) (io.ReadCloser, error) {
+		rangeReader, rangeStat, isPartial, err := c.getObjectPartial(bucketName, objectName, versionID, offset, length, etag)
+		if err != nil {
+			return nil, err
+		}
+		if rangeStat.ETag != etag {
+			rangeReader.Close()
+			return nil, ErrObjectChanged(etag, rangeStat.ETag, bucketName, objectName)
+		}
+		if !isPartial {
+			rangeReader.Close()
+			return nil, ErrAPINotSupported("Range requests are not supported consistently by the server.")
+		}
+		return rangeReader, nil
+	}
+	return newReadAheadReader(bucketName, objectName, reader, c.readAheadSize, offset+c.readAheadSize,
+		objectStat.Size, c.readAheadSize, getRange), objectStat, nil
+}
+
+// getObjectPartial is identical to getObject, additionally reports if
+// the server honored the requested range. Servers not supporting ranges
+// return the whole object.
+//
+// A non empty ifRange ETag is sent as 'If-Range', the range is only
+// honored while the object has this ETag. Otherwise the server returns
+// the whole current object.
+func (c Client) getObjectPartial(bucketName, objectName, versionID string, offset, length int64, ifRange string) (io.ReadCloser, ObjectInfo, bool, error) {
 	// Validate input arguments.
 	if err := isValidBucketName(bucketName); err != nil {
-		return nil, ObjectInfo{}, err
+		return nil, ObjectInfo{}, false, err
 	}
 	if err := isValidObjectName(objectName); err != nil {
-		return nil, ObjectInfo{}, err
+		return nil, ObjectInfo{}, false, err
 	}
 
 	customHeader := make(http.Header)
@@ -519,19 +1018,33 @@ func (c Client) getObject(bucketName, objectName string, offset, length int64) (
 	} else if length < 0 && offset == 0 {
 		customHeader.Set("Range", fmt.Sprintf("bytes=%d", length))
 	}
+	if ifRange != "" && customHeader.Get("Range") != "" {
+		customHeader.Set("If-Range", "\""+ifRange+"\"")
+	}
+
+	// Always fetch the object data as stored, do not let the transport
+	// transparently decompress objects with 'Content-Encoding: gzip'.
+	customHeader.Set("Accept-Encoding", "identity")
+
+	// Set version id if requested.
+	urlValues := make(url.Values)
+	if versionID != "" {
+		urlValues.Set("versionId", versionID)
+	}
 
 	// Execute GET on objectName.
 	resp, err := c.executeMethod("GET", requestMetadata{
 		bucketName:   bucketName,
 		objectName:   objectName,
+		queryValues:  urlValues,
 		customHeader: customHeader,
 	})
 	if err != nil {
-		return nil, ObjectInfo{}, err
+		return nil, ObjectInfo{}, false, err
 	}
 	if resp != nil {
 		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
-			return nil, ObjectInfo{}, httpRespToErrorResponse(resp, bucketName, objectName)
+			return nil, ObjectInfo{}, false, httpRespToErrorResponse(resp, bucketName, objectName)
 		}
 	}
 
@@ -543,7 +1056,7 @@ func (c Client) getObject(bucketName, objectName string, offset, length int64) (
 	date, err := time.Parse(http.TimeFormat, resp.Header.Get("Last-Modified"))
 	if err != nil {
 		msg := "Last-Modified time format not recognized. " + reportIssue
-		return nil, ObjectInfo{}, ErrorResponse{
+		return nil, ObjectInfo{}, false, ErrorResponse{
 			Code:      "InternalError",
 			Message:   msg,
 			RequestID: resp.Header.Get("x-amz-request-id"),
@@ -560,9 +1073,469 @@ func (c Client) getObject(bucketName, objectName string, offset, length int64) (
 	objectStat.ETag = md5sum
 	objectStat.Key = objectName
 	objectStat.Size = resp.ContentLength
+	// Ranged reads report the total object size in Content-Range.
+	if resp.StatusCode == http.StatusPartialContent {
+		if size, err := parseContentRangeSize(resp.Header.Get("Content-Range")); err == nil {
+			objectStat.Size = size
+		}
+	}
 	objectStat.LastModified = date
 	objectStat.ContentType = contentType
+	objectStat.ContentEncoding = resp.Header.Get("Content-Encoding")
+	objectStat.VersionID = resp.Header.Get("x-amz-version-id")
 
 	// do not close body here, caller will close
-	return resp.Body, objectStat, nil
+	return resp.Body, objectStat, resp.StatusCode == http.StatusPartialContent, nil
+}
+
+// GetObjectTagging - Get the tag set of an existing object.
+//
+// Objects without any tags return an empty map.
+func (c Client) GetObjectTagging(bucketName, objectName string) (map[string]string, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return nil, err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return nil, err
+	}
+
+	// Set tagging query.
+	urlValues := make(url.Values)
+	urlValues.Set("tagging", "")
+
+	// Execute GET tagging on objectName.
+	resp, err := c.executeMethod("GET", requestMetadata{
+		bucketName:  bucketName,
+		objectName:  objectName,
+		queryValues: urlValues,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, httpRespToErrorResponse(resp, bucketName, objectName)
+		}
+	}
+
+	// Decode tagging response.
+	objectTagging := tagging{}
+	err = xmlDecoder(resp.Body, &objectTagging)
+	if err != nil {
+		return nil, err
+	}
+	return objectTagging.toMap(), nil
+}
+
+// GetObjectAttributes - Get the attributes of an object listed in
+// attrs, one or more of ObjectAttributeETag, ObjectAttributeChecksum,
+// ObjectAttributeObjectParts, ObjectAttributeStorageClass and
+// ObjectAttributeObjectSize, with a single request instead of
+// StatObject and ListObjectParts. Parts of objects with more than 1000
+// parts are listed with further requests.
+//
+// Servers which do not implement the API return ErrAPINotSupported.
+//
+//   attrs, err := api.GetObjectAttributes("mybucket", "myobject", []string{minio.ObjectAttributeObjectParts})
+//   if err != nil {
+//       return err
+//   }
+//   fmt.Println(attrs.PartsCount)
+//
+func (c Client) GetObjectAttributes(bucketName, objectName string, attrs []string) (ObjectAttributes, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return ObjectAttributes{}, err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return ObjectAttributes{}, err
+	}
+	if len(attrs) == 0 {
+		return ObjectAttributes{}, ErrInvalidArgument("At least one object attribute must be requested.")
+	}
+	for _, attr := range attrs {
+		if !isValidObjectAttribute(attr) {
+			return ObjectAttributes{}, ErrInvalidArgument("Unrecognized object attribute " + attr)
+		}
+	}
+
+	// Set attributes query.
+	urlValues := make(url.Values)
+	urlValues.Set("attributes", "")
+
+	var attributes ObjectAttributes
+	var partNumberMarker int
+	for {
+		// Set requested attributes and the parts to continue after.
+		customHeader := make(http.Header)
+		customHeader.Set("x-amz-object-attributes", strings.Join(attrs, ","))
+		if partNumberMarker > 0 {
+			customHeader.Set("x-amz-part-number-marker", strconv.Itoa(partNumberMarker))
+		}
+
+		// Execute GET attributes on objectName.
+		resp, err := c.executeMethod("GET", requestMetadata{
+			bucketName:   bucketName,
+			objectName:   objectName,
+			queryValues:  urlValues,
+			customHeader: customHeader,
+		})
+		if err != nil {
+			closeResponse(resp)
+			return ObjectAttributes{}, err
+		}
+		if resp != nil {
+			if resp.StatusCode != http.StatusOK {
+				err = httpRespToAPIErrorResponse(resp, bucketName, objectName, "GetObjectAttributes")
+				closeResponse(resp)
+				return ObjectAttributes{}, err
+			}
+		}
+
+		// Decode attributes response.
+		result := getObjectAttributesResponse{}
+		err = xmlDecoder(resp.Body, &result)
+		closeResponse(resp)
+		if err != nil {
+			return ObjectAttributes{}, err
+		}
+		if partNumberMarker == 0 {
+			attributes.ETag = strings.Trim(result.ETag, "\"")
+			attributes.Checksum = result.Checksum
+			attributes.PartsCount = result.ObjectParts.PartsCount
+			attributes.StorageClass = result.StorageClass
+			attributes.ObjectSize = result.ObjectSize
+			attributes.VersionID = resp.Header.Get("x-amz-version-id")
+			if date, err := time.Parse(http.TimeFormat, resp.Header.Get("Last-Modified")); err == nil {
+				attributes.LastModified = date
+			}
+		}
+		for _, part := range result.ObjectParts.Parts {
+			attributes.Parts = append(attributes.Parts, ObjectAttributePart{
+				PartNumber: part.PartNumber,
+				Size:       part.Size,
+				Checksum:   part.ObjectChecksum,
+			})
+		}
+
+		// Listing of parts ends when result is not truncated.
+		if !result.ObjectParts.IsTruncated {
+			return attributes, nil
+		}
+		if result.ObjectParts.NextPartNumberMarker <= partNumberMarker {
+			return ObjectAttributes{}, ErrInvalidArgument("Object parts truncated without a next part number marker. " + reportIssue)
+		}
+		partNumberMarker = result.ObjectParts.NextPartNumberMarker
+	}
+}
+
+// GetBucketLifecycle - Get the lifecycle configuration of an existing
+// bucket.
+//
+// Returns ErrAPINotSupported if the server does not support lifecycle
+// configuration.
+func (c Client) GetBucketLifecycle(bucketName string) (BucketLifecycle, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return BucketLifecycle{}, err
+	}
+
+	// Set lifecycle query.
+	urlValues := make(url.Values)
+	urlValues.Set("lifecycle", "")
+
+	// Execute GET lifecycle on bucketName.
+	resp, err := c.executeMethod("GET", requestMetadata{
+		bucketName:  bucketName,
+		queryValues: urlValues,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return BucketLifecycle{}, err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return BucketLifecycle{}, httpRespToAPIErrorResponse(resp, bucketName, "", "Bucket lifecycle")
+		}
+	}
+
+	// Decode lifecycle configuration.
+	lifecycle := BucketLifecycle{}
+	err = xmlDecoder(resp.Body, &lifecycle)
+	if err != nil {
+		return BucketLifecycle{}, err
+	}
+	return lifecycle, nil
+}
+
+// GetBucketCORS - Get the CORS configuration of an existing bucket.
+//
+// Buckets without CORS configuration fail with an ErrorResponse with
+// Code 'NoSuchCORSConfiguration'. Returns ErrAPINotSupported if the
+// server does not support CORS configuration.
+func (c Client) GetBucketCORS(bucketName string) (BucketCORS, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return BucketCORS{}, err
+	}
+
+	// Set cors query.
+	urlValues := make(url.Values)
+	urlValues.Set("cors", "")
+
+	// Execute GET cors on bucketName.
+	resp, err := c.executeMethod("GET", requestMetadata{
+		bucketName:  bucketName,
+		queryValues: urlValues,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return BucketCORS{}, err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return BucketCORS{}, httpRespToAPIErrorResponse(resp, bucketName, "", "Bucket CORS")
+		}
+	}
+
+	// Decode cors configuration.
+	cors := BucketCORS{}
+	err = xmlDecoder(resp.Body, &cors)
+	if err != nil {
+		return BucketCORS{}, err
+	}
+	return cors, nil
+}
+
+// GetObjectLockConfiguration - Get the object lock configuration of a
+// bucket, Rule is nil if the bucket has no default retention.
+//
+// Buckets created without object lock fail with an ErrorResponse with
+// Code 'ObjectLockConfigurationNotFoundError'.
+func (c Client) GetObjectLockConfiguration(bucketName string) (ObjectLockConfiguration, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return ObjectLockConfiguration{}, err
+	}
+
+	// Set object lock query.
+	urlValues := make(url.Values)
+	urlValues.Set("object-lock", "")
+
+	// Execute GET object lock on bucketName.
+	resp, err := c.executeMethod("GET", requestMetadata{
+		bucketName:  bucketName,
+		queryValues: urlValues,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return ObjectLockConfiguration{}, err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return ObjectLockConfiguration{}, httpRespToAPIErrorResponse(resp, bucketName, "", "Object lock")
+		}
+	}
+
+	// Decode object lock configuration.
+	config := ObjectLockConfiguration{}
+	err = xmlDecoder(resp.Body, &config)
+	if err != nil {
+		return ObjectLockConfiguration{}, err
+	}
+	return config, nil
+}
+
+// GetBucketVersioning - Get the versioning state of an existing bucket.
+//
+// Returned values are:
+//
+//  VersioningUnversioned - Bucket was never configured for versioning.
+//  VersioningEnabled - Bucket keeps all versions of its objects.
+//  VersioningSuspended - Bucket stopped creating new versions.
+func (c Client) GetBucketVersioning(bucketName string) (VersioningStatus, error) {
+	versioning, err := c.GetBucketVersioningConfiguration(bucketName)
+	if err != nil {
+		return VersioningUnversioned, err
+	}
+	return versioning.Status, nil
+}
+
+// GetBucketVersioningConfiguration - Get the versioning configuration
+// of an existing bucket, including the MFA delete state.
+func (c Client) GetBucketVersioningConfiguration(bucketName string) (BucketVersioningConfiguration, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return BucketVersioningConfiguration{}, err
+	}
+
+	// Set versioning query.
+	urlValues := make(url.Values)
+	urlValues.Set("versioning", "")
+
+	// Execute GET versioning on bucketName.
+	resp, err := c.executeMethod("GET", requestMetadata{
+		bucketName:  bucketName,
+		queryValues: urlValues,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return BucketVersioningConfiguration{}, err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return BucketVersioningConfiguration{}, httpRespToAPIErrorResponse(resp, bucketName, "", "Bucket versioning")
+		}
+	}
+
+	// Decode versioning configuration, buckets which were never
+	// configured respond with an empty configuration.
+	versioning := BucketVersioningConfiguration{}
+	err = xmlDecoder(resp.Body, &versioning)
+	if err != nil {
+		return BucketVersioningConfiguration{}, err
+	}
+	return versioning, nil
+}
+
+// GetBucketNotification - Get the notification configuration of an
+// existing bucket.
+func (c Client) GetBucketNotification(bucketName string) (BucketNotification, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return BucketNotification{}, err
+	}
+
+	// Set notification query.
+	urlValues := make(url.Values)
+	urlValues.Set("notification", "")
+
+	// Execute GET notification on bucketName.
+	resp, err := c.executeMethod("GET", requestMetadata{
+		bucketName:  bucketName,
+		queryValues: urlValues,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return BucketNotification{}, err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return BucketNotification{}, httpRespToAPIErrorResponse(resp, bucketName, "", "Bucket notification")
+		}
+	}
+
+	// Decode notification configuration.
+	notification := BucketNotification{}
+	err = xmlDecoder(resp.Body, &notification)
+	if err != nil {
+		return BucketNotification{}, err
+	}
+	return notification, nil
+}
+
+// GetObjectRetention - Get the object lock retention of an object, an
+// empty versionID gets the retention of the latest version.
+//
+// Objects without retention return an empty mode and a nil
+// retainUntilDate.
+func (c Client) GetObjectRetention(bucketName, objectName, versionID string) (mode RetentionMode, retainUntilDate *time.Time, err error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return "", nil, err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return "", nil, err
+	}
+
+	// Set retention query.
+	urlValues := make(url.Values)
+	urlValues.Set("retention", "")
+	if versionID != "" {
+		urlValues.Set("versionId", versionID)
+	}
+
+	// Execute GET on objectName to get retention.
+	resp, err := c.executeMethod("GET", requestMetadata{
+		bucketName:  bucketName,
+		objectName:  objectName,
+		queryValues: urlValues,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return "", nil, err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			err = httpRespToAPIErrorResponse(resp, bucketName, objectName, "Object lock")
+			// Objects without retention.
+			if ToErrorResponse(err).Code == "NoSuchObjectLockConfiguration" {
+				return "", nil, nil
+			}
+			return "", nil, c.objectLockError(bucketName, err)
+		}
+	}
+
+	// Decode retention.
+	retention := objectRetention{}
+	err = xmlDecoder(resp.Body, &retention)
+	if err != nil {
+		return "", nil, err
+	}
+	return retention.Mode, retention.RetainUntilDate, nil
+}
+
+// GetObjectLegalHold - Get the object lock legal hold status of an
+// object, an empty versionID gets the legal hold of the latest version.
+//
+// Objects which never had a legal hold return LegalHoldOff.
+func (c Client) GetObjectLegalHold(bucketName, objectName, versionID string) (LegalHoldStatus, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return "", err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return "", err
+	}
+
+	// Set legal hold query.
+	urlValues := make(url.Values)
+	urlValues.Set("legal-hold", "")
+	if versionID != "" {
+		urlValues.Set("versionId", versionID)
+	}
+
+	// Execute GET on objectName to get legal hold.
+	resp, err := c.executeMethod("GET", requestMetadata{
+		bucketName:  bucketName,
+		objectName:  objectName,
+		queryValues: urlValues,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return "", err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			err = httpRespToAPIErrorResponse(resp, bucketName, objectName, "Object lock")
+			// Objects which never had a legal hold.
+			if ToErrorResponse(err).Code == "NoSuchObjectLockConfiguration" {
+				return LegalHoldOff, nil
+			}
+			return "", c.objectLockError(bucketName, err)
+		}
+	}
+
+	// Decode legal hold.
+	legalHold := objectLegalHold{}
+	err = xmlDecoder(resp.Body, &legalHold)
+	if err != nil {
+		return "", err
+	}
+	if legalHold.Status == "" {
+		return LegalHoldOff, nil
+	}
+	return legalHold.Status, nil
 }
@@ -0,0 +1,117 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// HealState - state of a bucket or object after healing.
+type HealState string
+
+// Different heal states.
+const (
+	// Nothing needed healing.
+	HealNone = HealState("none")
+	// Healed only partially, not enough disks were online.
+	HealPartial = HealState("partial")
+	// Healed successfully.
+	HealOK = HealState("ok")
+)
+
+// HealResult - container for the result of healing a bucket or object.
+type HealResult struct {
+	// State after healing, for dry runs the state healing would
+	// result in.
+	State HealState `xml:"State"`
+}
+
+// HealBucket - heals a bucket on all the disks of a Minio server,
+// dryRun only reports what would be healed.
+//
+// NOTE: This API is only supported by Minio servers.
+func (c Client) HealBucket(bucketName string, dryRun bool) (HealResult, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return HealResult{}, err
+	}
+	return c.heal(bucketName, "", "bucket", dryRun)
+}
+
+// HealObject - heals an object on all the disks of a Minio server,
+// dryRun only reports what would be healed.
+//
+// NOTE: This API is only supported by Minio servers.
+func (c Client) HealObject(bucketName, objectName string, dryRun bool) (HealResult, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return HealResult{}, err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return HealResult{}, err
+	}
+	return c.heal(bucketName, objectName, "object", dryRun)
+}
+
+// heal - executes a heal operation on a bucket or object.
+func (c Client) heal(bucketName, objectName, operation string, dryRun bool) (HealResult, error) {
+	// Healing is specific to Minio servers.
+	if isAmazonEndpoint(c.endpointURL) || c.isGoogleCompatible() {
+		return HealResult{}, ErrAPINotSupported("Healing is specific only to Minio servers.")
+	}
+
+	// Set heal query.
+	urlValues := make(url.Values)
+	urlValues.Set("heal", "")
+
+	// Set heal operation.
+	customHeader := make(http.Header)
+	customHeader.Set("x-minio-operation", operation)
+	if dryRun {
+		customHeader.Set("x-minio-dry-run", "yes")
+	}
+
+	// Execute POST on bucket or object to heal.
+	resp, err := c.executeMethod("POST", requestMetadata{
+		bucketName:   bucketName,
+		objectName:   objectName,
+		queryValues:  urlValues,
+		customHeader: customHeader,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return HealResult{}, err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return HealResult{}, httpRespToAPIErrorResponse(resp, bucketName, objectName, "Healing")
+		}
+	}
+
+	// Decode heal result, an empty response means healed.
+	healResult := HealResult{}
+	err = xmlDecoder(resp.Body, &healResult)
+	if err == io.EOF {
+		return HealResult{State: HealOK}, nil
+	}
+	if err != nil {
+		return HealResult{}, err
+	}
+	return healResult, nil
+}
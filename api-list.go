@@ -21,8 +21,14 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 )
 
+// bucketLocationConcurrency - maximum number of concurrent bucket
+// location lookups of ListBucketsByRegion.
+const bucketLocationConcurrency = 16
+
 // ListBuckets list all buckets owned by this authenticated user.
 //
 // This call requires explicit authentication, no anonymous requests are
@@ -53,6 +59,58 @@ func (c Client) ListBuckets() ([]BucketInfo, error) {
 	return listAllMyBucketsResult.Buckets.Bucket, nil
 }
 
+// ListBucketsByRegion lists all buckets owned by this authenticated
+// user located in region, in the order of ListBuckets.
+//
+// Locations are resolved with up to 16 concurrent lookups and cached
+// by the client. Buckets whose location cannot be resolved are
+// returned in locationErrs and left out of buckets, err is only set if
+// listing the buckets fails.
+func (c Client) ListBucketsByRegion(region string) (buckets []BucketInfo, locationErrs []BucketLocationError, err error) {
+	if region == "" {
+		return nil, nil, ErrInvalidArgument("Region cannot be empty.")
+	}
+	allBuckets, err := c.ListBuckets()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Resolve all the locations, bucket indices are sent to a bounded
+	// number of workers.
+	locations := make([]string, len(allBuckets))
+	errs := make([]error, len(allBuckets))
+	indexCh := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < bucketLocationConcurrency && i < len(allBuckets); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexCh {
+				locations[index], errs[index] = c.getBucketLocation(allBuckets[index].Name)
+			}
+		}()
+	}
+	for index := range allBuckets {
+		indexCh <- index
+	}
+	close(indexCh)
+	wg.Wait()
+
+	for index, bucket := range allBuckets {
+		if errs[index] != nil {
+			locationErrs = append(locationErrs, BucketLocationError{
+				BucketName: bucket.Name,
+				Err:        errs[index],
+			})
+			continue
+		}
+		if locations[index] == region {
+			buckets = append(buckets, bucket)
+		}
+	}
+	return buckets, locationErrs, nil
+}
+
 // ListObjects - (List Objects) - List some objects or all recursively.
 //
 // ListObjects lists all objects matching the objectPrefix from
@@ -65,6 +123,10 @@ func (c Client) ListBuckets() ([]BucketInfo, error) {
 // return back all the objects in a given bucket name and object
 // prefix.
 //
+// Listing failures, including those after some pages were listed, are
+// sent as a last value with only Err set before the channel is closed.
+// A closed channel without such a value means the listing completed.
+//
 //   api := client.New(....)
 //   // Create a done channel.
 //   doneCh := make(chan struct{})
@@ -72,6 +134,9 @@ func (c Client) ListBuckets() ([]BucketInfo, error) {
 //   // Recurively list all objects in 'mytestbucket'
 //   recursive := true
 //   for message := range api.ListObjects("mytestbucket", "starthere", recursive, doneCh) {
+//       if message.Err != nil {
+//           return message.Err
+//       }
 //       fmt.Println(message)
 //   }
 //
@@ -108,13 +173,12 @@ func (c Client) ListObjects(bucketName, objectPrefix string, recursive bool, don
 		var marker string
 		for {
 			// Get list of objects a maximum of 1000 per request.
-			result, err := c.listObjectsQuery(bucketName, objectPrefix, marker, delimiter, 1000)
+			result, err := c.listObjectsQuery(bucketName, objectPrefix, marker, delimiter, c.listMaxKeys)
 			if err != nil {
-				objectStatCh <- ObjectInfo{
-					Err: err,
-				}
+				sendListError(objectStatCh, err, doneCh)
 				return
 			}
+			prevMarker := marker
 
 			// If contents are available loop through and send over channel.
 			for _, object := range result.Contents {
@@ -142,6 +206,11 @@ func (c Client) ListObjects(bucketName, objectPrefix string, recursive bool, don
 				case <-doneCh:
 					return
 				}
+				// Continue after the last prefix if it sorts after
+				// the last key.
+				if object.Key > marker {
+					marker = object.Key
+				}
 			}
 
 			// If next marker present, save it for next request.
@@ -153,11 +222,234 @@ func (c Client) ListObjects(bucketName, objectPrefix string, recursive bool, don
 			if !result.IsTruncated {
 				return
 			}
+			// Requesting the same page again would never end.
+			if marker == prevMarker {
+				sendListError(objectStatCh, ErrInvalidArgument("Listing truncated without a next marker. "+reportIssue), doneCh)
+				return
+			}
+		}
+	}(objectStatCh)
+	return objectStatCh
+}
+
+// ListObjectsPage - (List Objects Page) - List one page of objects
+// recursively, for stateless callers resuming a listing across
+// separate requests, e.g. paginated web handlers.
+//
+// Listing starts after the object startAfter, an empty startAfter
+// starts at the beginning. At most maxKeys objects are returned, 0
+// lists up to 1000 objects. Pass the returned nextToken as startAfter
+// to get the next page, nextToken is empty once the listing is done.
+//
+//   api := client.New(....)
+//   var token string
+//   for {
+//       objects, nextToken, err := api.ListObjectsPage("mytestbucket", "starthere", token, 100)
+//       if err != nil {
+//           break
+//       }
+//       fmt.Println(objects)
+//       if nextToken == "" {
+//           break
+//       }
+//       token = nextToken
+//   }
+//
+func (c Client) ListObjectsPage(bucketName, objectPrefix, startAfter string, maxKeys int) (objects []ObjectInfo, nextToken string, err error) {
+	if maxKeys < 0 {
+		return nil, "", ErrInvalidArgument("Max keys cannot be negative.")
+	}
+	result, err := c.listObjectsQuery(bucketName, objectPrefix, startAfter, "", maxKeys)
+	if err != nil {
+		return nil, "", err
+	}
+	objects = result.Contents
+
+	// Listing ends when result is not truncated.
+	if !result.IsTruncated {
+		return objects, "", nil
+	}
+	// Next marker is not returned for undelimited listings, continue
+	// after the last key instead.
+	nextToken = result.NextMarker
+	if nextToken == "" && len(objects) > 0 {
+		nextToken = objects[len(objects)-1].Key
+	}
+	return objects, nextToken, nil
+}
+
+// ListDirectories - (List Directories) - List the immediate child
+// prefixes of objectPrefix delimited at '/', e.g. to show the folders
+// of a file browser. Objects are not returned, prefixes end with '/'.
+//
+// Pass a prefix ending with '/' to list the directories inside it,
+// an empty prefix lists the top level directories of the bucket.
+//
+//   api := client.New(....)
+//   dirs, err := api.ListDirectories("mytestbucket", "photos/")
+//   if err != nil {
+//       return err
+//   }
+//   fmt.Println(dirs) // [photos/2016/ photos/2017/]
+//
+func (c Client) ListDirectories(bucketName, objectPrefix string) ([]string, error) {
+	var dirs []string
+	var marker string
+	for {
+		// Keys and prefixes count against max keys alike, list as
+		// many as possible per request.
+		result, err := c.listObjectsQuery(bucketName, objectPrefix, marker, "/", 1000)
+		if err != nil {
+			return nil, err
+		}
+		for _, prefix := range result.CommonPrefixes {
+			dirs = append(dirs, prefix.Prefix)
+		}
+
+		// Listing ends when result is not truncated.
+		if !result.IsTruncated {
+			return dirs, nil
+		}
+		// Continue after the last key or prefix if the next marker
+		// is not returned.
+		nextMarker := result.NextMarker
+		if nextMarker == "" {
+			if len(result.Contents) > 0 {
+				nextMarker = result.Contents[len(result.Contents)-1].Key
+			}
+			if len(result.CommonPrefixes) > 0 {
+				if prefix := result.CommonPrefixes[len(result.CommonPrefixes)-1].Prefix; prefix > nextMarker {
+					nextMarker = prefix
+				}
+			}
+		}
+		if nextMarker == "" || nextMarker == marker {
+			return nil, ErrInvalidArgument("Listing truncated without a next marker. " + reportIssue)
+		}
+		marker = nextMarker
+	}
+}
+
+// ListObjectVersions - (List Object Versions) - List all versions
+// of some objects or all recursively.
+//
+// ListObjectVersions is similar to ListObjects but lists every
+// version of the objects, including delete markers, in a versioned
+// bucket. Versions of an object are listed latest first, each entry
+// carries its VersionID and whether it is the latest version or a
+// delete marker.
+//
+//   api := client.New(....)
+//   // Create a done channel.
+//   doneCh := make(chan struct{})
+//   defer close(doneCh)
+//   // Recurively list all object versions in 'mytestbucket'
+//   recursive := true
+//   for message := range api.ListObjectVersions("mytestbucket", "starthere", recursive, doneCh) {
+//       fmt.Println(message)
+//   }
+//
+func (c Client) ListObjectVersions(bucketName, objectPrefix string, recursive bool, doneCh <-chan struct{}) <-chan ObjectInfo {
+	// Allocate new list object versions channel.
+	objectStatCh := make(chan ObjectInfo, 1000)
+	// Default listing is delimited at "/"
+	delimiter := "/"
+	if recursive {
+		// If recursive we do not delimit.
+		delimiter = ""
+	}
+	// Validate bucket name.
+	if err := isValidBucketName(bucketName); err != nil {
+		defer close(objectStatCh)
+		objectStatCh <- ObjectInfo{
+			Err: err,
+		}
+		return objectStatCh
+	}
+	// Validate incoming object prefix.
+	if err := isValidObjectPrefix(objectPrefix); err != nil {
+		defer close(objectStatCh)
+		objectStatCh <- ObjectInfo{
+			Err: err,
+		}
+		return objectStatCh
+	}
+
+	// Initiate list object versions goroutine here.
+	go func(objectStatCh chan<- ObjectInfo) {
+		defer close(objectStatCh)
+		// Save key and version id markers for next request.
+		var keyMarker, versionIDMarker string
+		for {
+			// Get list of object versions a maximum of 1000 per request.
+			result, err := c.listObjectVersionsQuery(bucketName, objectPrefix, keyMarker, versionIDMarker, delimiter, c.listMaxKeys)
+			if err != nil {
+				sendListError(objectStatCh, err, doneCh)
+				return
+			}
+
+			// If versions are available loop through and send over channel.
+			for _, version := range result.Versions {
+				object := version.ObjectInfo
+				switch version.XMLName.Local {
+				case "Version":
+				case "DeleteMarker":
+					object.IsDeleteMarker = true
+				default:
+					// Skip any unknown elements.
+					continue
+				}
+				select {
+				// Send object version.
+				case objectStatCh <- object:
+				// If receives done from the caller, return here.
+				case <-doneCh:
+					return
+				}
+			}
+
+			// Send all common prefixes if any.
+			// NOTE: prefixes are only present if the request is delimited.
+			for _, obj := range result.CommonPrefixes {
+				object := ObjectInfo{}
+				object.Key = obj.Prefix
+				object.Size = 0
+				select {
+				// Send object prefixes.
+				case objectStatCh <- object:
+				// If receives done from the caller, return here.
+				case <-doneCh:
+					return
+				}
+			}
+
+			// Save markers for next request.
+			keyMarker = result.NextKeyMarker
+			versionIDMarker = result.NextVersionIDMarker
+
+			// Listing ends result is not truncated, return right here.
+			if !result.IsTruncated {
+				return
+			}
+			// Restarting from the first page would never end.
+			if keyMarker == "" {
+				sendListError(objectStatCh, ErrInvalidArgument("Listing truncated without a next key marker. "+reportIssue), doneCh)
+				return
+			}
 		}
 	}(objectStatCh)
 	return objectStatCh
 }
 
+// sendListError - sends err as the last value of a listing, unless
+// the caller is done reading the listing.
+func sendListError(objectStatCh chan<- ObjectInfo, err error, doneCh <-chan struct{}) {
+	select {
+	case objectStatCh <- ObjectInfo{Err: err}:
+	case <-doneCh:
+	}
+}
+
 /// Bucket Read Operations.
 
 // listObjects - (List Objects) - List some or all (up to 1000) of the objects in a bucket.
@@ -224,6 +516,77 @@ func (c Client) listObjectsQuery(bucketName, objectPrefix, objectMarker, delimit
 	return listBucketResult, nil
 }
 
+// listObjectVersionsQuery - (List Object Versions) - List some or all (up to 1000) of the object versions in a bucket.
+//
+// You can use the request parameters as selection criteria to return a subset of the object versions in a bucket.
+// request parameters :-
+// ---------
+// ?key-marker - Specifies the key to start with when listing object versions in a bucket.
+// ?version-id-marker - Together with key-marker specifies the version after which listing should begin.
+// ?delimiter - A delimiter is a character you use to group keys.
+// ?prefix - Limits the response to keys that begin with the specified prefix.
+// ?max-keys - Sets the maximum number of keys returned in the response body.
+func (c Client) listObjectVersionsQuery(bucketName, objectPrefix, keyMarker, versionIDMarker, delimiter string, maxkeys int) (listVersionsResult, error) {
+	// Validate bucket name.
+	if err := isValidBucketName(bucketName); err != nil {
+		return listVersionsResult{}, err
+	}
+	// Validate object prefix.
+	if err := isValidObjectPrefix(objectPrefix); err != nil {
+		return listVersionsResult{}, err
+	}
+	// Get resources properly escaped and lined up before
+	// using them in http request.
+	urlValues := make(url.Values)
+	// Set versions.
+	urlValues.Set("versions", "")
+	// Set object prefix.
+	if objectPrefix != "" {
+		urlValues.Set("prefix", objectPrefix)
+	}
+	// Set key marker.
+	if keyMarker != "" {
+		urlValues.Set("key-marker", keyMarker)
+	}
+	// Set version id marker.
+	if versionIDMarker != "" {
+		urlValues.Set("version-id-marker", versionIDMarker)
+	}
+	// Set delimiter.
+	if delimiter != "" {
+		urlValues.Set("delimiter", delimiter)
+	}
+
+	// maxkeys should default to 1000 or less.
+	if maxkeys == 0 || maxkeys > 1000 {
+		maxkeys = 1000
+	}
+	// Set max keys.
+	urlValues.Set("max-keys", fmt.Sprintf("%d", maxkeys))
+
+	// Execute GET on bucket to list object versions.
+	resp, err := c.executeMethod("GET", requestMetadata{
+		bucketName:  bucketName,
+		queryValues: urlValues,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return listVersionsResult{}, err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return listVersionsResult{}, httpRespToErrorResponse(resp, bucketName, "")
+		}
+	}
+	// Decode list versions XML.
+	listVersionsResult := listVersionsResult{}
+	err = xmlDecoder(resp.Body, &listVersionsResult)
+	if err != nil {
+		return listVersionsResult, err
+	}
+	return listVersionsResult, nil
+}
+
 // ListIncompleteUploads - List incompletely uploaded multipart objects.
 //
 // ListIncompleteUploads lists all incompleted objects matching the
@@ -245,12 +608,77 @@ func (c Client) listObjectsQuery(bucketName, objectPrefix, objectMarker, delimit
 //       fmt.Println(message)
 //   }
 //
+// Size of the listed uploads is not calculated, use
+// ListIncompleteUploadsWithSize to list uploads with their size.
 func (c Client) ListIncompleteUploads(bucketName, objectPrefix string, recursive bool, doneCh <-chan struct{}) <-chan ObjectMultipartInfo {
+	// Turn off size aggregation of individual parts.
+	isAggregateSize := false
+	return c.listIncompleteUploads(bucketName, objectPrefix, recursive, isAggregateSize, doneCh)
+}
+
+// ListIncompleteUploadsWithSize - List incompletely uploaded multipart
+// objects along with the total size of their uploaded parts.
+//
+// Similar to ListIncompleteUploads, but lists the uploaded parts of every
+// upload to calculate its size, which costs an additional request per
+// upload. Size and Initiated time can be used to find old or large
+// uploads to be removed.
+func (c Client) ListIncompleteUploadsWithSize(bucketName, objectPrefix string, recursive bool, doneCh <-chan struct{}) <-chan ObjectMultipartInfo {
 	// Turn on size aggregation of individual parts.
 	isAggregateSize := true
 	return c.listIncompleteUploads(bucketName, objectPrefix, recursive, isAggregateSize, doneCh)
 }
 
+// CountIncompleteUploads - returns the number and total size of all
+// incomplete uploads matching objectPrefix which were initiated more
+// than olderThan ago, nothing is removed. Zero olderThan counts all
+// incomplete uploads.
+//
+// Use RemoveIncompleteUploads with the same arguments to remove them.
+func (c Client) CountIncompleteUploads(bucketName, objectPrefix string, olderThan time.Duration) (IncompleteUploadsInfo, error) {
+	var info IncompleteUploadsInfo
+	// Turn on size aggregation of individual parts.
+	isAggregateSize := true
+	err := c.walkIncompleteUploads(bucketName, objectPrefix, olderThan, isAggregateSize, func(upload ObjectMultipartInfo) error {
+		info.Count++
+		info.Size += upload.Size
+		return nil
+	})
+	if err != nil {
+		return IncompleteUploadsInfo{}, err
+	}
+	return info, nil
+}
+
+// walkIncompleteUploads calls walkFn for all incomplete uploads matching
+// objectPrefix which were initiated more than olderThan ago.
+func (c Client) walkIncompleteUploads(bucketName, objectPrefix string, olderThan time.Duration, aggregateSize bool, walkFn func(ObjectMultipartInfo) error) error {
+	if olderThan < 0 {
+		return ErrInvalidArgument("Age of incomplete uploads cannot be negative.")
+	}
+	// Uploads initiated after this time are skipped.
+	initiatedBefore := time.Now().UTC().Add(-olderThan)
+
+	// Create done channel to cleanup the routine.
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	// List all incomplete uploads recursively.
+	isRecursive := true
+	for upload := range c.listIncompleteUploads(bucketName, objectPrefix, isRecursive, aggregateSize, doneCh) {
+		if upload.Err != nil {
+			return upload.Err
+		}
+		if olderThan > 0 && !upload.Initiated.Before(initiatedBefore) {
+			continue
+		}
+		if err := walkFn(upload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // listIncompleteUploads lists all incomplete uploads.
 func (c Client) listIncompleteUploads(bucketName, objectPrefix string, recursive, aggregateSize bool, doneCh <-chan struct{}) <-chan ObjectMultipartInfo {
 	// Allocate channel for multipart uploads.
@@ -286,8 +714,9 @@ func (c Client) listIncompleteUploads(bucketName, objectPrefix string, recursive
 			// list all multipart uploads.
 			result, err := c.listMultipartUploadsQuery(bucketName, objectMarker, uploadIDMarker, objectPrefix, delimiter, 1000)
 			if err != nil {
-				objectMultipartStatCh <- ObjectMultipartInfo{
-					Err: err,
+				select {
+				case objectMultipartStatCh <- ObjectMultipartInfo{Err: err}:
+				case <-doneCh:
 				}
 				return
 			}
@@ -301,9 +730,11 @@ func (c Client) listIncompleteUploads(bucketName, objectPrefix string, recursive
 					// Get total multipart size.
 					obj.Size, err = c.getTotalMultipartSize(bucketName, obj.Key, obj.UploadID)
 					if err != nil {
-						objectMultipartStatCh <- ObjectMultipartInfo{
-							Err: err,
+						select {
+						case objectMultipartStatCh <- ObjectMultipartInfo{Err: err}:
+						case <-doneCh:
 						}
+						return
 					}
 				}
 				select {
@@ -332,6 +763,14 @@ func (c Client) listIncompleteUploads(bucketName, objectPrefix string, recursive
 			if !result.IsTruncated {
 				return
 			}
+			// Restarting from the first page would never end.
+			if objectMarker == "" {
+				select {
+				case objectMultipartStatCh <- ObjectMultipartInfo{Err: ErrInvalidArgument("Listing truncated without a next key marker. " + reportIssue)}:
+				case <-doneCh:
+				}
+				return
+			}
 		}
 	}(objectMultipartStatCh)
 	// return.
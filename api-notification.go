@@ -0,0 +1,192 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ListenBucketNotification - listen on bucket notifications.
+//
+// ListenBucketNotification holds a long lived connection open to a
+// Minio server and sends all notification events matching the
+// prefix, suffix and events over the returned channel. Transient
+// disconnects are retried with a capped exponential back off which
+// starts over once connected, the routine stops once doneCh is closed.
+//
+//   api := client.New(....)
+//   // Create a done channel.
+//   doneCh := make(chan struct{})
+//   defer close(doneCh)
+//   events := []string{"s3:ObjectCreated:*", "s3:ObjectRemoved:*"}
+//   for info := range api.ListenBucketNotification("mytestbucket", "photos/", ".jpg", events, doneCh) {
+//       fmt.Println(info)
+//   }
+//
+// NOTE: This API is only supported by Minio servers.
+func (c Client) ListenBucketNotification(bucketName, prefix, suffix string, events []string, doneCh <-chan struct{}) <-chan NotificationInfo {
+	notificationInfoCh := make(chan NotificationInfo, 1)
+	// Validate bucket name.
+	if err := isValidBucketName(bucketName); err != nil {
+		defer close(notificationInfoCh)
+		sendNotificationError(notificationInfoCh, err, doneCh)
+		return notificationInfoCh
+	}
+	// Validate incoming object prefix.
+	if err := isValidObjectPrefix(prefix); err != nil {
+		defer close(notificationInfoCh)
+		sendNotificationError(notificationInfoCh, err, doneCh)
+		return notificationInfoCh
+	}
+	// Listening on bucket notifications is specific to Minio servers.
+	if isAmazonEndpoint(c.endpointURL) || c.isGoogleCompatible() {
+		defer close(notificationInfoCh)
+		sendNotificationError(notificationInfoCh, ErrAPINotSupported("Listening on bucket notifications is specific only to Minio servers."), doneCh)
+		return notificationInfoCh
+	}
+
+	// The connection is held open indefinitely, do not let the
+	// client level timeout cancel the request. Client is a copy
+	// here, so this does not affect the caller.
+	c.httpClient = &http.Client{
+		Transport:     c.httpClient.Transport,
+		CheckRedirect: doNotFollowRedirects,
+	}
+
+	// listen - listens until the connection drops, returns whether it
+	// connected, i.e. received at least one record, and whether
+	// listening must stop.
+	listen := func() (connected, stop bool) {
+		urlValues := make(url.Values)
+		urlValues.Set("prefix", prefix)
+		urlValues.Set("suffix", suffix)
+		urlValues["events"] = events
+
+		// Execute GET on bucket to listen on notifications.
+		resp, err := c.executeMethod("GET", requestMetadata{
+			bucketName:  bucketName,
+			queryValues: urlValues,
+		})
+		if err != nil {
+			if isNetErrorRetryable(err) {
+				return false, false // Retry.
+			}
+			sendNotificationError(notificationInfoCh, err, doneCh)
+			return false, true
+		}
+
+		// Validate http response, upon error return quickly.
+		if resp.StatusCode != http.StatusOK {
+			errResponse := httpRespToAPIErrorResponse(resp, bucketName, "", "Listening on bucket notifications")
+			closeResponse(resp)
+			sendNotificationError(notificationInfoCh, errResponse, doneCh)
+			return false, true
+		}
+
+		// Close the response body once doneCh is closed, which
+		// unblocks the decoder below.
+		closedCh := make(chan struct{})
+		defer close(closedCh)
+		defer closeResponse(resp)
+		go func() {
+			select {
+			case <-doneCh:
+				resp.Body.Close()
+			case <-closedCh:
+			}
+		}()
+
+		// Decode each event record, records are separated by
+		// white space which is also sent as keep alive.
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var notificationInfo NotificationInfo
+			if err = decoder.Decode(&notificationInfo); err != nil {
+				select {
+				// Body was closed as doneCh is closed.
+				case <-doneCh:
+					return connected, true
+				default:
+				}
+				// Connection was dropped, reconnect. Servers closing
+				// the connection before sending any record are
+				// retried with the back off.
+				return connected, false
+			}
+			connected = true
+			// Skip keep alive records.
+			if len(notificationInfo.Records) == 0 {
+				continue
+			}
+			select {
+			// Send notification events.
+			case notificationInfoCh <- notificationInfo:
+			// If receives done from the caller, return here.
+			case <-doneCh:
+				return true, true
+			}
+		}
+	}
+
+	// Continously run and listen on bucket notification.
+	go func() {
+		defer close(notificationInfoCh)
+
+		// Retry with a capped exponential back off until doneCh is
+		// closed. The back off starts over once a connection received
+		// records, drops of long-lived connections are retried right
+		// away.
+		for {
+			timerDoneCh := make(chan struct{})
+			retryTimerCh := c.newRetryTimerContinous(time.Second, time.Second*30, MaxJitter, timerDoneCh)
+			connected, stop := false, false
+			for !connected && !stop {
+				select {
+				case <-retryTimerCh:
+					// Closed doneCh takes precedence over the timer.
+					select {
+					case <-doneCh:
+						stop = true
+					default:
+						connected, stop = listen()
+					}
+				case <-doneCh:
+					stop = true
+				}
+			}
+			close(timerDoneCh)
+			if stop {
+				return
+			}
+		}
+	}()
+
+	// Returns the notification info channel, for caller to start reading from.
+	return notificationInfoCh
+}
+
+// sendNotificationError - sends err as the last notification, unless
+// the caller is done reading notifications.
+func sendNotificationError(notificationInfoCh chan<- NotificationInfo, err error, doneCh <-chan struct{}) {
+	select {
+	case notificationInfoCh <- NotificationInfo{Err: err}:
+	case <-doneCh:
+	}
+}
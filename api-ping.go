@@ -0,0 +1,55 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import "net/http"
+
+// Ping - verifies with a single request that the endpoint is reachable
+// and, for authenticated clients, that the credentials are accepted.
+// Requests are not retried so that problems are reported quickly.
+//
+// DNS and connection failures are reported as an ErrorResponse with Code
+// 'EndpointUnreachable', rejected credentials with the Code returned by
+// the server, for example 'InvalidAccessKeyId' or 'SignatureDoesNotMatch'.
+func (c Client) Ping() error {
+	// Listing buckets is the cheapest request which verifies
+	// credentials.
+	req, err := c.newRequest("GET", requestMetadata{})
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(req)
+	defer closeResponse(resp)
+	if err != nil {
+		return ErrEndpointUnreachable(c.endpointURL.Host, err)
+	}
+	// Any response means the endpoint is online, credentials of
+	// anonymous clients cannot be verified.
+	if c.anonymous {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return httpRespToErrorResponse(resp, "", "")
+	}
+	return nil
+}
+
+// IsOnline - reports whether the endpoint is reachable and accepts the
+// credentials, see Ping for the reason of a failure.
+func (c Client) IsOnline() bool {
+	return c.Ping() == nil
+}
@@ -18,6 +18,7 @@ package minio
 
 import (
 	"errors"
+	"net/http"
 	"net/url"
 	"time"
 )
@@ -33,7 +34,7 @@ var supportedGetReqParams = map[string]struct{}{
 
 // presignURL - Returns a presigned URL for an input 'method'.
 // Expires maximum is 7days - ie. 604800 and minimum is 1.
-func (c Client) presignURL(method string, bucketName string, objectName string, expires time.Duration, reqParams url.Values) (urlStr string, err error) {
+func (c Client) presignURL(method string, bucketName string, objectName string, expires time.Duration, reqParams url.Values, headers http.Header) (urlStr string, err error) {
 	// Input validation.
 	if method == "" {
 		return "", ErrInvalidArgument("method cannot be empty.")
@@ -55,6 +56,8 @@ func (c Client) presignURL(method string, bucketName string, objectName string,
 		bucketName: bucketName,
 		objectName: objectName,
 		expires:    expireSeconds,
+		// Headers to be sent by the user of the URL.
+		customHeader: headers,
 	}
 
 	// For "GET" we are handling additional request parameters to
@@ -80,18 +83,86 @@ func (c Client) presignURL(method string, bucketName string, objectName string,
 	return req.URL.String(), nil
 }
 
+// PublicURL - Returns the unsigned URL of an object, e.g. to link to
+// objects of public-read buckets. The URL is virtual host style or path
+// style the same way requests of the client are addressed, the object
+// name is escaped.
+//
+// No request is sent, except for Amazon S3 endpoints where the location
+// of the bucket is looked up once if it is not cached yet.
+func (c Client) PublicURL(bucketName, objectName string) (*url.URL, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return nil, err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return nil, err
+	}
+	if c.isObjectNameNormalized {
+		objectName = normalizeObjectName(objectName)
+		if objectName == "" {
+			return nil, ErrInvalidObjectName("Object name cannot be empty after normalization.")
+		}
+	}
+
+	// Location only selects the host of Amazon S3 endpoints.
+	location := "us-east-1"
+	if isAmazonEndpoint(c.endpointURL) {
+		var err error
+		if location, err = c.getBucketLocation(bucketName); err != nil {
+			return nil, err
+		}
+	}
+	return c.makeTargetURL(bucketName, objectName, location, nil)
+}
+
 // PresignedGetObject - Returns a presigned URL to access an object
 // without credentials. Expires maximum is 7days - ie. 604800 and
 // minimum is 1. Additionally you can override a set of response
 // headers using the query parameters.
+//
+// URLs are signed locally, but the location of the bucket is looked up
+// once if it is not cached yet. Use PresignedGetObjectWithRegion to
+// never send a request.
 func (c Client) PresignedGetObject(bucketName string, objectName string, expires time.Duration, reqParams url.Values) (url string, err error) {
-	return c.presignURL("GET", bucketName, objectName, expires, reqParams)
+	return c.presignURL("GET", bucketName, objectName, expires, reqParams, nil)
+}
+
+// PresignedGetObjectWithRegion - same as PresignedGetObject, but the
+// URL is signed for the given region instead of the location of the
+// bucket. No request is sent, which allows generating URLs offline or
+// without access to the bucket.
+func (c Client) PresignedGetObjectWithRegion(bucketName string, objectName string, expires time.Duration, reqParams url.Values, region string) (url string, err error) {
+	if region == "" {
+		return "", ErrInvalidArgument("Region cannot be empty.")
+	}
+	return c.withBucketLocation(bucketName, region).presignURL("GET", bucketName, objectName, expires, reqParams, nil)
 }
 
 // PresignedPutObject - Returns a presigned URL to upload an object without credentials.
 // Expires maximum is 7days - ie. 604800 and minimum is 1.
 func (c Client) PresignedPutObject(bucketName string, objectName string, expires time.Duration) (url string, err error) {
-	return c.presignURL("PUT", bucketName, objectName, expires, nil)
+	return c.presignURL("PUT", bucketName, objectName, expires, nil, nil)
+}
+
+// PresignedPutObjectWithRegion - same as PresignedPutObject, but the
+// URL is signed for the given region instead of the location of the
+// bucket. No request is sent, which allows generating URLs offline or
+// without access to the bucket.
+func (c Client) PresignedPutObjectWithRegion(bucketName string, objectName string, expires time.Duration, region string) (url string, err error) {
+	if region == "" {
+		return "", ErrInvalidArgument("Region cannot be empty.")
+	}
+	return c.withBucketLocation(bucketName, region).presignURL("PUT", bucketName, objectName, expires, nil, nil)
+}
+
+// PresignedPutObjectWithHeaders - Returns a presigned URL to upload an
+// object without credentials, signed along with the given headers
+// such as Content-Type or x-amz-acl. The upload has to send all the
+// headers with the same values, otherwise it fails the signature
+// check. Expires maximum is 7days - ie. 604800 and minimum is 1.
+func (c Client) PresignedPutObjectWithHeaders(bucketName string, objectName string, expires time.Duration, headers http.Header) (url string, err error) {
+	return c.presignURL("PUT", bucketName, objectName, expires, nil, headers)
 }
 
 // PresignedPostPolicy - Returns POST form data to upload an object at a location.
@@ -114,6 +185,24 @@ func (c Client) PresignedPostPolicy(p *PostPolicy) (map[string]string, error) {
 		return nil, err
 	}
 
+	// Get credentials, retrieved again if expired.
+	accessKeyID, secretAccessKey, sessionToken, err := c.getCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	// Add session token policy for temporary credentials.
+	if sessionToken != "" {
+		if err = p.addNewPolicy(policyCondition{
+			matchType: "eq",
+			condition: "$x-amz-security-token",
+			value:     sessionToken,
+		}); err != nil {
+			return nil, err
+		}
+		p.formData["x-amz-security-token"] = sessionToken
+	}
+
 	// Keep time.
 	t := time.Now().UTC()
 	// For signature version '2' handle here.
@@ -121,14 +210,14 @@ func (c Client) PresignedPostPolicy(p *PostPolicy) (map[string]string, error) {
 		policyBase64 := p.base64()
 		p.formData["policy"] = policyBase64
 		// For Google endpoint set this value to be 'GoogleAccessId'.
-		if isGoogleEndpoint(c.endpointURL) {
-			p.formData["GoogleAccessId"] = c.accessKeyID
+		if c.isGoogleCompatible() {
+			p.formData["GoogleAccessId"] = accessKeyID
 		} else {
 			// For all other endpoints set this value to be 'AWSAccessKeyId'.
-			p.formData["AWSAccessKeyId"] = c.accessKeyID
+			p.formData["AWSAccessKeyId"] = accessKeyID
 		}
 		// Sign the policy.
-		p.formData["signature"] = postPresignSignatureV2(policyBase64, c.secretAccessKey)
+		p.formData["signature"] = postPresignSignatureV2(policyBase64, secretAccessKey)
 		return p.formData, nil
 	}
 
@@ -151,7 +240,7 @@ func (c Client) PresignedPostPolicy(p *PostPolicy) (map[string]string, error) {
 	}
 
 	// Add a credential policy.
-	credential := getCredential(c.accessKeyID, location, t)
+	credential := getCredential(accessKeyID, location, t, serviceTypeS3)
 	if err = p.addNewPolicy(policyCondition{
 		matchType: "eq",
 		condition: "$x-amz-credential",
@@ -167,6 +256,6 @@ func (c Client) PresignedPostPolicy(p *PostPolicy) (map[string]string, error) {
 	p.formData["x-amz-algorithm"] = signV4Algorithm
 	p.formData["x-amz-credential"] = credential
 	p.formData["x-amz-date"] = t.Format(iso8601DateFormat)
-	p.formData["x-amz-signature"] = postPresignSignatureV4(policyBase64, t, c.secretAccessKey, location)
+	p.formData["x-amz-signature"] = postPresignSignatureV4(policyBase64, t, secretAccessKey, location)
 	return p.formData, nil
 }
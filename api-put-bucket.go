@@ -32,6 +32,10 @@ import (
 // Optional arguments are acl and location - by default all buckets are created
 // with ``private`` acl and in US Standard region.
 //
+// An empty location is the same as 'us-east-1', the request is then
+// sent without a location constraint, which Amazon S3 rejects for
+// 'us-east-1'.
+//
 // ACL valid values - http://docs.aws.amazon.com/AmazonS3/latest/dev/acl-overview.html
 //
 //  private - owner gets full access [default].
@@ -42,6 +46,43 @@ import (
 // For Amazon S3 for more supported regions - http://docs.aws.amazon.com/general/latest/gr/rande.html
 // For Google Cloud Storage for more supported regions - https://cloud.google.com/storage/docs/bucket-locations
 func (c Client) MakeBucket(bucketName string, acl BucketACL, location string) error {
+	return c.makeBucket(bucketName, acl, location, false)
+}
+
+// MakeBucketWithObjectLock makes a new bucket with object lock enabled,
+// arguments are the same as MakeBucket.
+//
+// Object lock can only be enabled while creating a bucket, versioning
+// is enabled along with it and cannot be suspended afterwards. Objects
+// in the bucket can be protected with PutObjectRetention and
+// PutObjectLegalHold.
+//
+// Google Cloud Storage does not support object lock and fails with
+// ErrAPINotSupported. Servers and regions without object lock support
+// fail with ErrAPINotSupported.
+func (c Client) MakeBucketWithObjectLock(bucketName string, acl BucketACL, location string) error {
+	if c.isGoogleCompatible() {
+		return ErrAPINotSupported("Object lock is not supported by Google Cloud Storage.")
+	}
+	return c.makeBucket(bucketName, acl, location, true)
+}
+
+// MakeBucketIfNotExists makes a new bucket unless you already own a
+// bucket of this name, arguments are the same as MakeBucket.
+//
+// A bucket you already own is left as is, its ACL and location are not
+// changed. Buckets of this name owned by someone else still fail with
+// 'BucketAlreadyExists'.
+func (c Client) MakeBucketIfNotExists(bucketName string, acl BucketACL, location string) error {
+	err := c.MakeBucket(bucketName, acl, location)
+	if ToErrorResponse(err).Code == "BucketAlreadyOwnedByYou" {
+		return nil
+	}
+	return err
+}
+
+// makeBucket makes a new bucket, optionally with object lock enabled.
+func (c Client) makeBucket(bucketName string, acl BucketACL, location string, objectLockEnabled bool) error {
 	// Validate the input arguments.
 	if err := isValidBucketName(bucketName); err != nil {
 		return err
@@ -56,7 +97,7 @@ func (c Client) MakeBucket(bucketName string, acl BucketACL, location string) er
 	}
 
 	// Instantiate the request.
-	req, err := c.makeBucketRequest(bucketName, acl, location)
+	req, err := c.makeBucketRequest(bucketName, acl, location, objectLockEnabled)
 	if err != nil {
 		return err
 	}
@@ -70,6 +111,9 @@ func (c Client) MakeBucket(bucketName string, acl BucketACL, location string) er
 
 	if resp != nil {
 		if resp.StatusCode != http.StatusOK {
+			if objectLockEnabled {
+				return httpRespToAPIErrorResponse(resp, bucketName, "", "Object lock")
+			}
 			return httpRespToErrorResponse(resp, bucketName, "")
 		}
 	}
@@ -82,7 +126,7 @@ func (c Client) MakeBucket(bucketName string, acl BucketACL, location string) er
 }
 
 // makeBucketRequest constructs request for makeBucket.
-func (c Client) makeBucketRequest(bucketName string, acl BucketACL, location string) (*http.Request, error) {
+func (c Client) makeBucketRequest(bucketName string, acl BucketACL, location string, objectLockEnabled bool) (*http.Request, error) {
 	// Validate input arguments.
 	if err := isValidBucketName(bucketName); err != nil {
 		return nil, err
@@ -112,8 +156,14 @@ func (c Client) makeBucketRequest(bucketName string, acl BucketACL, location str
 		req.Header.Set("x-amz-acl", string(acl))
 	}
 
+	// Enable object lock, only possible while creating a bucket.
+	if objectLockEnabled {
+		req.Header.Set("x-amz-bucket-object-lock-enabled", "true")
+	}
+
 	// set UserAgent for the request.
 	c.setUserAgent(req)
+	c.setCustomHeader(req)
 
 	// set sha256 sum for signature calculation only with signature version '4'.
 	if c.signature.isV4() {
@@ -137,13 +187,22 @@ func (c Client) makeBucketRequest(bucketName string, acl BucketACL, location str
 		}
 	}
 
+	// Get credentials, retrieved again if expired.
+	accessKeyID, secretAccessKey, sessionToken, err := c.getCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	// Adjust signing time for server clock offset if enabled.
+	c.setSigningTime(req)
+
 	// Sign the request.
 	if c.signature.isV4() {
 		// Signature calculated for MakeBucket request should be for 'us-east-1',
 		// regardless of the bucket's location constraint.
-		req = signV4(*req, c.accessKeyID, c.secretAccessKey, "us-east-1")
+		req = signV4(*req, accessKeyID, secretAccessKey, sessionToken, "us-east-1")
 	} else if c.signature.isV2() {
-		req = signV2(*req, c.accessKeyID, c.secretAccessKey)
+		req = signV2(*req, accessKeyID, secretAccessKey, sessionToken)
 	}
 
 	// Return signed request.
@@ -204,3 +263,348 @@ func (c Client) SetBucketACL(bucketName string, acl BucketACL) error {
 	// return
 	return nil
 }
+
+// SetBucketACLPolicy set the full access control policy of an existing
+// bucket, replacing its ACL with the explicit grants of policy.
+//
+// Grantees are canonical users by ID, groups by URI or customers by
+// email address. The owner is usually taken from GetBucketACLPolicy.
+//
+//  policy, err := api.GetBucketACLPolicy("mybucket")
+//  policy.Grants = append(policy.Grants, minio.Grant{
+//          Grantee: minio.Grantee{
+//                  Type: minio.GranteeCanonicalUser,
+//                  ID:   "79a59df900b949e55d96a1e698fbaced",
+//          },
+//          Permission: minio.PermissionRead,
+//  })
+//  err = api.SetBucketACLPolicy("mybucket", policy)
+func (c Client) SetBucketACLPolicy(bucketName string, policy AccessControlPolicy) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+	if err := isValidAccessControlPolicy(policy); err != nil {
+		return err
+	}
+	return c.putAccessControlPolicy(bucketName, "", policy)
+}
+
+// putAccessControlPolicy - replace the access control policy of a
+// bucket, or of an object if objectName is set.
+func (c Client) putAccessControlPolicy(bucketName, objectName string, policy AccessControlPolicy) error {
+	policyBytes, err := xml.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	// Set acl query.
+	urlValues := make(url.Values)
+	urlValues.Set("acl", "")
+
+	reqMetadata := requestMetadata{
+		bucketName:         bucketName,
+		objectName:         objectName,
+		queryValues:        urlValues,
+		contentBody:        bytes.NewReader(policyBytes),
+		contentLength:      int64(len(policyBytes)),
+		contentMD5Bytes:    sumMD5(policyBytes),
+		contentSHA256Bytes: c.payloadSHA256(policyBytes),
+	}
+
+	// Execute PUT acl on bucketName or objectName.
+	resp, err := c.executeMethod("PUT", reqMetadata)
+	defer closeResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return httpRespToErrorResponse(resp, bucketName, objectName)
+		}
+	}
+	return nil
+}
+
+// SetBucketLifecycle set the lifecycle configuration on an existing bucket.
+//
+// Lifecycle rules automate expiration and transition of objects
+// matching a prefix, for example to clean up incomplete multipart
+// uploads.
+//
+//  lifecycle := minio.BucketLifecycle{
+//          Rules: []minio.LifecycleRule{{
+//                  ID:     "abort-incomplete-uploads",
+//                  Status: minio.LifecycleEnabled,
+//                  AbortIncompleteMultipartUpload: &minio.AbortIncompleteMultipartUpload{
+//                          DaysAfterInitiation: 7,
+//                  },
+//          }},
+//  }
+//
+// Returns ErrAPINotSupported if the server does not support lifecycle
+// configuration.
+func (c Client) SetBucketLifecycle(bucketName string, lifecycle BucketLifecycle) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+	if err := isValidBucketLifecycle(lifecycle); err != nil {
+		return err
+	}
+
+	// Set lifecycle query.
+	urlValues := make(url.Values)
+	urlValues.Set("lifecycle", "")
+
+	// Marshal lifecycle body.
+	lifecycleBytes, err := xml.Marshal(lifecycle)
+	if err != nil {
+		return err
+	}
+
+	reqMetadata := requestMetadata{
+		bucketName:         bucketName,
+		queryValues:        urlValues,
+		contentBody:        bytes.NewReader(lifecycleBytes),
+		contentLength:      int64(len(lifecycleBytes)),
+		contentMD5Bytes:    sumMD5(lifecycleBytes),
+		contentSHA256Bytes: c.payloadSHA256(lifecycleBytes),
+	}
+
+	// Execute PUT on bucket to set lifecycle.
+	resp, err := c.executeMethod("PUT", reqMetadata)
+	defer closeResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return httpRespToAPIErrorResponse(resp, bucketName, "", "Bucket lifecycle")
+		}
+	}
+	return nil
+}
+
+// SetBucketCORS set the CORS configuration on an existing bucket,
+// replacing any previous configuration.
+//
+// CORS rules allow browser applications of other origins to access
+// the bucket, for example to upload with presigned PUT and POST
+// requests.
+//
+//  cors := minio.BucketCORS{
+//          Rules: []minio.CORSRule{{
+//                  AllowedOrigins: []string{"https://example.com"},
+//                  AllowedMethods: []string{"PUT", "POST"},
+//                  AllowedHeaders: []string{"*"},
+//                  ExposeHeaders:  []string{"ETag"},
+//                  MaxAgeSeconds:  3000,
+//          }},
+//  }
+//
+// Returns ErrAPINotSupported if the server does not support CORS
+// configuration.
+func (c Client) SetBucketCORS(bucketName string, cors BucketCORS) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+	if err := isValidBucketCORS(cors); err != nil {
+		return err
+	}
+
+	// Set cors query.
+	urlValues := make(url.Values)
+	urlValues.Set("cors", "")
+
+	// Marshal cors body.
+	corsBytes, err := xml.Marshal(cors)
+	if err != nil {
+		return err
+	}
+
+	reqMetadata := requestMetadata{
+		bucketName:         bucketName,
+		queryValues:        urlValues,
+		contentBody:        bytes.NewReader(corsBytes),
+		contentLength:      int64(len(corsBytes)),
+		contentMD5Bytes:    sumMD5(corsBytes),
+		contentSHA256Bytes: c.payloadSHA256(corsBytes),
+	}
+
+	// Execute PUT on bucket to set cors.
+	resp, err := c.executeMethod("PUT", reqMetadata)
+	defer closeResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return httpRespToAPIErrorResponse(resp, bucketName, "", "Bucket CORS")
+		}
+	}
+	return nil
+}
+
+// SetObjectLockConfiguration set the object lock configuration of a
+// bucket created with object lock enabled, see
+// MakeBucketWithObjectLock.
+//
+// New objects without retention of their own are retained for the
+// default retention of config.Rule, a nil Rule removes the default
+// retention. The default retention is set either in Days or in Years:
+//
+//  config := minio.ObjectLockConfiguration{
+//          Rule: &minio.ObjectLockRule{
+//                  DefaultRetention: minio.DefaultRetention{
+//                          Mode: minio.RetentionCompliance,
+//                          Days: 30,
+//                  },
+//          },
+//  }
+func (c Client) SetObjectLockConfiguration(bucketName string, config ObjectLockConfiguration) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+	if err := isValidObjectLockConfiguration(config); err != nil {
+		return err
+	}
+	config.ObjectLockEnabled = "Enabled"
+
+	// Set object lock query.
+	urlValues := make(url.Values)
+	urlValues.Set("object-lock", "")
+
+	// Marshal object lock body.
+	configBytes, err := xml.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	reqMetadata := requestMetadata{
+		bucketName:         bucketName,
+		queryValues:        urlValues,
+		contentBody:        bytes.NewReader(configBytes),
+		contentLength:      int64(len(configBytes)),
+		contentMD5Bytes:    sumMD5(configBytes),
+		contentSHA256Bytes: c.payloadSHA256(configBytes),
+	}
+
+	// Execute PUT on bucket to set object lock configuration.
+	resp, err := c.executeMethod("PUT", reqMetadata)
+	defer closeResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return httpRespToAPIErrorResponse(resp, bucketName, "", "Object lock")
+		}
+	}
+	return nil
+}
+
+// SetBucketVersioning set the versioning state of an existing bucket.
+//
+// Valid values are
+//
+//  VersioningEnabled - bucket keeps all versions of its objects.
+//  VersioningSuspended - bucket stops creating new versions.
+//
+// Once versioning is enabled a bucket can never return to the
+// unversioned state, it can only be suspended.
+func (c Client) SetBucketVersioning(bucketName string, status VersioningStatus) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+	if !status.isValidVersioningStatus() {
+		return ErrInvalidArgument("Unrecognized versioning status " + status.String())
+	}
+
+	// Set versioning query.
+	urlValues := make(url.Values)
+	urlValues.Set("versioning", "")
+
+	// Marshal versioning body.
+	versioningBytes, err := xml.Marshal(BucketVersioningConfiguration{Status: status})
+	if err != nil {
+		return err
+	}
+
+	reqMetadata := requestMetadata{
+		bucketName:         bucketName,
+		queryValues:        urlValues,
+		contentBody:        bytes.NewReader(versioningBytes),
+		contentLength:      int64(len(versioningBytes)),
+		contentMD5Bytes:    sumMD5(versioningBytes),
+		contentSHA256Bytes: c.payloadSHA256(versioningBytes),
+	}
+
+	// Execute PUT on bucket to set versioning.
+	resp, err := c.executeMethod("PUT", reqMetadata)
+	defer closeResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return httpRespToAPIErrorResponse(resp, bucketName, "", "Bucket versioning")
+		}
+	}
+	return nil
+}
+
+// SetBucketNotification set the notification configuration on an
+// existing bucket, replacing any previous configuration.
+//
+// Events matching the configured filters are delivered to the
+// configured SQS queue, SNS topic or Lambda function ARNs.
+func (c Client) SetBucketNotification(bucketName string, notification BucketNotification) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+	if err := isValidBucketNotification(notification); err != nil {
+		return err
+	}
+
+	// Set notification query.
+	urlValues := make(url.Values)
+	urlValues.Set("notification", "")
+
+	// Marshal notification body.
+	notificationBytes, err := xml.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	reqMetadata := requestMetadata{
+		bucketName:         bucketName,
+		queryValues:        urlValues,
+		contentBody:        bytes.NewReader(notificationBytes),
+		contentLength:      int64(len(notificationBytes)),
+		contentSHA256Bytes: c.payloadSHA256(notificationBytes),
+	}
+
+	// Execute PUT on bucket to set notification.
+	resp, err := c.executeMethod("PUT", reqMetadata)
+	defer closeResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return httpRespToAPIErrorResponse(resp, bucketName, "", "Bucket notification")
+		}
+	}
+	return nil
+}
+
+// RemoveAllBucketNotification removes all notification configurations
+// of an existing bucket.
+func (c Client) RemoveAllBucketNotification(bucketName string) error {
+	return c.SetBucketNotification(bucketName, BucketNotification{})
+}
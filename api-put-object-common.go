@@ -19,11 +19,13 @@ package minio
 import (
 	"crypto/md5"
 	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"hash"
 	"io"
 	"math"
 	"os"
+	"strings"
 )
 
 // Verify if reader is *os.File
@@ -44,6 +46,14 @@ func isReadAt(reader io.Reader) (ok bool) {
 	return
 }
 
+// partComplete - reports a part acknowledged by the server, uploaded
+// now or by a previous session of a resumed upload.
+func (c Client) partComplete(objPart objectPart) {
+	if c.onPartComplete != nil {
+		c.onPartComplete(objPart.PartNumber, objPart.ETag, objPart.Size)
+	}
+}
+
 // shouldUploadPart - verify if part should be uploaded.
 func shouldUploadPart(objPart objectPart, objectParts map[int]objectPart) bool {
 	// If part not found should upload the part.
@@ -62,6 +72,26 @@ func shouldUploadPart(objPart objectPart, objectParts map[int]objectPart) bool {
 	return false
 }
 
+// verifyMultipartETag - verifies etag of a completed multipart upload
+// is the MD5 sum of the concatenated MD5 sums of parts, followed by
+// the number of parts.
+func verifyMultipartETag(bucketName, objectName, etag string, parts []completePart, md5Sums map[int][]byte) error {
+	hashMD5 := md5.New()
+	for _, part := range parts {
+		md5Sum, ok := md5Sums[part.PartNumber]
+		if !ok {
+			return ErrMultipartETagMismatch("", etag, bucketName, objectName)
+		}
+		hashMD5.Write(md5Sum)
+	}
+	expectedETag := fmt.Sprintf("%s-%d", hex.EncodeToString(hashMD5.Sum(nil)), len(parts))
+	etag = strings.TrimSuffix(strings.TrimPrefix(etag, "\""), "\"")
+	if etag != expectedETag {
+		return ErrMultipartETagMismatch(expectedETag, etag, bucketName, objectName)
+	}
+	return nil
+}
+
 // optimalPartInfo - calculate the optimal part info for a given
 // object size.
 //
@@ -84,8 +114,12 @@ func optimalPartInfo(objectSize int64) (totalPartsCount int, partSize int64, las
 	}
 	// Use floats for part size for all calculations to avoid
 	// overflows during float64 to int64 conversions.
-	partSizeFlt := math.Ceil(float64(objectSize / maxPartsCount))
+	partSizeFlt := math.Ceil(float64(objectSize) / maxPartsCount)
 	partSizeFlt = math.Ceil(partSizeFlt/minPartSize) * minPartSize
+	// Empty objects are uploaded as a single empty part.
+	if partSizeFlt == 0 {
+		return 1, minPartSize, 0, nil
+	}
 	// Total parts count.
 	totalPartsCount = int(math.Ceil(float64(objectSize) / partSizeFlt))
 	// Part size.
@@ -95,6 +129,36 @@ func optimalPartInfo(objectSize int64) (totalPartsCount int, partSize int64, las
 	return totalPartsCount, partSize, lastPartSize, nil
 }
 
+// partInfo - calculate the part info for a given object size, uses
+// the part size set with SetPartSize if any and the optimal part size
+// otherwise.
+func (c Client) partInfo(objectSize int64) (totalPartsCount int, partSize int64, lastPartSize int64, err error) {
+	if c.partSize == 0 {
+		return optimalPartInfo(objectSize)
+	}
+	partSize = c.partSize
+	// object size is '-1', upload up to the maximum parts count.
+	if objectSize == -1 {
+		return maxPartsCount, partSize, partSize, nil
+	}
+	// object size is larger than supported maximum.
+	if objectSize > maxMultipartPutObjectSize {
+		err = ErrEntityTooLarge(objectSize, maxMultipartPutObjectSize, "", "")
+		return
+	}
+	// Empty objects are uploaded as a single empty part.
+	if objectSize == 0 {
+		return 1, partSize, 0, nil
+	}
+	totalPartsCount = int((objectSize + partSize - 1) / partSize)
+	if totalPartsCount > maxPartsCount {
+		err = ErrInvalidArgument(fmt.Sprintf("Part size %d is too small for object size %d, more than %d parts are needed.", partSize, objectSize, maxPartsCount))
+		return
+	}
+	lastPartSize = objectSize - int64(totalPartsCount-1)*partSize
+	return totalPartsCount, partSize, lastPartSize, nil
+}
+
 // hashCopyBuffer is identical to hashCopyN except that it doesn't take
 // any size argument but takes a buffer argument and reader should be
 // of io.ReaderAt interface.
@@ -107,7 +171,7 @@ func (c Client) hashCopyBuffer(writer io.Writer, reader io.ReaderAt, buf []byte)
 	// MD5 and SHA256 hasher.
 	hashMD5 = md5.New()
 	hashWriter := io.MultiWriter(writer, hashMD5)
-	if c.signature.isV4() {
+	if c.isPayloadSigned() {
 		hashSHA256 = sha256.New()
 		hashWriter = io.MultiWriter(writer, hashMD5, hashSHA256)
 	}
@@ -145,7 +209,7 @@ func (c Client) hashCopyBuffer(writer io.Writer, reader io.ReaderAt, buf []byte)
 
 	// Finalize md5 sum and sha256 sum.
 	md5Sum = hashMD5.Sum(nil)
-	if c.signature.isV4() {
+	if c.isPayloadSigned() {
 		sha256Sum = hashSHA256.Sum(nil)
 	}
 	return md5Sum, sha256Sum, size, err
@@ -159,7 +223,7 @@ func (c Client) hashCopy(writer io.Writer, reader io.Reader) (md5Sum, sha256Sum
 	// MD5 and SHA256 hasher.
 	hashMD5 = md5.New()
 	hashWriter := io.MultiWriter(writer, hashMD5)
-	if c.signature.isV4() {
+	if c.isPayloadSigned() {
 		hashSHA256 = sha256.New()
 		hashWriter = io.MultiWriter(writer, hashMD5, hashSHA256)
 	}
@@ -173,7 +237,7 @@ func (c Client) hashCopy(writer io.Writer, reader io.Reader) (md5Sum, sha256Sum
 
 	// Finalize md5 sum and sha256 sum.
 	md5Sum = hashMD5.Sum(nil)
-	if c.signature.isV4() {
+	if c.isPayloadSigned() {
 		sha256Sum = hashSHA256.Sum(nil)
 	}
 	return md5Sum, sha256Sum, size, err
@@ -186,7 +250,7 @@ func (c Client) hashCopyN(writer io.Writer, reader io.Reader, partSize int64) (m
 	// MD5 and SHA256 hasher.
 	hashMD5 = md5.New()
 	hashWriter := io.MultiWriter(writer, hashMD5)
-	if c.signature.isV4() {
+	if c.isPayloadSigned() {
 		hashSHA256 = sha256.New()
 		hashWriter = io.MultiWriter(writer, hashMD5, hashSHA256)
 	}
@@ -202,7 +266,7 @@ func (c Client) hashCopyN(writer io.Writer, reader io.Reader, partSize int64) (m
 
 	// Finalize md5shum and sha256 sum.
 	md5Sum = hashMD5.Sum(nil)
-	if c.signature.isV4() {
+	if c.isPayloadSigned() {
 		sha256Sum = hashSHA256.Sum(nil)
 	}
 	return md5Sum, sha256Sum, size, err
@@ -243,6 +307,42 @@ func (c Client) getUploadID(bucketName, objectName, contentType string) (uploadI
 	return uploadID, isNew, nil
 }
 
+// abortFailedUpload - aborts the multipart upload uploadID after the
+// upload failed with uploadErr, unless uploads are resumable. The
+// abort is best effort, uploadErr is reported to the caller either
+// way.
+func (c Client) abortFailedUpload(bucketName, objectName, uploadID string, uploadErr error) {
+	if c.isUploadResumable {
+		return
+	}
+	// Abort even if the upload was cancelled by its context.
+	abortErr := c.withContext(nil).abortMultipartUpload(bucketName, objectName, uploadID)
+	if c.isTraceEnabled {
+		if abortErr != nil {
+			fmt.Fprintf(c.traceOutput, "Aborting multipart upload %s of %s/%s failed with %v, after upload failed with %v\n",
+				uploadID, bucketName, objectName, abortErr, uploadErr)
+		} else {
+			fmt.Fprintf(c.traceOutput, "Aborted multipart upload %s of %s/%s, upload failed with %v\n",
+				uploadID, bucketName, objectName, uploadErr)
+		}
+	}
+}
+
+// isPayloadSigned - verify if the sha256 sum of payloads is signed,
+// only signature version '4' does. Other clients skip computing it.
+func (c Client) isPayloadSigned() bool {
+	return !c.anonymous && c.signature.isV4()
+}
+
+// payloadSHA256 - returns the sha256 sum of an in-memory payload, nil
+// unless it is signed.
+func (c Client) payloadSHA256(payload []byte) []byte {
+	if !c.isPayloadSigned() {
+		return nil
+	}
+	return sum256(payload)
+}
+
 // computeHash - Calculates MD5 and SHA256 for an input read Seeker.
 func (c Client) computeHash(reader io.ReadSeeker) (md5Sum, sha256Sum []byte, size int64, err error) {
 	// MD5 and SHA256 hasher.
@@ -250,7 +350,7 @@ func (c Client) computeHash(reader io.ReadSeeker) (md5Sum, sha256Sum []byte, siz
 	// MD5 and SHA256 hasher.
 	hashMD5 = md5.New()
 	hashWriter := io.MultiWriter(hashMD5)
-	if c.signature.isV4() {
+	if c.isPayloadSigned() {
 		hashSHA256 = sha256.New()
 		hashWriter = io.MultiWriter(hashMD5, hashSHA256)
 	}
@@ -268,8 +368,27 @@ func (c Client) computeHash(reader io.ReadSeeker) (md5Sum, sha256Sum []byte, siz
 
 	// Finalize md5shum and sha256 sum.
 	md5Sum = hashMD5.Sum(nil)
-	if c.signature.isV4() {
+	if c.isPayloadSigned() {
 		sha256Sum = hashSHA256.Sum(nil)
 	}
 	return md5Sum, sha256Sum, size, nil
 }
+
+// computeMD5 - Calculates MD5 for the next size bytes of an input read
+// Seeker, the reader is seeked back to its current offset afterwards.
+func computeMD5(reader io.ReadSeeker, size int64) (md5Sum []byte, err error) {
+	// Save the current offset to seek back to.
+	offset, err := reader.Seek(0, 1)
+	if err != nil {
+		return nil, err
+	}
+	hashMD5 := md5.New()
+	if _, err = io.CopyN(hashMD5, reader, size); err != nil {
+		return nil, err
+	}
+	// Seek back reader to the original offset.
+	if _, err = reader.Seek(offset, 0); err != nil {
+		return nil, err
+	}
+	return hashMD5.Sum(nil), nil
+}
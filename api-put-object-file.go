@@ -17,15 +17,24 @@
 package minio
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
+	"net/http"
 	"os"
+	"path/filepath"
 	"sort"
 )
 
 // FPutObject - Create an object in a bucket, with contents from file at filePath.
+//
+// If contentType is empty it is detected from the file extension, if the
+// extension is unknown it is detected from the first 512 bytes of the
+// file. Explicit contentType takes precedence over the file extension,
+// which takes precedence over the file contents.
 func (c Client) FPutObject(bucketName, objectName, filePath, contentType string) (n int64, err error) {
 	// Input validation.
 	if err := isValidBucketName(bucketName); err != nil {
@@ -52,6 +61,14 @@ func (c Client) FPutObject(bucketName, objectName, filePath, contentType string)
 	// Save the file size.
 	fileSize := fileStat.Size()
 
+	// Detect content type if not set.
+	if contentType == "" {
+		contentType, err = detectContentType(fileReader, filePath)
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	// Check for largest object size allowed.
 	if fileSize > int64(maxMultipartPutObjectSize) {
 		return 0, ErrEntityTooLarge(fileSize, maxMultipartPutObjectSize, bucketName, objectName)
@@ -59,7 +76,7 @@ func (c Client) FPutObject(bucketName, objectName, filePath, contentType string)
 
 	// NOTE: Google Cloud Storage multipart Put is not compatible with Amazon S3 APIs.
 	// Current implementation will only upload a maximum of 5GiB to Google Cloud Storage servers.
-	if isGoogleEndpoint(c.endpointURL) {
+	if c.isGoogleCompatible() {
 		if fileSize > int64(maxSinglePutObjectSize) {
 			return 0, ErrorResponse{
 				Code:       "NotImplemented",
@@ -87,9 +104,17 @@ func (c Client) FPutObject(bucketName, objectName, filePath, contentType string)
 		return c.putObjectNoChecksum(bucketName, objectName, fileReader, fileSize, contentType, nil)
 	}
 
-	// Small object upload is initiated for uploads for input data size smaller than 5MiB.
-	if fileSize < minPartSize && fileSize >= 0 {
-		return c.putObjectSingle(bucketName, objectName, fileReader, fileSize, contentType, nil)
+	// Multipart is disabled, upload as single PUT of up to 5GiB.
+	if c.isMultipartDisabled {
+		if fileSize > maxSinglePutObjectSize {
+			return 0, ErrEntityTooLarge(fileSize, maxSinglePutObjectSize, bucketName, objectName)
+		}
+		return c.putObjectSingle(bucketName, objectName, fileReader, fileSize, contentType, nil, nil)
+	}
+
+	// Small object upload is initiated for uploads for input data size smaller than multipart threshold.
+	if fileSize < c.multipartThreshold && fileSize >= 0 {
+		return c.putObjectSingle(bucketName, objectName, fileReader, fileSize, contentType, nil, nil)
 	}
 	// Upload all large objects as multipart.
 	n, err = c.putObjectMultipartFromFile(bucketName, objectName, fileReader, fileSize, contentType, nil)
@@ -103,13 +128,41 @@ func (c Client) FPutObject(bucketName, objectName, filePath, contentType string)
 				return 0, ErrEntityTooLarge(fileSize, maxSinglePutObjectSize, bucketName, objectName)
 			}
 			// Fall back to uploading as single PutObject operation.
-			return c.putObjectSingle(bucketName, objectName, fileReader, fileSize, contentType, nil)
+			return c.putObjectSingle(bucketName, objectName, fileReader, fileSize, contentType, nil, nil)
 		}
 		return n, err
 	}
 	return n, nil
 }
 
+// FPutObjectWithContext - Create an object in a bucket, with contents
+// from file at filePath like FPutObject. The upload is cancelled once
+// ctx is done, including the part being uploaded, and the error of ctx
+// is returned. Cancelled multipart uploads are aborted unless uploads
+// are resumable.
+func (c Client) FPutObjectWithContext(ctx context.Context, bucketName, objectName, filePath, contentType string) (n int64, err error) {
+	if ctx == nil {
+		return 0, ErrInvalidArgument("Context cannot be nil.")
+	}
+	return c.withContext(ctx).FPutObject(bucketName, objectName, filePath, contentType)
+}
+
+// detectContentType - detects content type from file extension, falls
+// back to sniffing the first 512 bytes of the file. File offset is left
+// untouched.
+func detectContentType(fileReader *os.File, filePath string) (string, error) {
+	if contentType := mime.TypeByExtension(filepath.Ext(filePath)); contentType != "" {
+		return contentType, nil
+	}
+	// Only the first 512 bytes are considered by DetectContentType.
+	buf := make([]byte, 512)
+	n, err := fileReader.ReadAt(buf, 0)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
+
 // putObjectMultipartFromFile - Creates object from contents of *os.File
 //
 // NOTE: This function is meant to be used for readers with local
@@ -118,7 +171,7 @@ func (c Client) FPutObject(bucketName, objectName, filePath, contentType string)
 // against MD5SUM of each individual parts. This function also
 // effectively utilizes file system capabilities of reading from
 // specific sections and not having to create temporary files.
-func (c Client) putObjectMultipartFromFile(bucketName, objectName string, fileReader io.ReaderAt, fileSize int64, contentType string, progress io.Reader) (int64, error) {
+func (c Client) putObjectMultipartFromFile(bucketName, objectName string, fileReader io.ReaderAt, fileSize int64, contentType string, progress io.Reader) (n int64, err error) {
 	// Input validation.
 	if err := isValidBucketName(bucketName); err != nil {
 		return 0, err
@@ -134,6 +187,13 @@ func (c Client) putObjectMultipartFromFile(bucketName, objectName string, fileRe
 		return 0, err
 	}
 
+	// Abort the upload on failure, its parts would be left behind.
+	defer func() {
+		if err != nil {
+			c.abortFailedUpload(bucketName, objectName, uploadID, err)
+		}
+	}()
+
 	// Total data read and written to server. should be equal to 'size' at the end of the call.
 	var totalUploadedSize int64
 
@@ -154,7 +214,7 @@ func (c Client) putObjectMultipartFromFile(bucketName, objectName string, fileRe
 	}
 
 	// Calculate the optimal parts info for a given size.
-	totalPartsCount, partSize, _, err := optimalPartInfo(fileSize)
+	totalPartsCount, partSize, _, err := c.partInfo(fileSize)
 	if err != nil {
 		return 0, err
 	}
@@ -162,6 +222,9 @@ func (c Client) putObjectMultipartFromFile(bucketName, objectName string, fileRe
 	// Part number always starts with '1'.
 	partNumber := 1
 
+	// MD5 sums of all the parts, to verify the final ETag.
+	md5Sums := make(map[int][]byte)
+
 	for partNumber <= totalPartsCount {
 		// Get a section reader on a particular offset.
 		sectionReader := io.NewSectionReader(fileReader, totalUploadedSize, partSize)
@@ -173,6 +236,7 @@ func (c Client) putObjectMultipartFromFile(bucketName, objectName string, fileRe
 		if err != nil {
 			return 0, err
 		}
+		md5Sums[partNumber] = md5Sum
 
 		var reader io.Reader
 		// Update progress reader appropriately to the latest offset
@@ -195,6 +259,7 @@ func (c Client) putObjectMultipartFromFile(bucketName, objectName string, fileRe
 			// Save successfully uploaded part metadata.
 			partsInfo[partNumber] = objPart
 		} else {
+			c.partComplete(partsInfo[partNumber])
 			// Update the progress reader for the skipped part.
 			if progress != nil {
 				if _, err = io.CopyN(ioutil.Discard, progress, prtSize); err != nil {
@@ -230,10 +295,15 @@ func (c Client) putObjectMultipartFromFile(bucketName, objectName string, fileRe
 
 	// Sort all completed parts.
 	sort.Sort(completedParts(completeMultipartUpload.Parts))
-	_, err = c.completeMultipartUpload(bucketName, objectName, uploadID, completeMultipartUpload)
+	complResult, err := c.completeMultipartUpload(bucketName, objectName, uploadID, completeMultipartUpload)
 	if err != nil {
 		return totalUploadedSize, err
 	}
+	if c.isMultipartETagVerified {
+		if err = verifyMultipartETag(bucketName, objectName, complResult.ETag, completeMultipartUpload.Parts, md5Sums); err != nil {
+			return totalUploadedSize, err
+		}
+	}
 
 	// Return final size.
 	return totalUploadedSize, nil
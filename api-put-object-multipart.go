@@ -89,6 +89,13 @@ func (c Client) putObjectMultipartStream(bucketName, objectName string, reader i
 		return 0, err
 	}
 
+	// Abort the upload on failure, its parts would be left behind.
+	defer func() {
+		if err != nil {
+			c.abortFailedUpload(bucketName, objectName, uploadID, err)
+		}
+	}()
+
 	// If This session is a continuation of a previous session fetch all
 	// previously uploaded parts info.
 	if !isNew {
@@ -100,7 +107,7 @@ func (c Client) putObjectMultipartStream(bucketName, objectName string, reader i
 	}
 
 	// Calculate the optimal parts info for a given size.
-	totalPartsCount, partSize, _, err := optimalPartInfo(size)
+	totalPartsCount, partSize, _, err := c.partInfo(size)
 	if err != nil {
 		return 0, err
 	}
@@ -108,8 +115,12 @@ func (c Client) putObjectMultipartStream(bucketName, objectName string, reader i
 	// Part number always starts with '1'.
 	partNumber := 1
 
-	// Initialize a temporary buffer.
-	tmpBuffer := new(bytes.Buffer)
+	// MD5 sums of all the parts, to verify the final ETag.
+	md5Sums := make(map[int][]byte)
+
+	// Get a temporary buffer from the pool.
+	tmpBuffer := c.bufferPool.Get()
+	defer c.bufferPool.Put(tmpBuffer)
 
 	for partNumber <= totalPartsCount {
 		// Calculates MD5 and SHA256 sum while copying partSize bytes
@@ -121,6 +132,14 @@ func (c Client) putObjectMultipartStream(bucketName, objectName string, reader i
 			}
 		}
 
+		// Stream of unknown size ended at a part boundary, do not
+		// upload an empty last part.
+		if size < 0 && prtSize == 0 && partNumber > 1 {
+			break
+		}
+
+		md5Sums[partNumber] = md5Sum
+
 		var reader io.Reader
 		// Update progress reader appropriately to the latest offset
 		// as we read from the source.
@@ -143,6 +162,7 @@ func (c Client) putObjectMultipartStream(bucketName, objectName string, reader i
 			// Save successfully uploaded part metadata.
 			partsInfo[partNumber] = objPart
 		} else {
+			c.partComplete(partsInfo[partNumber])
 			// Update the progress reader for the skipped part.
 			if progress != nil {
 				if _, err = io.CopyN(ioutil.Discard, progress, prtSize); err != nil {
@@ -191,10 +211,15 @@ func (c Client) putObjectMultipartStream(bucketName, objectName string, reader i
 
 	// Sort all completed parts.
 	sort.Sort(completedParts(complMultipartUpload.Parts))
-	_, err = c.completeMultipartUpload(bucketName, objectName, uploadID, complMultipartUpload)
+	complResult, err := c.completeMultipartUpload(bucketName, objectName, uploadID, complMultipartUpload)
 	if err != nil {
 		return totalUploadedSize, err
 	}
+	if c.isMultipartETagVerified {
+		if err = verifyMultipartETag(bucketName, objectName, complResult.ETag, complMultipartUpload.Parts, md5Sums); err != nil {
+			return totalUploadedSize, err
+		}
+	}
 
 	// Return final size.
 	return totalUploadedSize, nil
@@ -202,6 +227,15 @@ func (c Client) putObjectMultipartStream(bucketName, objectName string, reader i
 
 // initiateMultipartUpload - Initiates a multipart upload and returns an upload ID.
 func (c Client) initiateMultipartUpload(bucketName, objectName, contentType string) (initiateMultipartUploadResult, error) {
+	// Set ContentType header.
+	customHeader := make(http.Header)
+	customHeader.Set("Content-Type", contentType)
+	return c.initiateMultipartUploadWithHeader(bucketName, objectName, customHeader)
+}
+
+// initiateMultipartUploadWithHeader - Initiates a multipart upload
+// with the object metadata set in customHeader.
+func (c Client) initiateMultipartUploadWithHeader(bucketName, objectName string, customHeader http.Header) (initiateMultipartUploadResult, error) {
 	// Input validation.
 	if err := isValidBucketName(bucketName); err != nil {
 		return initiateMultipartUploadResult{}, err
@@ -214,13 +248,14 @@ func (c Client) initiateMultipartUpload(bucketName, objectName, contentType stri
 	urlValues := make(url.Values)
 	urlValues.Set("uploads", "")
 
-	if contentType == "" {
-		contentType = "application/octet-stream"
+	if customHeader.Get("Content-Type") == "" {
+		customHeader.Set("Content-Type", "application/octet-stream")
+	}
+	for k, v := range c.uploadHeader {
+		if _, ok := customHeader[k]; !ok {
+			customHeader[k] = v
+		}
 	}
-
-	// Set ContentType header.
-	customHeader := make(http.Header)
-	customHeader.Set("Content-Type", contentType)
 
 	reqMetadata := requestMetadata{
 		bucketName:   bucketName,
@@ -249,7 +284,8 @@ func (c Client) initiateMultipartUpload(bucketName, objectName, contentType stri
 	return initiateMultipartUploadResult, nil
 }
 
-// uploadPart - Uploads a part in a multipart upload.
+// uploadPart - Uploads a part in a multipart upload, md5Sum is sent as
+// Content-MD5 of the part.
 func (c Client) uploadPart(bucketName, objectName, uploadID string, reader io.Reader, partNumber int, md5Sum, sha256Sum []byte, size int64) (objectPart, error) {
 	// Input validation.
 	if err := isValidBucketName(bucketName); err != nil {
@@ -306,6 +342,7 @@ func (c Client) uploadPart(bucketName, objectName, uploadID string, reader io.Re
 	// Trim off the odd double quotes from ETag in the beginning and end.
 	objPart.ETag = strings.TrimPrefix(resp.Header.Get("ETag"), "\"")
 	objPart.ETag = strings.TrimSuffix(objPart.ETag, "\"")
+	c.partComplete(objPart)
 	return objPart, nil
 }
 
@@ -337,7 +374,7 @@ func (c Client) completeMultipartUpload(bucketName, objectName, uploadID string,
 		queryValues:        urlValues,
 		contentBody:        completeMultipartUploadBuffer,
 		contentLength:      int64(len(completeMultipartUploadBytes)),
-		contentSHA256Bytes: sum256(completeMultipartUploadBytes),
+		contentSHA256Bytes: c.payloadSHA256(completeMultipartUploadBytes),
 	}
 
 	// Execute POST to complete multipart upload for an objectName.
@@ -357,5 +394,14 @@ func (c Client) completeMultipartUpload(bucketName, objectName, uploadID string,
 	if err != nil {
 		return completeMultipartUploadResult, err
 	}
+	completeMultipartUploadResult.VersionID = resp.Header.Get("x-amz-version-id")
+	completeMultipartUploadResult.Expiration = resp.Header.Get("x-amz-expiration")
+
+	// Save the metadata of the created object if requested.
+	if c.uploadInfo != nil {
+		c.uploadInfo.ETag = strings.Trim(completeMultipartUploadResult.ETag, "\"")
+		c.uploadInfo.VersionID = completeMultipartUploadResult.VersionID
+		c.uploadInfo.Expiration = completeMultipartUploadResult.Expiration
+	}
 	return completeMultipartUploadResult, nil
 }
@@ -16,7 +16,10 @@
 
 package minio
 
-import "io"
+import (
+	"bytes"
+	"io"
+)
 
 // PutObjectWithProgress - With progress.
 func (c Client) PutObjectWithProgress(bucketName, objectName string, reader io.Reader, contentType string, progress io.Reader) (n int64, err error) {
@@ -40,6 +43,19 @@ func (c Client) PutObjectWithProgress(bucketName, objectName string, reader io.R
 		return 0, err
 	}
 
+	// Streams of unknown size which turn out to be empty are uploaded
+	// as empty objects with a single PUT.
+	if size < 0 {
+		var isEmpty bool
+		reader, isEmpty, err = peekEmpty(reader)
+		if err != nil {
+			return 0, err
+		}
+		if isEmpty {
+			size = 0
+		}
+	}
+
 	// Check for largest object size allowed.
 	if size > int64(maxMultipartPutObjectSize) {
 		return 0, ErrEntityTooLarge(size, maxMultipartPutObjectSize, bucketName, objectName)
@@ -47,7 +63,7 @@ func (c Client) PutObjectWithProgress(bucketName, objectName string, reader io.R
 
 	// NOTE: Google Cloud Storage does not implement Amazon S3 Compatible multipart PUT.
 	// So we fall back to single PUT operation with the maximum limit of 5GiB.
-	if isGoogleEndpoint(c.endpointURL) {
+	if c.isGoogleCompatible() {
 		if size <= -1 {
 			return 0, ErrorResponse{
 				Code:       "NotImplemented",
@@ -81,11 +97,19 @@ func (c Client) PutObjectWithProgress(bucketName, objectName string, reader io.R
 		return c.putObjectNoChecksum(bucketName, objectName, reader, size, contentType, progress)
 	}
 
+	// Multipart is disabled, upload as single PUT of up to 5GiB.
+	if c.isMultipartDisabled {
+		if size > maxSinglePutObjectSize {
+			return 0, ErrEntityTooLarge(size, maxSinglePutObjectSize, bucketName, objectName)
+		}
+		return c.putObjectSingle(bucketName, objectName, reader, size, contentType, nil, progress)
+	}
+
 	// putSmall object.
-	if size < minPartSize && size >= 0 {
-		return c.putObjectSingle(bucketName, objectName, reader, size, contentType, progress)
+	if size < c.multipartThreshold && size >= 0 {
+		return c.putObjectSingle(bucketName, objectName, reader, size, contentType, nil, progress)
 	}
-	// For all sizes greater than 5MiB do multipart.
+	// For all sizes greater than multipart threshold do multipart.
 	n, err = c.putObjectMultipart(bucketName, objectName, reader, size, contentType, progress)
 	if err != nil {
 		errResp := ToErrorResponse(err)
@@ -97,9 +121,23 @@ func (c Client) PutObjectWithProgress(bucketName, objectName string, reader io.R
 				return 0, ErrEntityTooLarge(size, maxSinglePutObjectSize, bucketName, objectName)
 			}
 			// Fall back to uploading as single PutObject operation.
-			return c.putObjectSingle(bucketName, objectName, reader, size, contentType, progress)
+			return c.putObjectSingle(bucketName, objectName, reader, size, contentType, nil, progress)
 		}
 		return n, err
 	}
 	return n, nil
 }
+
+// peekEmpty - reads the first byte of reader to verify if it is empty,
+// the returned reader yields all the data of reader.
+func peekEmpty(reader io.Reader) (io.Reader, bool, error) {
+	buf := make([]byte, 1)
+	n, err := io.ReadFull(reader, buf)
+	if err == io.EOF {
+		return bytes.NewReader(nil), true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return io.MultiReader(bytes.NewReader(buf[:n]), reader), false, nil
+}
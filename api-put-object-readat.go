@@ -63,6 +63,13 @@ func (c Client) putObjectMultipartFromReadAt(bucketName, objectName string, read
 		return 0, err
 	}
 
+	// Abort the upload on failure, its parts would be left behind.
+	defer func() {
+		if err != nil {
+			c.abortFailedUpload(bucketName, objectName, uploadID, err)
+		}
+	}()
+
 	// Total data read and written to server. should be equal to 'size' at the end of the call.
 	var totalUploadedSize int64
 
@@ -81,7 +88,7 @@ func (c Client) putObjectMultipartFromReadAt(bucketName, objectName string, read
 	}
 
 	// Calculate the optimal parts info for a given size.
-	totalPartsCount, partSize, lastPartSize, err := optimalPartInfo(size)
+	totalPartsCount, partSize, lastPartSize, err := c.partInfo(size)
 	if err != nil {
 		return 0, err
 	}
@@ -93,11 +100,16 @@ func (c Client) putObjectMultipartFromReadAt(bucketName, objectName string, read
 	// partNumber always starts with '1'.
 	partNumber := 1
 
-	// Initialize a temporary buffer.
-	tmpBuffer := new(bytes.Buffer)
+	// MD5 sums of all the parts, to verify the final ETag.
+	md5Sums := make(map[int][]byte)
+
+	// Get a temporary buffer from the pool.
+	tmpBuffer := c.bufferPool.Get()
+	defer c.bufferPool.Put(tmpBuffer)
 
 	// Read defaults to reading at 5MiB buffer.
-	readAtBuffer := make([]byte, optimalReadBufferSize)
+	readAtBuffer := c.bufferPool.GetReadBuffer()
+	defer c.bufferPool.PutReadBuffer(readAtBuffer)
 
 	// Upload all the missing parts.
 	for partNumber <= lastPartNumber {
@@ -117,6 +129,21 @@ func (c Client) putObjectMultipartFromReadAt(bucketName, objectName string, read
 
 		// Verify if part should be uploaded.
 		if !shouldUploadPartReadAt(verifyObjPart, partsInfo) {
+			// Skipped parts are only verified by size, hash them
+			// to verify the final ETag.
+			if c.isMultipartETagVerified {
+				readOffset := int64(partNumber-1) * partSize
+				if partNumber == lastPartNumber {
+					readOffset = size - lastPartSize
+				}
+				var md5Sum []byte
+				md5Sum, _, _, err = c.computeHash(io.NewSectionReader(reader, readOffset, verifyObjPart.Size))
+				if err != nil {
+					return 0, err
+				}
+				md5Sums[partNumber] = md5Sum
+			}
+			c.partComplete(partsInfo[partNumber])
 			// Increment part number when not uploaded.
 			partNumber++
 			if progress != nil {
@@ -151,6 +178,7 @@ func (c Client) putObjectMultipartFromReadAt(bucketName, objectName string, read
 		if err != nil {
 			return 0, err
 		}
+		md5Sums[partNumber] = md5Sum
 
 		var reader io.Reader
 		// Update progress reader appropriately to the latest offset
@@ -197,10 +225,15 @@ func (c Client) putObjectMultipartFromReadAt(bucketName, objectName string, read
 
 	// Sort all completed parts.
 	sort.Sort(completedParts(complMultipartUpload.Parts))
-	_, err = c.completeMultipartUpload(bucketName, objectName, uploadID, complMultipartUpload)
+	complResult, err := c.completeMultipartUpload(bucketName, objectName, uploadID, complMultipartUpload)
 	if err != nil {
 		return totalUploadedSize, err
 	}
+	if c.isMultipartETagVerified {
+		if err = verifyMultipartETag(bucketName, objectName, complResult.ETag, complMultipartUpload.Parts, md5Sums); err != nil {
+			return totalUploadedSize, err
+		}
+	}
 
 	// Return final size.
 	return totalUploadedSize, nil
@@ -18,13 +18,16 @@ package minio
 
 import (
 	"bytes"
+	"encoding/xml"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"reflect"
 	"runtime"
 	"strings"
+	"time"
 )
 
 // getReaderSize - Determine the size of Reader if available.
@@ -119,9 +122,15 @@ func (a completedParts) Less(i, j int) bool { return a[i].PartNumber < a[j].Part
 //
 //  - For size smaller than 5MiB PutObject automatically does a single atomic Put operation.
 //  - For size larger than 5MiB PutObject automatically does a resumable multipart Put operation.
+//    The 5MiB threshold can be changed with SetMultipartThreshold.
 //  - For size input as -1 PutObject does a multipart Put operation until input stream reaches EOF.
 //    Maximum object size that can be uploaded through this operation will be 5TiB.
 //
+// Every single PUT and every multipart part is sent with Content-MD5, except
+// for non-seekable streams uploaded to Google Cloud Storage or anonymously to
+// Amazon S3. Data corrupted in transit is rejected by the server and reported
+// as an ErrorResponse with Code 'BadDigest'.
+//
 // NOTE: Google Cloud Storage does not implement Amazon S3 Compatible multipart PUT.
 // So we fall back to single PUT operation with the maximum limit of 5GiB.
 //
@@ -130,8 +139,146 @@ func (c Client) PutObject(bucketName, objectName string, reader io.Reader, conte
 	return c.PutObjectWithProgress(bucketName, objectName, reader, contentType, nil)
 }
 
+// PutObjectWithInfo - same as PutObject, but returns the metadata of
+// the created object sent by the server: Key, Size, ETag and, if set,
+// VersionID of versioned buckets and Expiration by bucket lifecycle
+// rules.
+func (c Client) PutObjectWithInfo(bucketName, objectName string, reader io.Reader, contentType string) (ObjectInfo, error) {
+	var objInfo ObjectInfo
+	n, err := c.withUploadInfo(&objInfo).PutObjectWithProgress(bucketName, objectName, reader, contentType, nil)
+	if err != nil {
+		return ObjectInfo{Key: objectName, Size: n}, err
+	}
+	objInfo.Key = objectName
+	objInfo.Size = n
+	return objInfo, nil
+}
+
+// PutObjectWithExpires - same as PutObjectWithInfo, but sets the
+// 'Expires' header of the object to expires. The header is returned on
+// downloads of the object and tells caches when it becomes stale.
+//
+// Objects are not deleted by the header, S3 deletes objects only by
+// bucket lifecycle rules. Expiration of the returned metadata tells
+// when a lifecycle rule deletes the uploaded object, if any.
+func (c Client) PutObjectWithExpires(bucketName, objectName string, reader io.Reader, contentType string, expires time.Time) (ObjectInfo, error) {
+	if expires.IsZero() {
+		return ObjectInfo{}, ErrInvalidArgument("Expires time cannot be zero.")
+	}
+	header := make(http.Header)
+	header.Set("Expires", expires.UTC().Format(http.TimeFormat))
+	return c.withUploadHeader(header).PutObjectWithInfo(bucketName, objectName, reader, contentType)
+}
+
+// withUploadHeader - returns a copy of the client sending header with
+// the requests creating objects, the client itself is unchanged.
+func (c Client) withUploadHeader(header http.Header) Client {
+	c.uploadHeader = header
+	return c
+}
+
+// withUploadInfo - returns a copy of the client saving the metadata of
+// the object created by an upload to objInfo, the client itself is
+// unchanged.
+func (c Client) withUploadInfo(objInfo *ObjectInfo) Client {
+	c.uploadInfo = objInfo
+	return c
+}
+
+// PutObjectWithRegion - same as PutObject, but the request is signed
+// for the given region instead of the location of the bucket looked
+// up and cached by the client. Use it to avoid the region mismatch of
+// 'AuthorizationHeaderMalformed' errors on buckets in a different
+// region, the client state is not modified.
+func (c Client) PutObjectWithRegion(bucketName, objectName string, reader io.Reader, contentType, region string) (n int64, err error) {
+	if region == "" {
+		return 0, ErrInvalidArgument("Region cannot be empty.")
+	}
+	// Sign all the requests of this upload for the region.
+	return c.withBucketLocation(bucketName, region).PutObjectWithProgress(bucketName, objectName, reader, contentType, nil)
+}
+
+// PutObjectSized - same as PutObject, for readers of known size which
+// cannot be seeked, such as uploads proxied from a network source.
+// Exactly size bytes are read from reader.
+//
+// Objects smaller than the multipart threshold are streamed as a
+// single PUT without buffering, so the payload is sent without
+// Content-MD5 and, with signature version '4', unsigned. Larger
+// objects are uploaded with multipart as usual.
+func (c Client) PutObjectSized(bucketName, objectName string, reader io.Reader, size int64, contentType string) (n int64, err error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return 0, err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return 0, err
+	}
+	if reader == nil {
+		return 0, ErrInvalidArgument("Input reader is invalid, cannot be nil.")
+	}
+	if size < 0 {
+		return 0, ErrInvalidArgument("Size cannot be negative, use PutObject for streams of unknown size.")
+	}
+	if size > maxMultipartPutObjectSize {
+		return 0, ErrEntityTooLarge(size, maxMultipartPutObjectSize, bucketName, objectName)
+	}
+
+	// Never read beyond size.
+	reader = io.LimitReader(reader, size)
+
+	// Upload with multipart if available.
+	isMultipartAvailable := !c.isMultipartDisabled && !c.isGoogleCompatible() && !(isAmazonEndpoint(c.endpointURL) && c.anonymous)
+	if size >= c.multipartThreshold && isMultipartAvailable {
+		return c.putObjectMultipart(bucketName, objectName, reader, size, contentType, nil)
+	}
+	return c.putObjectNoChecksum(bucketName, objectName, reader, size, contentType, nil)
+}
+
+// PutObjectIfNotExists - same as PutObject, but creates the object only
+// if no object of the same name exists, atomically on the server. The
+// upload is sent as a single PUT of up to 5GiB with the header
+// 'If-None-Match: *'.
+//
+// If the object exists an ErrorResponse with Code 'PreconditionFailed'
+// is returned and the object is left as is, which makes it suitable
+// for lock files and leader election.
+//
+// NOTE: Servers which do not support conditional writes ignore the
+// header and overwrite existing objects. There the only fallback is
+// checking with StatObject before uploading, which is racy.
+func (c Client) PutObjectIfNotExists(bucketName, objectName string, reader io.Reader, contentType string) (n int64, err error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return 0, err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return 0, err
+	}
+	if reader == nil {
+		return 0, ErrInvalidArgument("Input reader is invalid, cannot be nil.")
+	}
+
+	// Get reader size.
+	size, err := getReaderSize(reader)
+	if err != nil {
+		return 0, err
+	}
+	if size > maxSinglePutObjectSize {
+		return 0, ErrEntityTooLarge(size, maxSinglePutObjectSize, bucketName, objectName)
+	}
+
+	customHeader := make(http.Header)
+	customHeader.Set("If-None-Match", "*")
+	return c.putObjectSingle(bucketName, objectName, reader, size, contentType, customHeader, nil)
+}
+
 // putObjectNoChecksum special function used Google Cloud Storage. This special function
 // is used for Google Cloud Storage since Google's multipart API is not S3 compatible.
+//
+// Payload is not buffered to calculate checksums, but Content-MD5 is
+// still sent for seekable readers so that the server can validate the
+// integrity of the upload.
 func (c Client) putObjectNoChecksum(bucketName, objectName string, reader io.Reader, size int64, contentType string, progress io.Reader) (n int64, err error) {
 	// Input validation.
 	if err := isValidBucketName(bucketName); err != nil {
@@ -144,13 +291,22 @@ func (c Client) putObjectNoChecksum(bucketName, objectName string, reader io.Rea
 		return 0, ErrEntityTooLarge(size, maxSinglePutObjectSize, bucketName, objectName)
 	}
 
+	// Calculate md5sum only if the reader can be seeked back.
+	var md5Sum []byte
+	if readSeeker, ok := reader.(io.ReadSeeker); ok && size >= 0 {
+		md5Sum, err = computeMD5(readSeeker, size)
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	// Update progress reader appropriately to the latest offset as we
 	// read from the source.
 	readSeeker := newHook(reader, progress)
 
-	// This function does not calculate sha256 and md5sum for payload.
+	// This function does not calculate sha256 for payload.
 	// Execute put object.
-	st, err := c.putObjectDo(bucketName, objectName, readSeeker, nil, nil, size, contentType)
+	st, err := c.putObjectDo(bucketName, objectName, readSeeker, md5Sum, nil, size, contentType, nil)
 	if err != nil {
 		return 0, err
 	}
@@ -162,7 +318,10 @@ func (c Client) putObjectNoChecksum(bucketName, objectName string, reader io.Rea
 
 // putObjectSingle is a special function for uploading single put object request.
 // This special function is used as a fallback when multipart upload fails.
-func (c Client) putObjectSingle(bucketName, objectName string, reader io.Reader, size int64, contentType string, progress io.Reader) (n int64, err error) {
+//
+// customHeader is sent with the request in addition to the headers
+// set by putObjectDo, it may be nil.
+func (c Client) putObjectSingle(bucketName, objectName string, reader io.Reader, size int64, contentType string, customHeader http.Header, progress io.Reader) (n int64, err error) {
 	// Input validation.
 	if err := isValidBucketName(bucketName); err != nil {
 		return 0, err
@@ -174,16 +333,34 @@ func (c Client) putObjectSingle(bucketName, objectName string, reader io.Reader,
 		return 0, ErrEntityTooLarge(size, maxSinglePutObjectSize, bucketName, objectName)
 	}
 	// If size is a stream, upload up to 5GiB.
-	if size <= -1 {
+	isStream := size <= -1
+	if isStream {
 		size = maxSinglePutObjectSize
 	}
+	// Payload is staged in memory or in a temporary file, unless it
+	// can be read again from where it is.
+	var payload io.Reader
 	var md5Sum, sha256Sum []byte
-	if size <= minPartSize {
-		// Initialize a new temporary buffer.
-		tmpBuffer := new(bytes.Buffer)
+	readerAt, isReaderAt := reader.(io.ReaderAt)
+	readSeeker, isReadSeeker := reader.(io.ReadSeeker)
+	if isReaderAt && isReadSeeker && !isStream {
+		// Files and other seekable readers are hashed in a first pass
+		// and uploaded from their current offset in a second pass,
+		// memory use does not grow with the size of the payload.
+		var offset int64
+		offset, err = readSeeker.Seek(0, 1)
+		if err != nil {
+			return 0, err
+		}
+		md5Sum, sha256Sum, size, err = c.hashCopyN(ioutil.Discard, readSeeker, size)
+		payload = io.NewSectionReader(readerAt, offset, size)
+	} else if size <= minPartSize {
+		// Get a temporary buffer from the pool, returned once the
+		// payload is uploaded.
+		tmpBuffer := c.bufferPool.Get()
+		defer c.bufferPool.Put(tmpBuffer)
 		md5Sum, sha256Sum, size, err = c.hashCopyN(tmpBuffer, reader, size)
-		reader = bytes.NewReader(tmpBuffer.Bytes())
-		tmpBuffer.Reset()
+		payload = bytes.NewReader(tmpBuffer.Bytes())
 	} else {
 		// Initialize a new temporary file.
 		var tmpFile *tempFile
@@ -192,21 +369,27 @@ func (c Client) putObjectSingle(bucketName, objectName string, reader io.Reader,
 			return 0, err
 		}
 		defer tmpFile.Close()
+		payload = tmpFile
 		md5Sum, sha256Sum, size, err = c.hashCopyN(tmpFile, reader, size)
 		// Seek back to beginning of the temporary file.
-		if _, err = tmpFile.Seek(0, 0); err != nil {
-			return 0, err
+		if _, serr := tmpFile.Seek(0, 0); serr != nil {
+			return 0, serr
 		}
-		reader = tmpFile
 	}
 	// Return error if its not io.EOF.
 	if err != nil {
 		if err != io.EOF {
 			return 0, err
 		}
+	} else if isStream {
+		// Stream did not end within 5GiB, fail instead of uploading
+		// a truncated object.
+		if n, _ := io.ReadFull(reader, make([]byte, 1)); n > 0 {
+			return 0, ErrEntityTooLarge(size+1, maxSinglePutObjectSize, bucketName, objectName)
+		}
 	}
 	// Execute put object.
-	st, err := c.putObjectDo(bucketName, objectName, reader, md5Sum, sha256Sum, size, contentType)
+	st, err := c.putObjectDo(bucketName, objectName, payload, md5Sum, sha256Sum, size, contentType, customHeader)
 	if err != nil {
 		return 0, err
 	}
@@ -224,7 +407,11 @@ func (c Client) putObjectSingle(bucketName, objectName string, reader io.Reader,
 
 // putObjectDo - executes the put object http operation.
 // NOTE: You must have WRITE permissions on a bucket to add an object to it.
-func (c Client) putObjectDo(bucketName, objectName string, reader io.Reader, md5Sum []byte, sha256Sum []byte, size int64, contentType string) (ObjectInfo, error) {
+//
+// If md5Sum is set it is sent as Content-MD5, a payload which does not
+// match it is rejected by the server with a 'BadDigest' error. Headers
+// in header are sent as well, it may be nil.
+func (c Client) putObjectDo(bucketName, objectName string, reader io.Reader, md5Sum []byte, sha256Sum []byte, size int64, contentType string, header http.Header) (ObjectInfo, error) {
 	// Input validation.
 	if err := isValidBucketName(bucketName); err != nil {
 		return ObjectInfo{}, err
@@ -247,6 +434,12 @@ func (c Client) putObjectDo(bucketName, objectName string, reader io.Reader, md5
 
 	// Set headers.
 	customHeader := make(http.Header)
+	for k, v := range c.uploadHeader {
+		customHeader[k] = v
+	}
+	for k, v := range header {
+		customHeader[k] = v
+	}
 	customHeader.Set("Content-Type", contentType)
 
 	// Populate request metadata.
@@ -278,7 +471,307 @@ func (c Client) putObjectDo(bucketName, objectName string, reader io.Reader, md5
 	metadata.ETag = strings.TrimSuffix(metadata.ETag, "\"")
 	// A success here means data was written to server successfully.
 	metadata.Size = size
+	metadata.VersionID = resp.Header.Get("x-amz-version-id")
+	metadata.Expiration = resp.Header.Get("x-amz-expiration")
+
+	// Save the metadata of the created object if requested.
+	if c.uploadInfo != nil {
+		*c.uploadInfo = metadata
+	}
 
 	// Return here.
 	return metadata, nil
 }
+
+// PutObjectTagging replaces the tag set of an existing object.
+//
+// An object can have up to 10 tags, tag keys can be up to 128 unicode
+// characters and tag values can be up to 256 unicode characters.
+func (c Client) PutObjectTagging(bucketName, objectName string, tags map[string]string) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return err
+	}
+	if err := isValidObjectTags(tags); err != nil {
+		return err
+	}
+
+	// Set tagging query.
+	urlValues := make(url.Values)
+	urlValues.Set("tagging", "")
+
+	// Marshal tagging body.
+	taggingBytes, err := xml.Marshal(newTagging(tags))
+	if err != nil {
+		return err
+	}
+
+	reqMetadata := requestMetadata{
+		bucketName:         bucketName,
+		objectName:         objectName,
+		queryValues:        urlValues,
+		contentBody:        bytes.NewReader(taggingBytes),
+		contentLength:      int64(len(taggingBytes)),
+		contentMD5Bytes:    sumMD5(taggingBytes),
+		contentSHA256Bytes: c.payloadSHA256(taggingBytes),
+	}
+
+	// Execute PUT on objectName to set tagging.
+	resp, err := c.executeMethod("PUT", reqMetadata)
+	defer closeResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return httpRespToErrorResponse(resp, bucketName, objectName)
+		}
+	}
+	return nil
+}
+
+// PutObjectRetention sets the object lock retention of an object, an
+// empty versionID sets the retention of the latest version.
+//
+// Object is protected from being removed or overwritten until
+// retainUntilDate. Retention can only be set on buckets created with
+// object lock enabled, see MakeBucketWithObjectLock.
+func (c Client) PutObjectRetention(bucketName, objectName, versionID string, mode RetentionMode, retainUntilDate time.Time) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return err
+	}
+	if !mode.isValidRetentionMode() {
+		return ErrInvalidArgument("Unrecognized retention mode " + string(mode) + ".")
+	}
+	if retainUntilDate.IsZero() {
+		return ErrInvalidArgument("Retain until date cannot be empty.")
+	}
+
+	// Set retention query.
+	urlValues := make(url.Values)
+	urlValues.Set("retention", "")
+	if versionID != "" {
+		urlValues.Set("versionId", versionID)
+	}
+
+	// Marshal retention body.
+	retainUntilDate = retainUntilDate.UTC()
+	retentionBytes, err := xml.Marshal(objectRetention{
+		Mode:            mode,
+		RetainUntilDate: &retainUntilDate,
+	})
+	if err != nil {
+		return err
+	}
+
+	reqMetadata := requestMetadata{
+		bucketName:         bucketName,
+		objectName:         objectName,
+		queryValues:        urlValues,
+		contentBody:        bytes.NewReader(retentionBytes),
+		contentLength:      int64(len(retentionBytes)),
+		contentMD5Bytes:    sumMD5(retentionBytes),
+		contentSHA256Bytes: c.payloadSHA256(retentionBytes),
+	}
+
+	// Execute PUT on objectName to set retention.
+	resp, err := c.executeMethod("PUT", reqMetadata)
+	defer closeResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return c.objectLockError(bucketName, httpRespToAPIErrorResponse(resp, bucketName, objectName, "Object lock"))
+		}
+	}
+	return nil
+}
+
+// PutObjectLegalHold sets the object lock legal hold status of an
+// object, an empty versionID sets the legal hold of the latest version.
+//
+// Object is protected from being removed or overwritten until the legal
+// hold is turned off, independent of its retention.
+func (c Client) PutObjectLegalHold(bucketName, objectName, versionID string, status LegalHoldStatus) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return err
+	}
+	if !status.isValidLegalHoldStatus() {
+		return ErrInvalidArgument("Unrecognized legal hold status " + string(status) + ".")
+	}
+
+	// Set legal hold query.
+	urlValues := make(url.Values)
+	urlValues.Set("legal-hold", "")
+	if versionID != "" {
+		urlValues.Set("versionId", versionID)
+	}
+
+	// Marshal legal hold body.
+	legalHoldBytes, err := xml.Marshal(objectLegalHold{
+		Status: status,
+	})
+	if err != nil {
+		return err
+	}
+
+	reqMetadata := requestMetadata{
+		bucketName:         bucketName,
+		objectName:         objectName,
+		queryValues:        urlValues,
+		contentBody:        bytes.NewReader(legalHoldBytes),
+		contentLength:      int64(len(legalHoldBytes)),
+		contentMD5Bytes:    sumMD5(legalHoldBytes),
+		contentSHA256Bytes: c.payloadSHA256(legalHoldBytes),
+	}
+
+	// Execute PUT on objectName to set legal hold.
+	resp, err := c.executeMethod("PUT", reqMetadata)
+	defer closeResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return c.objectLockError(bucketName, httpRespToAPIErrorResponse(resp, bucketName, objectName, "Object lock"))
+		}
+	}
+	return nil
+}
+
+// RestoreObject - restores a temporary copy of an archived object, e.g.
+// of storage class 'GLACIER' or 'DEEP_ARCHIVE', readable for days. Tier
+// is one of RestoreTierExpedited, RestoreTierStandard or
+// RestoreTierBulk.
+//
+// The restore runs in the background, StatObject reports its status in
+// ObjectInfo.Restore. Restoring an object already being restored fails
+// with an ErrorResponse of Code 'RestoreAlreadyInProgress', the object
+// becomes readable once the first restore completes. Restoring a
+// restored object extends its expiry.
+func (c Client) RestoreObject(bucketName, objectName string, days int, tier string) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return err
+	}
+	if days < 1 {
+		return ErrInvalidArgument("Restore days must be at least 1.")
+	}
+	if !isValidRestoreTier(tier) {
+		return ErrInvalidArgument("Unrecognized restore tier " + tier + ".")
+	}
+
+	// Set restore query.
+	urlValues := make(url.Values)
+	urlValues.Set("restore", "")
+
+	// Marshal restore body.
+	restore := restoreRequest{Days: days}
+	restore.GlacierJobParameters.Tier = tier
+	restoreBytes, err := xml.Marshal(restore)
+	if err != nil {
+		return err
+	}
+
+	reqMetadata := requestMetadata{
+		bucketName:         bucketName,
+		objectName:         objectName,
+		queryValues:        urlValues,
+		contentBody:        bytes.NewReader(restoreBytes),
+		contentLength:      int64(len(restoreBytes)),
+		contentMD5Bytes:    sumMD5(restoreBytes),
+		contentSHA256Bytes: c.payloadSHA256(restoreBytes),
+	}
+
+	// Execute POST on objectName to restore it.
+	resp, err := c.executeMethod("POST", reqMetadata)
+	defer closeResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		// '202 Accepted' for new restores, '200 OK' for restored
+		// objects.
+		if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+			return httpRespToErrorResponse(resp, bucketName, objectName)
+		}
+	}
+	return nil
+}
+
+// SetObjectACL set the canned ACL of an existing object, objects have
+// ACLs of their own independent of the ACL of their bucket. Use it for
+// example to make a single object public-read in a private bucket.
+//
+// Canned ACLs are the ones of SetBucketACL, missing objects are
+// reported as ErrorResponse with Code 'NoSuchKey'.
+func (c Client) SetObjectACL(bucketName, objectName string, acl BucketACL) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return err
+	}
+	if !acl.isValidBucketACL() {
+		return ErrInvalidArgument("Unrecognized ACL " + acl.String())
+	}
+
+	// Set acl query.
+	urlValues := make(url.Values)
+	urlValues.Set("acl", "")
+
+	// Set canned acl, defaults to private.
+	customHeader := make(http.Header)
+	customHeader.Set("x-amz-acl", acl.String())
+
+	// Execute PUT acl on objectName.
+	resp, err := c.executeMethod("PUT", requestMetadata{
+		bucketName:   bucketName,
+		objectName:   objectName,
+		queryValues:  urlValues,
+		customHeader: customHeader,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return httpRespToErrorResponse(resp, bucketName, objectName)
+		}
+	}
+	return nil
+}
+
+// SetObjectACLPolicy set the full access control policy of an existing
+// object, replacing its ACL with the explicit grants of policy. See
+// SetBucketACLPolicy for the grantees.
+func (c Client) SetObjectACLPolicy(bucketName, objectName string, policy AccessControlPolicy) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return err
+	}
+	if err := isValidAccessControlPolicy(policy); err != nil {
+		return err
+	}
+	return c.putAccessControlPolicy(bucketName, objectName, policy)
+}
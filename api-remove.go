@@ -17,8 +17,11 @@
 package minio
 
 import (
+	"bytes"
+	"encoding/xml"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 // RemoveBucket deletes the bucket name.
@@ -50,8 +53,62 @@ func (c Client) RemoveBucket(bucketName string) error {
 	return nil
 }
 
+// RemoveBucketWithForce deletes the bucket name and everything in it.
+//
+//  Destructive, all incomplete uploads are aborted and all objects,
+//  including all object versions and delete markers, are removed
+//  before the bucket itself. Use with caution.
+//
+// The first object which could not be removed, for example an object
+// under retention, is returned and the bucket is left in place. Closing
+// doneCh stops the removal, objects removed so far stay removed.
+func (c Client) RemoveBucketWithForce(bucketName string, doneCh <-chan struct{}) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+
+	// Abort all incomplete uploads, their parts would keep the bucket
+	// from being removed on some servers.
+	if _, err := c.RemoveIncompleteUploads(bucketName, "", 0); err != nil {
+		return err
+	}
+
+	// Remove all objects and versions, drain the channel to let the
+	// removal finish and keep the first failure.
+	var err error
+	for removeErr := range c.RemoveObjectsByPrefix(bucketName, "", doneCh) {
+		if err == nil {
+			err = removeErr.Err
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// Verify if we are done.
+	select {
+	case <-doneCh:
+		return ErrorResponse{
+			Code:       "RequestCanceled",
+			Message:    "Removal of bucket " + bucketName + " was canceled.",
+			BucketName: bucketName,
+			RequestID:  "minio",
+		}
+	default:
+	}
+	return c.RemoveBucket(bucketName)
+}
+
 // RemoveObject remove an object from a bucket.
 func (c Client) RemoveObject(bucketName, objectName string) error {
+	return c.RemoveObjectVersion(bucketName, objectName, "")
+}
+
+// RemoveObjectVersion remove a specific version of an object from a
+// versioned bucket. An empty versionID removes the latest version,
+// which on versioned buckets creates a delete marker.
+func (c Client) RemoveObjectVersion(bucketName, objectName, versionID string) error {
 	// Input validation.
 	if err := isValidBucketName(bucketName); err != nil {
 		return err
@@ -59,21 +116,254 @@ func (c Client) RemoveObject(bucketName, objectName string) error {
 	if err := isValidObjectName(objectName); err != nil {
 		return err
 	}
+
+	// Set version id if requested.
+	urlValues := make(url.Values)
+	if versionID != "" {
+		urlValues.Set("versionId", versionID)
+	}
+
 	// Execute DELETE on objectName.
 	resp, err := c.executeMethod("DELETE", requestMetadata{
-		bucketName: bucketName,
-		objectName: objectName,
+		bucketName:  bucketName,
+		objectName:  objectName,
+		queryValues: urlValues,
 	})
 	defer closeResponse(resp)
 	if err != nil {
 		return err
 	}
-	// DeleteObject always responds with http '204' even for
-	// objects which do not exist. So no need to handle them
-	// specifically.
+	// DeleteObject responds with http '204' even for objects which
+	// do not exist, any other status is an error, e.g. removing a
+	// version under retention fails with 'AccessDenied'.
+	if resp != nil {
+		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+			return httpRespToErrorResponse(resp, bucketName, objectName)
+		}
+	}
 	return nil
 }
 
+// RemoveObjectError - container of RemoveObjectsByPrefix error.
+type RemoveObjectError struct {
+	ObjectName string
+	VersionID  string
+	Err        error
+}
+
+// maxDeleteObjects - maximum number of objects per multi objects
+// delete request.
+const maxDeleteObjects = 1000
+
+// RemoveObjectsByPrefix removes all objects under prefix recursively,
+// similar to 'rm -rf bucket/prefix'. On versioned buckets all versions
+// and delete markers are removed as well.
+//
+// Objects are removed in batches of up to 1000 using multi objects
+// delete, every object which could not be removed is reported on the
+// returned channel. The channel is closed once all objects are
+// processed or doneCh is closed.
+//
+//   api := client.New(....)
+//   // Create a done channel.
+//   doneCh := make(chan struct{})
+//   defer close(doneCh)
+//   for removeErr := range api.RemoveObjectsByPrefix("mytestbucket", "photos/", doneCh) {
+//       fmt.Println(removeErr)
+//   }
+//
+func (c Client) RemoveObjectsByPrefix(bucketName, objectPrefix string, doneCh <-chan struct{}) <-chan RemoveObjectError {
+	errorCh := make(chan RemoveObjectError, 1)
+	// Validate bucket name.
+	if err := isValidBucketName(bucketName); err != nil {
+		defer close(errorCh)
+		errorCh <- RemoveObjectError{
+			Err: err,
+		}
+		return errorCh
+	}
+	// Validate incoming object prefix.
+	if err := isValidObjectPrefix(objectPrefix); err != nil {
+		defer close(errorCh)
+		errorCh <- RemoveObjectError{
+			Err: err,
+		}
+		return errorCh
+	}
+
+	go func(errorCh chan<- RemoveObjectError) {
+		defer close(errorCh)
+
+		// Servers without versioning support respond with an
+		// error, treat them as unversioned.
+		versioning, err := c.GetBucketVersioning(bucketName)
+		if err != nil && ToErrorResponse(err).Code != "NotImplemented" {
+			select {
+			case errorCh <- RemoveObjectError{Err: err}:
+			case <-doneCh:
+			}
+			return
+		}
+
+		// Stop listing once we are done.
+		listDoneCh := make(chan struct{})
+		defer close(listDoneCh)
+
+		// List all objects or all object versions recursively.
+		isRecursive := true
+		objectStatCh := c.ListObjects(bucketName, objectPrefix, isRecursive, listDoneCh)
+		if versioning != VersioningUnversioned {
+			objectStatCh = c.ListObjectVersions(bucketName, objectPrefix, isRecursive, listDoneCh)
+		}
+
+		// Remove objects in batches.
+		batch := make([]deleteObject, 0, maxDeleteObjects)
+		for object := range objectStatCh {
+			if object.Err != nil {
+				// The caller may have stopped reading errors.
+				select {
+				case errorCh <- RemoveObjectError{Err: object.Err}:
+				case <-doneCh:
+				}
+				return
+			}
+			batch = append(batch, deleteObject{
+				Key:       object.Key,
+				VersionID: object.VersionID,
+			})
+			if len(batch) < maxDeleteObjects {
+				continue
+			}
+			if !c.removeObjectsBatch(bucketName, batch, errorCh, doneCh) {
+				return
+			}
+			batch = batch[:0]
+		}
+		if len(batch) > 0 {
+			c.removeObjectsBatch(bucketName, batch, errorCh, doneCh)
+		}
+	}(errorCh)
+	return errorCh
+}
+
+// removeObjectsBatch removes a batch of objects and reports all the
+// failures on errorCh, returns false if doneCh was closed.
+func (c Client) removeObjectsBatch(bucketName string, batch []deleteObject, errorCh chan<- RemoveObjectError, doneCh <-chan struct{}) bool {
+	var removeErrs []RemoveObjectError
+	var result deleteMultiObjectsResult
+	var err error
+	if c.isGoogleCompatible() {
+		// Google Cloud Storage does not implement multi objects delete.
+		err = ErrAPINotSupported("Multi objects delete is not supported by Google Cloud Storage.")
+	} else {
+		result, err = c.removeObjectsQuery(bucketName, batch)
+	}
+	if err == nil {
+		for _, obj := range result.UnDeletedObjects {
+			removeErrs = append(removeErrs, RemoveObjectError{
+				ObjectName: obj.Key,
+				VersionID:  obj.VersionID,
+				Err: ErrorResponse{
+					Code:       obj.Code,
+					Message:    obj.Message,
+					BucketName: bucketName,
+					Key:        obj.Key,
+				},
+			})
+		}
+	} else if ToErrorResponse(err).Code == "NotImplemented" {
+		// Fall back to removing objects one by one for servers
+		// without multi objects delete support.
+		for _, obj := range batch {
+			if err = c.RemoveObjectVersion(bucketName, obj.Key, obj.VersionID); err != nil {
+				removeErrs = append(removeErrs, RemoveObjectError{
+					ObjectName: obj.Key,
+					VersionID:  obj.VersionID,
+					Err:        err,
+				})
+			}
+		}
+	} else {
+		for _, obj := range batch {
+			removeErrs = append(removeErrs, RemoveObjectError{
+				ObjectName: obj.Key,
+				VersionID:  obj.VersionID,
+				Err:        err,
+			})
+		}
+	}
+	for _, removeErr := range removeErrs {
+		select {
+		// Send the failure.
+		case errorCh <- removeErr:
+		// If receives done from the caller, return here.
+		case <-doneCh:
+			return false
+		}
+	}
+	// Verify if we are done.
+	select {
+	case <-doneCh:
+		return false
+	default:
+		return true
+	}
+}
+
+// removeObjectsQuery - (Multi Objects Delete) - removes up to 1000
+// objects in a single request.
+func (c Client) removeObjectsQuery(bucketName string, objects []deleteObject) (deleteMultiObjectsResult, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return deleteMultiObjectsResult{}, err
+	}
+	if len(objects) > maxDeleteObjects {
+		return deleteMultiObjectsResult{}, ErrInvalidArgument("Cannot remove more than 1000 objects in a single request.")
+	}
+
+	// Set delete query.
+	urlValues := make(url.Values)
+	urlValues.Set("delete", "")
+
+	// Marshal multi objects delete body.
+	deleteBytes, err := xml.Marshal(deleteMultiObjects{
+		Quiet:   true,
+		Objects: objects,
+	})
+	if err != nil {
+		return deleteMultiObjectsResult{}, err
+	}
+
+	reqMetadata := requestMetadata{
+		bucketName:         bucketName,
+		queryValues:        urlValues,
+		contentBody:        bytes.NewReader(deleteBytes),
+		contentLength:      int64(len(deleteBytes)),
+		contentMD5Bytes:    sumMD5(deleteBytes),
+		contentSHA256Bytes: c.payloadSHA256(deleteBytes),
+	}
+
+	// Execute POST on bucket to remove objects.
+	resp, err := c.executeMethod("POST", reqMetadata)
+	defer closeResponse(resp)
+	if err != nil {
+		return deleteMultiObjectsResult{}, err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			return deleteMultiObjectsResult{}, httpRespToAPIErrorResponse(resp, bucketName, "", "Multi objects delete")
+		}
+	}
+
+	// Decode multi objects delete result.
+	result := deleteMultiObjectsResult{}
+	err = xmlDecoder(resp.Body, &result)
+	if err != nil {
+		return deleteMultiObjectsResult{}, err
+	}
+	return result, nil
+}
+
 // RemoveIncompleteUpload aborts an partially uploaded object.
 // Requires explicit authentication, no anonymous requests are allowed for multipart API.
 func (c Client) RemoveIncompleteUpload(bucketName, objectName string) error {
@@ -99,6 +389,27 @@ func (c Client) RemoveIncompleteUpload(bucketName, objectName string) error {
 	return nil
 }
 
+// RemoveIncompleteUploads - aborts all incomplete uploads matching
+// objectPrefix which were initiated more than olderThan ago, returns the
+// number of aborted uploads. Zero olderThan aborts all incomplete
+// uploads, including uploads which may still be in progress.
+//
+// Use CountIncompleteUploads with the same arguments to find out what
+// would be removed.
+func (c Client) RemoveIncompleteUploads(bucketName, objectPrefix string, olderThan time.Duration) (int, error) {
+	var count int
+	// Turn off size aggregation of individual parts.
+	isAggregateSize := false
+	err := c.walkIncompleteUploads(bucketName, objectPrefix, olderThan, isAggregateSize, func(upload ObjectMultipartInfo) error {
+		if err := c.abortMultipartUpload(bucketName, upload.Key, upload.UploadID); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
 // abortMultipartUpload aborts a multipart upload for the given
 // uploadID, all previously uploaded parts are deleted.
 func (c Client) abortMultipartUpload(bucketName, objectName, uploadID string) error {
@@ -149,3 +460,99 @@ func (c Client) abortMultipartUpload(bucketName, objectName, uploadID string) er
 	}
 	return nil
 }
+
+// RemoveObjectTagging removes the entire tag set of an object.
+func (c Client) RemoveObjectTagging(bucketName, objectName string) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return err
+	}
+
+	// Set tagging query.
+	urlValues := make(url.Values)
+	urlValues.Set("tagging", "")
+
+	// Execute DELETE tagging on objectName.
+	resp, err := c.executeMethod("DELETE", requestMetadata{
+		bucketName:  bucketName,
+		objectName:  objectName,
+		queryValues: urlValues,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+			return httpRespToErrorResponse(resp, bucketName, objectName)
+		}
+	}
+	return nil
+}
+
+// RemoveBucketCORS removes the CORS configuration of a bucket, cross
+// origin requests are denied afterwards.
+//
+// Returns ErrAPINotSupported if the server does not support CORS
+// configuration.
+func (c Client) RemoveBucketCORS(bucketName string) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+
+	// Set cors query.
+	urlValues := make(url.Values)
+	urlValues.Set("cors", "")
+
+	// Execute DELETE cors on bucketName.
+	resp, err := c.executeMethod("DELETE", requestMetadata{
+		bucketName:  bucketName,
+		queryValues: urlValues,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+			return httpRespToAPIErrorResponse(resp, bucketName, "", "Bucket CORS")
+		}
+	}
+	return nil
+}
+
+// RemoveBucketLifecycle removes the lifecycle configuration of a
+// bucket.
+//
+// Returns ErrAPINotSupported if the server does not support lifecycle
+// configuration.
+func (c Client) RemoveBucketLifecycle(bucketName string) error {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return err
+	}
+
+	// Set lifecycle query.
+	urlValues := make(url.Values)
+	urlValues.Set("lifecycle", "")
+
+	// Execute DELETE lifecycle on bucketName.
+	resp, err := c.executeMethod("DELETE", requestMetadata{
+		bucketName:  bucketName,
+		queryValues: urlValues,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+			return httpRespToAPIErrorResponse(resp, bucketName, "", "Bucket lifecycle")
+		}
+	}
+	return nil
+}
@@ -73,6 +73,44 @@ type listBucketResult struct {
 	Prefix     string
 }
 
+// objectVersion container for a single version or delete marker
+// entry of listObjectVersions response.
+type objectVersion struct {
+	XMLName xml.Name
+	ObjectInfo
+}
+
+// listVersionsResult container for listObjectVersions response.
+type listVersionsResult struct {
+	Name      string
+	Prefix    string
+	Delimiter string
+
+	KeyMarker       string
+	VersionIDMarker string `xml:"VersionIdMarker"`
+	MaxKeys         int64
+
+	// EncodingType used to encode object keys in the response.
+	EncodingType string
+
+	// A flag that indicates whether or not all of the results
+	// that satisfied the search criteria were returned.
+	IsTruncated bool
+
+	// When response is truncated, use these markers in the
+	// subsequent request to get next set of object versions.
+	NextKeyMarker       string
+	NextVersionIDMarker string `xml:"NextVersionIdMarker"`
+
+	// A response can contain CommonPrefixes only if you have
+	// specified a delimiter.
+	CommonPrefixes []commonPrefix
+
+	// Versions and delete markers are interleaved in the response,
+	// both are collected here in the order they were returned.
+	Versions []objectVersion `xml:",any"`
+}
+
 // listMultipartUploadsResult container for ListMultipartUploads response
 type listMultipartUploadsResult struct {
 	Bucket             string
@@ -148,6 +186,17 @@ type completeMultipartUploadResult struct {
 	Bucket   string
 	Key      string
 	ETag     string
+
+	// Response headers of the completed object.
+	VersionID  string `xml:"-"`
+	Expiration string `xml:"-"`
+}
+
+// copyObjectResult container for copy object and upload part copy
+// response.
+type copyObjectResult struct {
+	ETag         string
+	LastModified time.Time
 }
 
 // completePart sub container lists individual part numbers and their
@@ -172,26 +221,38 @@ type createBucketConfiguration struct {
 	Location string   `xml:"LocationConstraint"`
 }
 
-// grant container for the grantee and his or her permissions.
-type grant struct {
-	// grantee container for DisplayName and ID of the person being
-	// granted permissions.
-	Grantee struct {
-		ID           string
-		DisplayName  string
-		EmailAddress string
-		Type         string
-		URI          string
-	}
-	Permission string
+// deleteObject container for a single object of multi objects delete
+// request.
+type deleteObject struct {
+	Key       string
+	VersionID string `xml:"VersionId,omitempty"`
 }
 
-// accessControlPolicy contains the elements providing ACL permissions
-// for a bucket.
-type accessControlPolicy struct {
-	// accessControlList container for ACL information.
-	AccessControlList struct {
-		Grant []grant
-	}
-	Owner owner
+// deleteMultiObjects container for multi objects delete request.
+type deleteMultiObjects struct {
+	XMLName xml.Name `xml:"Delete" json:"-"`
+	// Quiet mode only reports objects which failed to be deleted.
+	Quiet   bool
+	Objects []deleteObject `xml:"Object"`
+}
+
+// deletedObject container for a successfully deleted object.
+type deletedObject struct {
+	Key       string
+	VersionID string `xml:"VersionId,omitempty"`
+}
+
+// nonDeletedObject container for an object which failed to be deleted.
+type nonDeletedObject struct {
+	Key       string
+	VersionID string `xml:"VersionId"`
+	Code      string
+	Message   string
+}
+
+// deleteMultiObjectsResult container for multi objects delete response.
+type deleteMultiObjectsResult struct {
+	XMLName          xml.Name           `xml:"DeleteResult" json:"-"`
+	DeletedObjects   []deletedObject    `xml:"Deleted"`
+	UnDeletedObjects []nonDeletedObject `xml:"Error"`
 }
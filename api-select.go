@@ -0,0 +1,332 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CSVInputOptions - csv input serialization of SelectObjectContent.
+type CSVInputOptions struct {
+	// Use of the first line, 'NONE', 'IGNORE' or 'USE'.
+	FileHeaderInfo       string `xml:",omitempty"`
+	RecordDelimiter      string `xml:",omitempty"`
+	FieldDelimiter       string `xml:",omitempty"`
+	QuoteCharacter       string `xml:",omitempty"`
+	QuoteEscapeCharacter string `xml:",omitempty"`
+	Comments             string `xml:",omitempty"`
+}
+
+// JSONInputOptions - json input serialization of SelectObjectContent.
+type JSONInputOptions struct {
+	// Type of the json input, 'DOCUMENT' or 'LINES'.
+	Type string
+}
+
+// SelectInputSerialization - format of the object queried by
+// SelectObjectContent, exactly one of CSV and JSON must be set.
+type SelectInputSerialization struct {
+	// Compression of the object, 'NONE', 'GZIP' or 'BZIP2'.
+	CompressionType string            `xml:",omitempty"`
+	CSV             *CSVInputOptions  `xml:"CSV,omitempty"`
+	JSON            *JSONInputOptions `xml:"JSON,omitempty"`
+}
+
+// CSVOutputOptions - csv output serialization of SelectObjectContent.
+type CSVOutputOptions struct {
+	// Quoting of output fields, 'ALWAYS' or 'ASNEEDED'.
+	QuoteFields          string `xml:",omitempty"`
+	RecordDelimiter      string `xml:",omitempty"`
+	FieldDelimiter       string `xml:",omitempty"`
+	QuoteCharacter       string `xml:",omitempty"`
+	QuoteEscapeCharacter string `xml:",omitempty"`
+}
+
+// JSONOutputOptions - json output serialization of SelectObjectContent.
+type JSONOutputOptions struct {
+	RecordDelimiter string `xml:",omitempty"`
+}
+
+// SelectOutputSerialization - format of the records returned by
+// SelectObjectContent, exactly one of CSV and JSON must be set.
+type SelectOutputSerialization struct {
+	CSV  *CSVOutputOptions  `xml:"CSV,omitempty"`
+	JSON *JSONOutputOptions `xml:"JSON,omitempty"`
+}
+
+// SelectOptions - container for the query of SelectObjectContent.
+type SelectOptions struct {
+	// SQL expression, for example 'select * from S3Object'.
+	Expression          string
+	InputSerialization  SelectInputSerialization
+	OutputSerialization SelectOutputSerialization
+}
+
+// selectObjectContentRequest - request body of SelectObjectContent.
+type selectObjectContentRequest struct {
+	XMLName             xml.Name `xml:"SelectObjectContentRequest" json:"-"`
+	Expression          string
+	ExpressionType      string
+	InputSerialization  SelectInputSerialization
+	OutputSerialization SelectOutputSerialization
+}
+
+// SelectStats - statistics of a query, sent by the server at the end
+// of the results.
+type SelectStats struct {
+	XMLName        xml.Name `xml:"Stats" json:"-"`
+	BytesScanned   int64
+	BytesProcessed int64
+	BytesReturned  int64
+}
+
+/// Event stream message framing.
+
+const (
+	// Total length, headers length and prelude crc.
+	selectPreludeLength = 12
+	// Crc of the whole message.
+	selectMessageCRCLength = 4
+	// Header value type of strings, the only type sent by S3.
+	selectHeaderValueString = 7
+)
+
+// SelectResults - records returned by SelectObjectContent, read them
+// like any other stream and close once done. Stats are available once
+// all the records are read.
+type SelectResults struct {
+	resp *http.Response
+
+	bucketName string
+	objectName string
+
+	// Records of the current message not read yet.
+	records []byte
+	// Set once the end event is received.
+	isEnded bool
+	stats   *SelectStats
+	err     error
+}
+
+// Read reads up to len(p) bytes of records, io.EOF is returned once
+// the end event is received. Errors sent by the server while
+// processing the query are returned as ErrorResponse.
+func (s *SelectResults) Read(p []byte) (n int, err error) {
+	for len(s.records) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		if s.isEnded {
+			return 0, io.EOF
+		}
+		s.err = s.readMessage()
+	}
+	n = copy(p, s.records)
+	s.records = s.records[n:]
+	return n, nil
+}
+
+// Stats returns the statistics of the query, nil until all the records
+// are read.
+func (s *SelectResults) Stats() *SelectStats {
+	return s.stats
+}
+
+// Close closes the results, the rest of the records are discarded.
+func (s *SelectResults) Close() error {
+	return s.resp.Body.Close()
+}
+
+// readMessage - reads and handles the next message of the event stream.
+func (s *SelectResults) readMessage() error {
+	prelude := make([]byte, selectPreludeLength)
+	if _, err := io.ReadFull(s.resp.Body, prelude); err != nil {
+		if err == io.EOF {
+			// Stream must be terminated by an end event.
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	totalLength := binary.BigEndian.Uint32(prelude[0:4])
+	headersLength := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[0:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return s.internalError("Select message prelude checksum mismatch.")
+	}
+	if uint64(totalLength) < uint64(headersLength)+selectPreludeLength+selectMessageCRCLength {
+		return s.internalError("Select message length is invalid.")
+	}
+
+	message := make([]byte, totalLength-selectPreludeLength)
+	if _, err := io.ReadFull(s.resp.Body, message); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	crc := crc32.NewIEEE()
+	crc.Write(prelude)
+	crc.Write(message[:len(message)-selectMessageCRCLength])
+	if crc.Sum32() != binary.BigEndian.Uint32(message[len(message)-selectMessageCRCLength:]) {
+		return s.internalError("Select message checksum mismatch.")
+	}
+
+	headers, err := parseSelectHeaders(message[:headersLength])
+	if err != nil {
+		return s.internalError(err.Error())
+	}
+	payload := message[headersLength : len(message)-selectMessageCRCLength]
+
+	switch headers.Get(":message-type") {
+	case "error":
+		return ErrorResponse{
+			Code:       headers.Get(":error-code"),
+			Message:    headers.Get(":error-message"),
+			BucketName: s.bucketName,
+			Key:        s.objectName,
+			RequestID:  s.resp.Header.Get("x-amz-request-id"),
+			HostID:     s.resp.Header.Get("x-amz-id-2"),
+		}
+	case "event":
+		switch headers.Get(":event-type") {
+		case "Records":
+			s.records = payload
+		case "Stats":
+			stats := &SelectStats{}
+			if err = xmlDecoder(bytes.NewReader(payload), stats); err != nil {
+				return err
+			}
+			s.stats = stats
+		case "End":
+			s.isEnded = true
+		}
+		// Progress and Cont (keep alive) events are ignored.
+		return nil
+	}
+	return s.internalError(fmt.Sprintf("Select message type '%s' is unknown.", headers.Get(":message-type")))
+}
+
+// internalError - error for malformed event streams.
+func (s *SelectResults) internalError(message string) error {
+	return ErrorResponse{
+		Code:       "InternalError",
+		Message:    message + " " + reportIssue,
+		BucketName: s.bucketName,
+		Key:        s.objectName,
+		RequestID:  s.resp.Header.Get("x-amz-request-id"),
+		HostID:     s.resp.Header.Get("x-amz-id-2"),
+	}
+}
+
+// parseSelectHeaders - parses the headers of an event stream message,
+// each header is a one byte name length, the name, a one byte value
+// type, a two bytes value length and the value.
+func parseSelectHeaders(data []byte) (http.Header, error) {
+	headers := make(http.Header)
+	for len(data) > 0 {
+		nameLength := int(data[0])
+		if len(data) < 1+nameLength+3 {
+			return nil, fmt.Errorf("Select message header is truncated.")
+		}
+		name := string(data[1 : 1+nameLength])
+		data = data[1+nameLength:]
+		if data[0] != selectHeaderValueString {
+			return nil, fmt.Errorf("Select message header '%s' has unsupported type %d.", name, data[0])
+		}
+		valueLength := int(binary.BigEndian.Uint16(data[1:3]))
+		if len(data) < 3+valueLength {
+			return nil, fmt.Errorf("Select message header '%s' is truncated.", name)
+		}
+		// Names are case sensitive, bypass canonicalization.
+		headers[name] = []string{string(data[3 : 3+valueLength])}
+		data = data[3+valueLength:]
+	}
+	return headers, nil
+}
+
+// SelectObjectContent - filters the content of an object server side
+// with a SQL expression, only the matching records are returned.
+//
+// Records are streamed as the server produces them, read them until
+// io.EOF and close the results. Errors while processing the query are
+// returned by Read.
+func (c Client) SelectObjectContent(bucketName, objectName string, opts SelectOptions) (*SelectResults, error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return nil, err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(opts.Expression) == "" {
+		return nil, ErrInvalidArgument("SQL expression cannot be empty.")
+	}
+	if (opts.InputSerialization.CSV == nil) == (opts.InputSerialization.JSON == nil) {
+		return nil, ErrInvalidArgument("Exactly one of CSV and JSON input serialization must be set.")
+	}
+	if (opts.OutputSerialization.CSV == nil) == (opts.OutputSerialization.JSON == nil) {
+		return nil, ErrInvalidArgument("Exactly one of CSV and JSON output serialization must be set.")
+	}
+
+	// Set select query.
+	urlValues := make(url.Values)
+	urlValues.Set("select", "")
+	urlValues.Set("select-type", "2")
+
+	selectBytes, err := xml.Marshal(selectObjectContentRequest{
+		Expression:          opts.Expression,
+		ExpressionType:      "SQL",
+		InputSerialization:  opts.InputSerialization,
+		OutputSerialization: opts.OutputSerialization,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Execute POST on objectName to run the query.
+	resp, err := c.executeMethod("POST", requestMetadata{
+		bucketName:         bucketName,
+		objectName:         objectName,
+		queryValues:        urlValues,
+		contentBody:        bytes.NewReader(selectBytes),
+		contentLength:      int64(len(selectBytes)),
+		contentMD5Bytes:    sumMD5(selectBytes),
+		contentSHA256Bytes: c.payloadSHA256(selectBytes),
+	})
+	if err != nil {
+		closeResponse(resp)
+		return nil, err
+	}
+	if resp != nil {
+		if resp.StatusCode != http.StatusOK {
+			defer closeResponse(resp)
+			return nil, httpRespToErrorResponse(resp, bucketName, objectName)
+		}
+	}
+	return &SelectResults{
+		resp:       resp,
+		bucketName: bucketName,
+		objectName: objectName,
+	}, nil
+}
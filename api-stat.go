@@ -18,8 +18,10 @@ package minio
 
 import (
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -48,26 +50,41 @@ func (c Client) BucketExists(bucketName string) error {
 
 // StatObject verifies if object exists and you have permission to access.
 func (c Client) StatObject(bucketName, objectName string) (ObjectInfo, error) {
+	objInfo, _, err := c.statObject(bucketName, objectName, "")
+	return objInfo, err
+}
+
+// statObject - stats the object and also returns the response headers,
+// for callers in need of the user metadata of the object. An empty
+// versionID stats the latest version.
+func (c Client) statObject(bucketName, objectName, versionID string) (ObjectInfo, http.Header, error) {
 	// Input validation.
 	if err := isValidBucketName(bucketName); err != nil {
-		return ObjectInfo{}, err
+		return ObjectInfo{}, nil, err
 	}
 	if err := isValidObjectName(objectName); err != nil {
-		return ObjectInfo{}, err
+		return ObjectInfo{}, nil, err
+	}
+
+	// Set version id if requested.
+	urlValues := make(url.Values)
+	if versionID != "" {
+		urlValues.Set("versionId", versionID)
 	}
 
 	// Execute HEAD on objectName.
 	resp, err := c.executeMethod("HEAD", requestMetadata{
-		bucketName: bucketName,
-		objectName: objectName,
+		bucketName:  bucketName,
+		objectName:  objectName,
+		queryValues: urlValues,
 	})
 	defer closeResponse(resp)
 	if err != nil {
-		return ObjectInfo{}, err
+		return ObjectInfo{}, nil, err
 	}
 	if resp != nil {
 		if resp.StatusCode != http.StatusOK {
-			return ObjectInfo{}, httpRespToErrorResponse(resp, bucketName, objectName)
+			return ObjectInfo{}, nil, httpRespToErrorResponse(resp, bucketName, objectName)
 		}
 	}
 
@@ -78,7 +95,7 @@ func (c Client) StatObject(bucketName, objectName string) (ObjectInfo, error) {
 	// Parse content length.
 	size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
 	if err != nil {
-		return ObjectInfo{}, ErrorResponse{
+		return ObjectInfo{}, nil, ErrorResponse{
 			Code:       "InternalError",
 			Message:    "Content-Length is invalid. " + reportIssue,
 			BucketName: bucketName,
@@ -91,7 +108,7 @@ func (c Client) StatObject(bucketName, objectName string) (ObjectInfo, error) {
 	// Parse Last-Modified has http time format.
 	date, err := time.Parse(http.TimeFormat, resp.Header.Get("Last-Modified"))
 	if err != nil {
-		return ObjectInfo{}, ErrorResponse{
+		return ObjectInfo{}, nil, ErrorResponse{
 			Code:       "InternalError",
 			Message:    "Last-Modified time format is invalid. " + reportIssue,
 			BucketName: bucketName,
@@ -113,5 +130,91 @@ func (c Client) StatObject(bucketName, objectName string) (ObjectInfo, error) {
 	objectStat.Size = size
 	objectStat.LastModified = date
 	objectStat.ContentType = contentType
-	return objectStat, nil
+	objectStat.ContentEncoding = resp.Header.Get("Content-Encoding")
+	objectStat.VersionID = resp.Header.Get("x-amz-version-id")
+	objectStat.Expiration = resp.Header.Get("x-amz-expiration")
+	objectStat.StorageClass = resp.Header.Get("x-amz-storage-class")
+	objectStat.Restore = parseRestoreInfo(resp.Header.Get("x-amz-restore"))
+	// Invalid 'Expires' dates mean the object is already stale, they
+	// are left unset.
+	if expires, err := time.Parse(http.TimeFormat, resp.Header.Get("Expires")); err == nil {
+		objectStat.Expires = expires
+	}
+	return objectStat, resp.Header, nil
+}
+
+// StatObjects - stats all the objects received on objectNames with up
+// to concurrency HEAD requests in flight and sends their metadata on
+// the returned channel. Results are sent in the order they complete,
+// every result has Key set to its object name and Err set if the stat
+// failed. The channel is closed once objectNames is closed and all
+// the results are sent, or once doneCh is closed.
+//
+// Example:
+//
+//   doneCh := make(chan struct{})
+//   defer close(doneCh)
+//   for objInfo := range api.StatObjects("mytestbucket", objectNames, 16, doneCh) {
+//       fmt.Println(objInfo.Key, objInfo.Size, objInfo.Err)
+//   }
+//
+func (c Client) StatObjects(bucketName string, objectNames <-chan string, concurrency int, doneCh <-chan struct{}) <-chan ObjectInfo {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		objectStatCh := make(chan ObjectInfo, 1)
+		defer close(objectStatCh)
+		objectStatCh <- ObjectInfo{
+			Err: err,
+		}
+		return objectStatCh
+	}
+	if concurrency <= 0 {
+		objectStatCh := make(chan ObjectInfo, 1)
+		defer close(objectStatCh)
+		objectStatCh <- ObjectInfo{
+			Err: ErrInvalidArgument("Concurrency cannot be negative or equal to zero."),
+		}
+		return objectStatCh
+	}
+
+	objectStatCh := make(chan ObjectInfo, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				var objectName string
+				var ok bool
+				select {
+				case objectName, ok = <-objectNames:
+					if !ok {
+						return
+					}
+				// If receives done from the caller, return here.
+				case <-doneCh:
+					return
+				}
+				objInfo, err := c.StatObject(bucketName, objectName)
+				if err != nil {
+					objInfo = ObjectInfo{
+						Key: objectName,
+						Err: err,
+					}
+				}
+				select {
+				case objectStatCh <- objInfo:
+				// If receives done from the caller, return here.
+				case <-doneCh:
+					return
+				}
+			}
+		}()
+	}
+	// Close the channel once all the workers are done.
+	go func() {
+		wg.Wait()
+		close(objectStatCh)
+	}()
+	return objectStatCh
 }
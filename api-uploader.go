@@ -0,0 +1,369 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
+	"sync"
+)
+
+// Uploader - uploads objects with settings configured once, picking
+// single PUT or multipart upload by the size of each object.
+//
+// Set the fields before the first Upload, an Uploader may then be used
+// by concurrent uploads.
+type Uploader struct {
+	// Part size of multipart uploads, '0' keeps the part size of the
+	// client. Must be between 5MiB and 5GiB otherwise.
+	PartSize int64
+
+	// Number of parts uploaded in parallel, '0' and '1' upload one
+	// part after the other. Up to Concurrency+1 parts are buffered in
+	// memory, the next part is read while Concurrency parts are
+	// uploaded.
+	Concurrency int
+
+	// Content type of all uploaded objects. If empty it is detected
+	// from the extension of the object name, or from the first 512
+	// bytes of readers implementing io.ReaderAt.
+	ContentType string
+
+	// Progress is read the number of bytes uploaded, same as the
+	// progress of PutObjectWithProgress. With concurrency it is
+	// advanced once a part is uploaded.
+	Progress io.Reader
+
+	// Set to 'true' to keep the parts of failed multipart uploads, the
+	// next upload of the object only uploads the missing parts.
+	Resumable bool
+
+	// OnPartComplete is called for each part of multipart uploads once
+	// the server acknowledged it, including parts uploaded before by a
+	// resumed upload. With concurrency it is called concurrently from
+	// the routines uploading the parts. Single PUT uploads do not call
+	// it.
+	OnPartComplete func(partNumber int, etag string, size int64)
+
+	// Client of all the uploads.
+	client Client
+}
+
+// NewUploader - instantiate a new uploader using client for all the
+// requests, uploads are sent one part after the other by default.
+func NewUploader(client Client) *Uploader {
+	return &Uploader{
+		Concurrency: 1,
+		client:      client,
+	}
+}
+
+// Upload - uploads the data of reader as objectName, returns the number
+// of bytes uploaded.
+//
+// Objects smaller than the multipart threshold of the client are
+// uploaded with a single PUT, larger objects and streams of unknown
+// size with multipart upload. Failed requests are retried by the
+// client, failed multipart uploads are aborted unless Resumable is
+// set.
+func (u *Uploader) Upload(bucketName, objectName string, reader io.Reader) (n int64, err error) {
+	// Input validation.
+	if err := isValidBucketName(bucketName); err != nil {
+		return 0, err
+	}
+	if err := isValidObjectName(objectName); err != nil {
+		return 0, err
+	}
+	if reader == nil {
+		return 0, ErrInvalidArgument("Input reader is invalid, cannot be nil.")
+	}
+	if u.Concurrency < 0 {
+		return 0, ErrInvalidArgument("Concurrency cannot be negative.")
+	}
+
+	// Apply the settings to a copy of the client.
+	c := u.client
+	if u.PartSize != 0 {
+		if err = c.SetPartSize(u.PartSize); err != nil {
+			return 0, err
+		}
+	}
+	if u.Resumable {
+		c.isUploadResumable = true
+	}
+	c.onPartComplete = u.OnPartComplete
+
+	contentType := u.ContentType
+	if contentType == "" {
+		contentType, err = detectReaderContentType(objectName, reader)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if u.Concurrency <= 1 {
+		return c.PutObjectWithProgress(bucketName, objectName, reader, contentType, u.Progress)
+	}
+
+	// Size of the object.
+	size, err := getReaderSize(reader)
+	if err != nil {
+		return 0, err
+	}
+	if size > maxMultipartPutObjectSize {
+		return 0, ErrEntityTooLarge(size, maxMultipartPutObjectSize, bucketName, objectName)
+	}
+	if size < 0 {
+		var isEmpty bool
+		reader, isEmpty, err = peekEmpty(reader)
+		if err != nil {
+			return 0, err
+		}
+		if isEmpty {
+			size = 0
+		}
+	}
+
+	// Small objects and servers without multipart support are
+	// uploaded as usual.
+	isMultipartAvailable := !c.isMultipartDisabled && !c.isGoogleCompatible() && !(isAmazonEndpoint(c.endpointURL) && c.anonymous)
+	if !isMultipartAvailable || (size >= 0 && size < c.multipartThreshold) {
+		return c.PutObjectWithProgress(bucketName, objectName, reader, contentType, u.Progress)
+	}
+	return c.putObjectMultipartParallel(bucketName, objectName, reader, size, contentType, u.Concurrency, u.Progress)
+}
+
+// detectReaderContentType - detects content type from the extension of
+// objectName, falls back to sniffing the first 512 bytes of readers
+// implementing io.ReaderAt. Other readers are not consumed and default
+// to 'application/octet-stream'.
+func detectReaderContentType(objectName string, reader io.Reader) (string, error) {
+	if contentType := mime.TypeByExtension(path.Ext(objectName)); contentType != "" {
+		return contentType, nil
+	}
+	readerAt, ok := reader.(io.ReaderAt)
+	if !ok {
+		return "application/octet-stream", nil
+	}
+	// Only the first 512 bytes are considered by DetectContentType.
+	buf := make([]byte, 512)
+	n, err := readerAt.ReadAt(buf, 0)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if n == 0 {
+		return "application/octet-stream", nil
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
+
+// uploadPartReq - a part read by putObjectMultipartParallel, uploaded by
+// one of its workers.
+type uploadPartReq struct {
+	partNumber int
+	buffer     *bytes.Buffer
+	md5Sum     []byte
+	sha256Sum  []byte
+	size       int64
+}
+
+// putObjectMultipartParallel - multipart upload of reader with up to
+// concurrency parts uploaded in parallel. Parts are read one after the
+// other, previously uploaded parts with the same MD5 sum are skipped.
+func (c Client) putObjectMultipartParallel(bucketName, objectName string, reader io.Reader, size int64, contentType string, concurrency int, progress io.Reader) (n int64, err error) {
+	// Get upload id for an object, initiates a new multipart request
+	// if it cannot find any previously partially uploaded object.
+	uploadID, isNew, err := c.getUploadID(bucketName, objectName, contentType)
+	if err != nil {
+		return 0, err
+	}
+
+	// Abort the upload on failure, its parts would be left behind.
+	defer func() {
+		if err != nil {
+			c.abortFailedUpload(bucketName, objectName, uploadID, err)
+		}
+	}()
+
+	// Fetch previously uploaded parts.
+	var partsInfo = make(map[int]objectPart)
+	if !isNew {
+		partsInfo, err = c.listObjectParts(bucketName, objectName, uploadID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// Calculate the optimal parts info for a given size.
+	totalPartsCount, partSize, _, err := c.partInfo(size)
+	if err != nil {
+		return 0, err
+	}
+
+	// Parts are uploaded by concurrency workers, their results are
+	// collected by a single routine.
+	uploadCh := make(chan uploadPartReq)
+	resultCh := make(chan objectPart, concurrency)
+	var mutex sync.Mutex
+	var uploadErr error
+	failed := func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return uploadErr != nil
+	}
+	fail := func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if uploadErr == nil {
+			uploadErr = err
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for req := range uploadCh {
+				// Drain the remaining parts once an upload failed.
+				if failed() {
+					c.bufferPool.Put(req.buffer)
+					continue
+				}
+				objPart, err := c.uploadPart(bucketName, objectName, uploadID, bytes.NewReader(req.buffer.Bytes()),
+					req.partNumber, req.md5Sum, req.sha256Sum, req.size)
+				c.bufferPool.Put(req.buffer)
+				if err != nil {
+					fail(err)
+					continue
+				}
+				resultCh <- objPart
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(resultCh)
+	}()
+
+	uploadedParts := make(map[int]objectPart)
+	collectDoneCh := make(chan struct{})
+	go func() {
+		defer close(collectDoneCh)
+		for objPart := range resultCh {
+			uploadedParts[objPart.PartNumber] = objPart
+			// Update the progress reader for the uploaded part.
+			if progress != nil && !failed() {
+				if _, err := io.CopyN(ioutil.Discard, progress, objPart.Size); err != nil {
+					fail(err)
+				}
+			}
+		}
+	}()
+
+	// MD5 sums of all the parts, to verify the final ETag.
+	md5Sums := make(map[int][]byte)
+
+	// Read all the parts and hand them to the workers.
+	var totalUploadedSize int64
+	var readErr error
+	for partNumber := 1; partNumber <= totalPartsCount && !failed(); partNumber++ {
+		// Each part is staged in its own buffer until it is uploaded.
+		req := uploadPartReq{
+			partNumber: partNumber,
+			buffer:     c.bufferPool.Get(),
+		}
+		req.md5Sum, req.sha256Sum, req.size, readErr = c.hashCopyN(req.buffer, reader, partSize)
+		if readErr != nil && readErr != io.EOF {
+			c.bufferPool.Put(req.buffer)
+			break
+		}
+		// Stream of unknown size ended at a part boundary, do not
+		// upload an empty last part.
+		if req.size == 0 && partNumber > 1 {
+			c.bufferPool.Put(req.buffer)
+			break
+		}
+		md5Sums[partNumber] = req.md5Sum
+		totalUploadedSize += req.size
+
+		// Skip parts uploaded by a previous session.
+		if !shouldUploadPart(objectPart{
+			ETag:       hex.EncodeToString(req.md5Sum),
+			PartNumber: partNumber,
+			Size:       req.size,
+		}, partsInfo) {
+			c.bufferPool.Put(req.buffer)
+			c.partComplete(partsInfo[partNumber])
+			resultCh <- partsInfo[partNumber]
+		} else {
+			uploadCh <- req
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+	close(uploadCh)
+	<-collectDoneCh
+
+	if readErr != nil && readErr != io.EOF {
+		return totalUploadedSize, readErr
+	}
+	if uploadErr != nil {
+		return totalUploadedSize, uploadErr
+	}
+
+	// Verify if we uploaded all the data.
+	if size >= 0 && totalUploadedSize != size {
+		return totalUploadedSize, ErrUnexpectedEOF(totalUploadedSize, size, bucketName, objectName)
+	}
+
+	// Save all the parts in a Parts array before completing the
+	// multipart request.
+	var complMultipartUpload completeMultipartUpload
+	for _, part := range uploadedParts {
+		complMultipartUpload.Parts = append(complMultipartUpload.Parts, completePart{
+			ETag:       part.ETag,
+			PartNumber: part.PartNumber,
+		})
+	}
+	if len(complMultipartUpload.Parts) != len(md5Sums) {
+		return totalUploadedSize, ErrInvalidParts(len(md5Sums), len(complMultipartUpload.Parts))
+	}
+
+	// Sort all completed parts.
+	sort.Sort(completedParts(complMultipartUpload.Parts))
+	complResult, err := c.completeMultipartUpload(bucketName, objectName, uploadID, complMultipartUpload)
+	if err != nil {
+		return totalUploadedSize, err
+	}
+	if c.isMultipartETagVerified {
+		if err = verifyMultipartETag(bucketName, objectName, complResult.ETag, complMultipartUpload.Parts, md5Sums); err != nil {
+			return totalUploadedSize, err
+		}
+	}
+
+	// Return final size.
+	return totalUploadedSize, nil
+}
@@ -18,12 +18,16 @@ package minio
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/xml"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -43,10 +47,17 @@ type Client struct {
 	accessKeyID string
 	// SecretAccessKey required for authorized requests.
 	secretAccessKey string
+	// SessionToken of temporary credentials, signed with every request.
+	sessionToken string
 	// Choose a signature type if necessary.
 	signature SignatureType
 	// Set to 'true' if Client has no access and secret keys.
 	anonymous bool
+	// Set to 'true' if endpoint is Google Cloud Storage compatible.
+	isGCSCompatible bool
+
+	// Credential providers, nil for static credentials.
+	credentials *Credentials
 
 	// User supplied.
 	appInfo struct {
@@ -58,11 +69,91 @@ type Client struct {
 	// Needs allocation.
 	httpClient     *http.Client
 	bucketLocCache *bucketLocationCache
+	clockSkew      *clockSkew
+	bufferPool     *bufferPool
+	serverType     *serverType
+
+	// Caches the addresses of the endpoint, set by SetDNSCache.
+	dnsCache *dnsCache
 
 	// Advanced functionality.
 	isTraceEnabled bool
 	traceOutput    io.Writer
 
+	// Set to 'true' to upload all objects with a single PUT.
+	isMultipartDisabled bool
+
+	// Receives the metadata of the object created by an upload, only
+	// set on copies of the client made by PutObjectWithInfo.
+	uploadInfo *ObjectInfo
+
+	// Headers sent with all the requests creating objects, only set on
+	// copies of the client made by PutObjectWithExpires.
+	uploadHeader http.Header
+
+	// Cancels the requests once done, only set on copies of the client
+	// made by the WithContext operations.
+	ctx context.Context
+
+	// Called for each part of multipart uploads acknowledged by the
+	// server, only set on copies of the client made by Uploader.
+	onPartComplete func(partNumber int, etag string, size int64)
+
+	// Set to 'true' to keep the parts of failed multipart uploads,
+	// the next upload of the object resumes from them.
+	isUploadResumable bool
+
+	// Set to 'true' to skip syncing downloaded files to disk.
+	isFileSyncDisabled bool
+
+	// Set to 'true' to verify the ETag of completed multipart uploads
+	// against the locally computed part MD5 sums.
+	isMultipartETagVerified bool
+
+	// Objects of this size and larger are uploaded with multipart.
+	multipartThreshold int64
+
+	// Part size of multipart uploads, '0' calculates the optimal part
+	// size from the object size.
+	partSize int64
+
+	// Set to 'true' to normalize slashes and dot segments of object
+	// names.
+	isObjectNameNormalized bool
+
+	// Headers set on every request, signed like all the other
+	// headers. Copied on write, clients share it after copying.
+	customHeader http.Header
+
+	// Host header sent and signed instead of the host of the
+	// endpoint, empty to use the endpoint.
+	customHost string
+
+	// Limit the aggregate rate of request and response bodies, nil
+	// for unlimited.
+	uploadLimiter   *bandwidthLimiter
+	downloadLimiter *bandwidthLimiter
+
+	// Limits concurrent requests once the server throttles them, set
+	// by SetAdaptiveThrottling.
+	throttle *adaptiveThrottle
+
+	// Receives the metrics of all the requests, set by
+	// SetMetricsCollector.
+	metricsCollector MetricsCollector
+
+	// Size of the ranges objects are read ahead in, set by
+	// SetReadAhead.
+	readAheadSize int64
+
+	// Objects listed per request by ListObjects and
+	// ListObjectVersions, '0' for 1000.
+	listMaxKeys int
+
+	// Normalizes error responses of non-standard servers, nil for
+	// the default parsing.
+	errorResponseParser func(statusCode int, header http.Header, body []byte) ErrorResponse
+
 	// Random seed.
 	random *rand.Rand
 }
@@ -108,6 +199,13 @@ func NewV4(endpoint string, accessKeyID, secretAccessKey string, insecure bool)
 
 // New - instantiate minio client Client, adds automatic verification
 // of signature.
+//
+// The signature type is detected from the endpoint:
+//  - Google Cloud Storage 'storage.googleapis.com' uses SignatureV2.
+//  - Amazon S3 's3.amazonaws.com' uses SignatureV4.
+//  - All other endpoints use SignatureV4.
+//  - Empty access and secret keys send anonymous requests.
+// Use SetSignatureType to override the detected signature type.
 func New(endpoint string, accessKeyID, secretAccessKey string, insecure bool) (*Client, error) {
 	clnt, err := privateNew(endpoint, accessKeyID, secretAccessKey, insecure)
 	if err != nil {
@@ -125,6 +223,54 @@ func New(endpoint string, accessKeyID, secretAccessKey string, insecure bool) (*
 	return clnt, nil
 }
 
+// NewGCS - instantiate minio client Client for Google Cloud Storage
+// or any endpoint compatible with its XML API, such as a proxy or an
+// emulator. Requests are signed with signature version '2' and use
+// path style. Multipart uploads and multi objects delete, which
+// Google Cloud Storage does not support, fall back to single PUT and
+// single DELETE operations.
+func NewGCS(endpoint string, accessKeyID, secretAccessKey string, insecure bool) (*Client, error) {
+	clnt, err := privateNew(endpoint, accessKeyID, secretAccessKey, insecure)
+	if err != nil {
+		return nil, err
+	}
+	// Set to use signature version '2'.
+	clnt.signature = SignatureV2
+	clnt.isGCSCompatible = true
+	return clnt, nil
+}
+
+// isGoogleCompatible - returns true for Google Cloud Storage and
+// endpoints compatible with it.
+func (c Client) isGoogleCompatible() bool {
+	return c.isGCSCompatible || isGoogleEndpoint(c.endpointURL)
+}
+
+// NewWithCredentials - instantiate minio client Client with
+// credentials resolved from a chain of providers, adds automatic
+// verification of signature.
+//
+// Use NewDefaultCredentials to resolve credentials the same way as
+// the AWS SDK.
+func NewWithCredentials(endpoint string, creds *Credentials, insecure bool) (*Client, error) {
+	if creds == nil {
+		return nil, ErrInvalidArgument("Credentials cannot be empty.")
+	}
+	accessKeyID, secretAccessKey, sessionToken, err := creds.Get()
+	if err != nil {
+		return nil, err
+	}
+	clnt, err := New(endpoint, accessKeyID, secretAccessKey, insecure)
+	if err != nil {
+		return nil, err
+	}
+	// Save session token of temporary credentials.
+	clnt.sessionToken = sessionToken
+	// Save credentials to retrieve them again once expired.
+	clnt.credentials = creds
+	return clnt, nil
+}
+
 // lockedRandSource provides protected rand source, implements rand.Source interface.
 type lockedRandSource struct {
 	lk  sync.Mutex
@@ -171,13 +317,26 @@ func privateNew(endpoint, accessKeyID, secretAccessKey string, insecure bool) (*
 		// Setting a sensible time out of 2minutes to wait for response
 		// headers. Request is pro-actively cancelled after 2minutes
 		// if no response was received from server.
-		Timeout:   2 * time.Minute,
-		Transport: http.DefaultTransport,
+		Timeout:       2 * time.Minute,
+		Transport:     http.DefaultTransport,
+		CheckRedirect: doNotFollowRedirects,
 	}
 
+	// Switch to multipart uploads at the minimum part size.
+	clnt.multipartThreshold = minPartSize
+
 	// Instantiae bucket location cache.
 	clnt.bucketLocCache = newBucketLocationCache()
 
+	// Instantiate clock skew cache.
+	clnt.clockSkew = newClockSkew()
+
+	// Instantiate server type cache.
+	clnt.serverType = newServerType()
+
+	// Instantiate buffer pool, keeping buffers of single PUT size.
+	clnt.bufferPool = newBufferPool(minPartSize)
+
 	// Introduce a new locked random seed.
 	clnt.random = rand.New(&lockedRandSource{src: rand.NewSource(time.Now().UTC().UnixNano())})
 
@@ -185,6 +344,128 @@ func privateNew(endpoint, accessKeyID, secretAccessKey string, insecure bool) (*
 	return clnt, nil
 }
 
+// SetSignatureType - overrides the signature type detected by New,
+// for gateways which only accept one of SignatureV2 or SignatureV4.
+// SignatureAnonymous sends unsigned requests even with credentials.
+func (c *Client) SetSignatureType(signature SignatureType) error {
+	if !signature.isValid() {
+		return ErrInvalidArgument("Unrecognized signature type " + signature.String() + ".")
+	}
+	c.signature = signature
+	c.anonymous = signature.isAnonymous() || c.accessKeyID == "" || c.secretAccessKey == ""
+	return nil
+}
+
+// GetSignatureType - returns the signature type used to sign requests,
+// SignatureAnonymous if requests are not signed.
+func (c Client) GetSignatureType() SignatureType {
+	if c.anonymous {
+		return SignatureAnonymous
+	}
+	if c.signature.isV4() {
+		return SignatureV4
+	}
+	return c.signature
+}
+
+// ServerType - returns the type of server the client talks to, one of
+// ServerTypeMinio, ServerTypeAmazon or ServerTypeCeph as detected from
+// the Server header of its responses. ServerTypeUnknown is returned
+// before the first response and for other servers.
+//
+// This lets applications enable server specific features, e.g.
+// ListenBucketNotification only on MinIO.
+func (c Client) ServerType() string {
+	return c.serverType.Get()
+}
+
+// SetCustomHeader - set a header on all the requests of the client,
+// for example 'x-amz-request-payer: requester'. Custom headers are
+// signed like all the other headers, headers set by an operation
+// itself take precedence. An empty value removes the header.
+func (c *Client) SetCustomHeader(key, value string) {
+	customHeader := cloneHeader(c.customHeader)
+	if value == "" {
+		customHeader.Del(key)
+	} else {
+		customHeader.Set(key, value)
+	}
+	c.customHeader = customHeader
+}
+
+// SetCustomHost - send and sign host as the 'Host' header of all the
+// requests, while connections, including TLS server name verification,
+// still go to the endpoint. Needed behind load balancers and gateways
+// expecting a host other than the one dialed. An empty host restores
+// the host of the endpoint.
+func (c *Client) SetCustomHost(host string) {
+	c.customHost = host
+}
+
+// SetRequesterPays - send 'x-amz-request-payer: requester' with all
+// the requests of the client, required to access Requester Pays
+// buckets of other accounts. The requester is charged for the
+// requests and the data transferred.
+func (c *Client) SetRequesterPays(enabled bool) {
+	if enabled {
+		c.SetCustomHeader("x-amz-request-payer", "requester")
+	} else {
+		c.SetCustomHeader("x-amz-request-payer", "")
+	}
+}
+
+// WithRequesterPays - returns a copy of the client accepting the
+// charges of Requester Pays buckets for single calls, the client
+// itself is unchanged.
+func (c Client) WithRequesterPays() Client {
+	return c.WithCustomHeader(http.Header{"X-Amz-Request-Payer": {"requester"}})
+}
+
+// SetExpectedBucketOwner - send 'x-amz-expected-bucket-owner' with all
+// the requests of the client, Amazon S3 then denies requests to buckets
+// not owned by the account accountID. This guards against writing to or
+// reading from a bucket of the same name created by another account
+// after the expected one was deleted. An empty accountID stops sending
+// the header.
+//
+// Requests to buckets of other owners fail with ErrorResponse Code
+// 'AccessDenied'. The owner of the source bucket of copies is not
+// checked.
+func (c *Client) SetExpectedBucketOwner(accountID string) {
+	c.SetCustomHeader("x-amz-expected-bucket-owner", accountID)
+}
+
+// WithExpectedBucketOwner - returns a copy of the client expecting
+// buckets to be owned by accountID for single calls, the client itself
+// is unchanged. See SetExpectedBucketOwner.
+func (c Client) WithExpectedBucketOwner(accountID string) Client {
+	return c.WithCustomHeader(http.Header{"X-Amz-Expected-Bucket-Owner": {accountID}})
+}
+
+// WithCustomHeader - returns a copy of the client setting header on
+// all its requests in addition to the custom headers of the client,
+// the client itself is unchanged. Meant for single calls, e.g.
+//
+//   api.WithCustomHeader(http.Header{"X-Amz-Request-Payer": {"requester"}}).GetObject(bucket, object)
+//
+func (c Client) WithCustomHeader(header http.Header) Client {
+	customHeader := cloneHeader(c.customHeader)
+	for k, v := range header {
+		customHeader[http.CanonicalHeaderKey(k)] = v
+	}
+	c.customHeader = customHeader
+	return c
+}
+
+// cloneHeader - returns a copy of header, never nil.
+func cloneHeader(header http.Header) http.Header {
+	clone := make(http.Header, len(header))
+	for k, v := range header {
+		clone[k] = append([]string(nil), v...)
+	}
+	return clone
+}
+
 // SetAppInfo - add application details to user agent.
 func (c *Client) SetAppInfo(appName string, appVersion string) {
 	// if app name and version is not set, we do not a new user
@@ -215,9 +496,176 @@ func (c *Client) SetCustomTransport(customHTTPTransport http.RoundTripper) {
 	//   }
 	//   api.SetTransport(tr)
 	//
+	// Set 'ExpectContinueTimeout' on custom transports, like
+	// ``http.DefaultTransport`` does, otherwise payloads are sent
+	// without waiting for the server to accept the request.
+	//
+	// ``http.DefaultTransport`` negotiates HTTP/2 over HTTPS, custom
+	// transports with a 'TLSClientConfig' only do so when configured
+	// with ``http2.ConfigureTransport`` from golang.org/x/net/http2.
+	// HTTP/2 multiplexes concurrent Object.ReadAt calls over a single
+	// connection.
+	//
 	if c.httpClient != nil {
 		c.httpClient.Transport = customHTTPTransport
+		// The DNS cache belonged to the previous transport.
+		c.dnsCache = nil
+	}
+}
+
+// SetConnectTimeout - set the timeout for establishing new connections,
+// defaults to 30 seconds. Zero waits as long as the operating system
+// allows, TLS handshakes are limited separately to 10 seconds.
+//
+// Transport timeouts can only be set on the default transport or on
+// an *http.Transport set with SetCustomTransport, which is modified.
+func (c *Client) SetConnectTimeout(timeout time.Duration) error {
+	if timeout < 0 {
+		return ErrInvalidArgument("Connect timeout cannot be negative.")
+	}
+	return c.configureTransport(func(tr *http.Transport) {
+		tr.DialContext = (&net.Dialer{
+			Timeout:   timeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+		// Keep caching addresses, dialing with the new timeout.
+		if c.dnsCache != nil {
+			c.dnsCache = newDNSCache(c.dnsCache.ttl, tr.DialContext)
+			tr.DialContext = c.dnsCache.DialContext
+		}
+	})
+}
+
+// SetDNSCache - cache the addresses of the endpoint for ttl, new
+// connections are then opened without a DNS lookup. Addresses are
+// looked up again once expired, or once none of them accepts a
+// connection. Zero disables the cache, the default.
+//
+// Under high request rates this saves the latency of a lookup per new
+// connection. Same as the transport timeouts, it can only be set on
+// the default transport or on an *http.Transport set with
+// SetCustomTransport.
+func (c *Client) SetDNSCache(ttl time.Duration) error {
+	if ttl < 0 {
+		return ErrInvalidArgument("DNS cache TTL cannot be negative.")
+	}
+	return c.configureTransport(func(tr *http.Transport) {
+		// Dial with the dialer of the transport, not the cache.
+		dial := dialFunc(tr.DialContext)
+		if c.dnsCache != nil {
+			dial = c.dnsCache.dial
+		}
+		if dial == nil {
+			dial = (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext
+		}
+		c.dnsCache = nil
+		tr.DialContext = dial
+		if ttl > 0 {
+			c.dnsCache = newDNSCache(ttl, dial)
+			tr.DialContext = c.dnsCache.DialContext
+		}
+	})
+}
+
+// SetIdleConnTimeout - set how long idle connections are kept open for
+// reuse, defaults to 90 seconds. Zero keeps them open indefinitely.
+func (c *Client) SetIdleConnTimeout(timeout time.Duration) error {
+	if timeout < 0 {
+		return ErrInvalidArgument("Idle connection timeout cannot be negative.")
+	}
+	return c.configureTransport(func(tr *http.Transport) {
+		tr.IdleConnTimeout = timeout
+	})
+}
+
+// SetRequestDeadline - set how long each request may take in total,
+// including reading its response body, defaults to 2 minutes. Zero
+// removes the deadline.
+//
+// The deadline applies to every request on its own, including every
+// retry and every part of a multipart upload, an operation sending
+// many requests may take much longer. To limit the total time of an
+// operation cancel it with the context of a WithContext operation such
+// as FPutObjectWithContext instead. Streams returned by GetObject are
+// cut off once their request exceeds the deadline.
+func (c *Client) SetRequestDeadline(deadline time.Duration) error {
+	if deadline < 0 {
+		return ErrInvalidArgument("Request deadline cannot be negative.")
+	}
+	c.httpClient.Timeout = deadline
+	return nil
+}
+
+// SetResponseHeaderTimeout - set how long to wait for response headers
+// once a request is sent, zero by default which waits as long as the
+// client allows. Requests are limited to 2 minutes in total by the
+// client regardless of this timeout, see SetRequestDeadline.
+func (c *Client) SetResponseHeaderTimeout(timeout time.Duration) error {
+	if timeout < 0 {
+		return ErrInvalidArgument("Response header timeout cannot be negative.")
+	}
+	return c.configureTransport(func(tr *http.Transport) {
+		tr.ResponseHeaderTimeout = timeout
+	})
+}
+
+// SetMaxIdleConnsPerHost - set how many idle connections to the
+// endpoint are kept open for reuse, defaults to 2. Under higher
+// concurrency connections beyond this are closed after each request
+// and new ones opened for the next, set it to about the number of
+// concurrent requests.
+//
+// Parts of a multipart upload are sent one after the other over a
+// single connection, concurrent requests come from concurrent calls
+// such as parallel PutObject and GetObject, StatObjects and
+// ListBucketsByRegion.
+func (c *Client) SetMaxIdleConnsPerHost(n int) error {
+	if n < 0 {
+		return ErrInvalidArgument("Maximum idle connections per host cannot be negative.")
+	}
+	return c.configureTransport(func(tr *http.Transport) {
+		tr.MaxIdleConnsPerHost = n
+		// Total limit across hosts must not be lower.
+		if tr.MaxIdleConns != 0 && tr.MaxIdleConns < n {
+			tr.MaxIdleConns = n
+		}
+	})
+}
+
+// SetTLSConfig - set the TLS configuration of connections to the
+// endpoint, for example to trust a private certificate authority
+// through 'RootCAs', or to authenticate with client certificates
+// through 'Certificates'. A nil config restores the defaults.
+//
+// The config is copied, later changes to it have no effect. Setting
+// 'InsecureSkipVerify' accepts any certificate, including those of
+// an attacker intercepting the connections, credentials and data are
+// then exposed. Only use it for testing.
+func (c *Client) SetTLSConfig(config *tls.Config) error {
+	return c.configureTransport(func(tr *http.Transport) {
+		tr.TLSClientConfig = nil
+		if config != nil {
+			tr.TLSClientConfig = config.Clone()
+		}
+	})
+}
+
+// configureTransport - applies configure to the transport of the
+// client. The default transport is shared by all clients, it is
+// replaced by a copy owned by this client first.
+func (c *Client) configureTransport(configure func(tr *http.Transport)) error {
+	if c.httpClient.Transport == http.DefaultTransport {
+		c.httpClient.Transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	tr, ok := c.httpClient.Transport.(*http.Transport)
+	if !ok {
+		return ErrInvalidArgument("Custom transport is not an *http.Transport, configure it directly.")
 	}
+	configure(tr)
+	return nil
 }
 
 // TraceOn - enable HTTP tracing.
@@ -239,6 +687,183 @@ func (c *Client) TraceOff() {
 	c.isTraceEnabled = false
 }
 
+// SetMultipartDisabled - disable multipart uploads, objects are always
+// uploaded with a single PUT operation regardless of their size.
+//
+// This is meant for S3 compatible servers with broken multipart
+// implementations, objects larger than 5GiB cannot be uploaded in this
+// mode and fail with an 'EntityTooLarge' error.
+func (c *Client) SetMultipartDisabled(disabled bool) {
+	c.isMultipartDisabled = disabled
+}
+
+// SetResumableUploads - keep the uploaded parts of failed multipart
+// uploads, the next upload of the same object only uploads the missing
+// parts. Disabled by default, failed multipart uploads are aborted and
+// their parts removed.
+//
+// Parts of abandoned uploads are stored and billed until they are
+// removed with RemoveIncompleteUpload.
+func (c *Client) SetResumableUploads(enabled bool) {
+	c.isUploadResumable = enabled
+}
+
+// SetFileSyncDisabled - disable syncing files downloaded by
+// FGetObject to disk. By default the data and the rename of the file
+// are synced before FGetObject returns, the file survives a crash
+// right after. Disabling it is faster, the operating system writes
+// the data back later.
+func (c *Client) SetFileSyncDisabled(disabled bool) {
+	c.isFileSyncDisabled = disabled
+}
+
+// SetMultipartETagVerification - verify the ETag returned for
+// completed multipart uploads, disabled by default. The ETag must be
+// 'md5(md5(part1)+md5(part2)+...)-N' calculated from the MD5 sums of
+// the uploaded data, otherwise uploads fail with a 'BadDigest' error.
+//
+// The object is already created when the mismatch is detected. Only
+// enable this for servers returning such ETags, for example Amazon S3
+// without SSE-KMS encryption.
+func (c *Client) SetMultipartETagVerification(enabled bool) {
+	c.isMultipartETagVerified = enabled
+}
+
+// SetMultipartThreshold - set the object size at which uploads switch
+// from a single PUT to multipart, defaults to 5MiB.
+//
+// Threshold must be between 5MiB and 5GiB. It only decides whether
+// multipart is used, part sizes of multipart uploads are still
+// calculated from the object size. Threshold is ignored if multipart is
+// disabled with SetMultipartDisabled.
+func (c *Client) SetMultipartThreshold(size int64) error {
+	if size < minPartSize {
+		return ErrInvalidArgument(fmt.Sprintf("Multipart threshold %d cannot be smaller than the minimum part size %d.", size, minPartSize))
+	}
+	if size > maxSinglePutObjectSize {
+		return ErrInvalidArgument(fmt.Sprintf("Multipart threshold %d cannot be larger than the maximum single PUT size %d.", size, maxSinglePutObjectSize))
+	}
+	c.multipartThreshold = size
+	return nil
+}
+
+// SetPartSize - set a fixed part size for multipart uploads, by
+// default the smallest part size keeping an upload within 10000 parts
+// is calculated from the object size. Size '0' restores the default.
+//
+// Part size must be between 5MiB and 5GiB. Uploads of a known size
+// needing more than 10000 parts of this size fail.
+func (c *Client) SetPartSize(size int64) error {
+	if size != 0 && (size < minPartSize || size > maxPartSize) {
+		return ErrInvalidArgument(fmt.Sprintf("Part size %d must be between %d and %d.", size, minPartSize, maxPartSize))
+	}
+	c.partSize = size
+	return nil
+}
+
+// SetUploadBandwidthLimit - limit the rate of all uploads of the
+// client to bytesPerSec, '0' removes the limit. The limit applies to
+// the aggregate of concurrent requests, including parallel parts of
+// multipart uploads.
+func (c *Client) SetUploadBandwidthLimit(bytesPerSec int64) error {
+	if bytesPerSec < 0 {
+		return ErrInvalidArgument(fmt.Sprintf("Upload bandwidth limit %d cannot be negative.", bytesPerSec))
+	}
+	c.uploadLimiter = nil
+	if bytesPerSec > 0 {
+		c.uploadLimiter = newBandwidthLimiter(bytesPerSec)
+	}
+	return nil
+}
+
+// SetDownloadBandwidthLimit - limit the rate of all downloads of the
+// client to bytesPerSec, '0' removes the limit. The limit applies to
+// the aggregate of concurrent requests.
+func (c *Client) SetDownloadBandwidthLimit(bytesPerSec int64) error {
+	if bytesPerSec < 0 {
+		return ErrInvalidArgument(fmt.Sprintf("Download bandwidth limit %d cannot be negative.", bytesPerSec))
+	}
+	c.downloadLimiter = nil
+	if bytesPerSec > 0 {
+		c.downloadLimiter = newBandwidthLimiter(bytesPerSec)
+	}
+	return nil
+}
+
+// SetAdaptiveThrottling - adapt the number of concurrent requests of
+// the client to the server, disabled by default.
+//
+// Once the server throttles requests with '503 SlowDown' or '429 Too
+// Many Requests', the allowed concurrent requests are halved and raised
+// by one after each 10 successful requests, until back at the
+// concurrency throttling engaged at. Throttled requests are retried
+// with backoff as usual. Requests over the limit wait, including the
+// parts uploaded in parallel by an Uploader, so that large batch jobs
+// slow down instead of failing.
+//
+// onChange, if not nil, is called with the allowed concurrent requests
+// whenever they change, '0' once the limit is lifted.
+func (c *Client) SetAdaptiveThrottling(enabled bool, onChange func(concurrency int)) {
+	c.throttle = nil
+	if enabled {
+		c.throttle = newAdaptiveThrottle(onChange)
+	}
+}
+
+// SetMetricsCollector - report the metrics of every request of the
+// client to collector, e.g. operation, duration, bytes, retries and
+// status. A nil collector, the default, stops reporting.
+func (c *Client) SetMetricsCollector(collector MetricsCollector) {
+	c.metricsCollector = collector
+}
+
+// SetReadAhead - read the objects returned by GetObject in ranges of
+// size bytes, the next range is fetched in the background while the
+// current one is read. '0' disables read-ahead, the default.
+//
+// This smooths sequential reads over high latency links, e.g. for
+// streaming media, at the cost of up to two ranges buffered in memory
+// per object. Seeking starts reading ahead from the new offset, ReadAt
+// is not affected. Objects overwritten while read fail with
+// ErrObjectChanged.
+func (c *Client) SetReadAhead(size int64) error {
+	if size < 0 {
+		return ErrInvalidArgument("Read-ahead size cannot be negative.")
+	}
+	c.readAheadSize = size
+	return nil
+}
+
+// SetListMaxKeys - set the number of objects ListObjects and
+// ListObjectVersions request per page, defaults to 1000.
+//
+// Must be between 1 and 1000. Smaller pages return the first objects
+// sooner over slow links, larger pages need fewer requests. The objects
+// sent on the channel are the same either way.
+func (c *Client) SetListMaxKeys(n int) error {
+	if n < 1 || n > 1000 {
+		return ErrInvalidArgument(fmt.Sprintf("List max keys %d must be between 1 and 1000.", n))
+	}
+	c.listMaxKeys = n
+	return nil
+}
+
+// SetObjectNameNormalization - enable normalization of object names,
+// disabled by default to address objects exactly by the given name.
+//
+// When enabled, leading and duplicate slashes are removed and '.' and
+// '..' segments are resolved before a request is sent, e.g.
+// '/foo/../bar//baz' addresses 'bar/baz'. A trailing slash is kept.
+// Names which resolve to nothing fail with an invalid object name
+// error.
+//
+// Either way object names are percent encoded the same way for every
+// operation and for presigned URLs, a name always addresses the same
+// object.
+func (c *Client) SetObjectNameNormalization(enabled bool) {
+	c.isObjectNameNormalized = enabled
+}
+
 // requestMetadata - is container for all the values to make a
 // request.
 type requestMetadata struct {
@@ -384,6 +1009,15 @@ func (c Client) do(req *http.Request) (*http.Response, error) {
 		return nil, ErrInvalidArgument(msg)
 	}
 
+	// Refresh the server clock offset periodically if signing time
+	// is adjusted.
+	if c.clockSkew.IsEnabled() && c.clockSkew.IsStale() {
+		c.clockSkew.Update(resp, time.Now().UTC())
+	}
+
+	// Detect the type of server from its first responses.
+	c.serverType.Update(resp)
+
 	// If trace is enabled, dump http request and response.
 	if c.isTraceEnabled {
 		err = c.dumpHTTP(req, resp)
@@ -394,6 +1028,13 @@ func (c Client) do(req *http.Request) (*http.Response, error) {
 	return resp, nil
 }
 
+// doNotFollowRedirects - returns redirects to executeMethod instead of
+// following them, signatures are not valid for the redirected host.
+// Redirects to the region of a bucket are retried by executeMethod.
+func doNotFollowRedirects(req *http.Request, via []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
 // List of success status.
 var successStatus = []int{
 	http.StatusOK,
@@ -407,16 +1048,36 @@ var successStatus = []int{
 func (c Client) executeMethod(method string, metadata requestMetadata) (res *http.Response, err error) {
 	var isRetryable bool     // Indicates if request can be retried.
 	var bodySeeker io.Seeker // Extracted seeker from io.Reader.
+	var isRedirected bool    // Set once retried for the bucket region.
+	var attempts int         // Number of requests sent.
 	if metadata.contentBody != nil {
 		// Check if body is seekable then it is retryable.
 		bodySeeker, isRetryable = metadata.contentBody.(io.Seeker)
 	}
 
+	// Requests are not sent once the context is done, retries stop
+	// when it is done while waiting.
+	var doneCh <-chan struct{}
+	if c.ctx != nil {
+		if err = c.ctx.Err(); err != nil {
+			return nil, err
+		}
+		doneCh = c.ctx.Done()
+	}
+
+	// Report the metrics of the request once it returns.
+	if c.metricsCollector != nil {
+		start := time.Now()
+		defer func() {
+			observeRequest(c.metricsCollector, method, metadata, start, attempts, res, err)
+		}()
+	}
+
 	// Retry executes the following function body if request has an
 	// error until maxRetries have been exhausted, retry attempts are
 	// performed after waiting for a given period of time in a
 	// binomial fashion.
-	for range c.newRetryTimer(MaxRetry, time.Second, time.Second*30, MaxJitter) {
+	for range c.newRetryTimer(MaxRetry, time.Second, time.Second*30, MaxJitter, doneCh) {
 		if isRetryable {
 			// Seek back to beginning for each attempt.
 			if _, err = bodySeeker.Seek(0, 0); err != nil {
@@ -425,6 +1086,8 @@ func (c Client) executeMethod(method string, metadata requestMetadata) (res *htt
 			}
 		}
 
+		attempts++
+
 		// Instantiate a new request.
 		var req *http.Request
 		req, err = c.newRequest(method, metadata)
@@ -435,10 +1098,27 @@ func (c Client) executeMethod(method string, metadata requestMetadata) (res *htt
 			}
 			return nil, err
 		}
+		if c.ctx != nil {
+			req = req.WithContext(c.ctx)
+		}
 
-		// Initiate the request.
-		res, err = c.do(req)
+		// Initiate the request, once the server allows it.
+		if c.throttle != nil {
+			generation := c.throttle.Acquire()
+			res, err = c.do(req)
+			var statusCode int
+			if err == nil {
+				statusCode = res.StatusCode
+			}
+			c.throttle.Release(generation, statusCode)
+		} else {
+			res, err = c.do(req)
+		}
 		if err != nil {
+			// Request cancelled by the context.
+			if c.ctx != nil && c.ctx.Err() != nil {
+				return nil, c.ctx.Err()
+			}
 			// For supported network errors verify.
 			if isNetErrorRetryable(err) {
 				continue // Retry.
@@ -450,6 +1130,12 @@ func (c Client) executeMethod(method string, metadata requestMetadata) (res *htt
 		// For any known successful http status, return quickly.
 		for _, httpStatus := range successStatus {
 			if httpStatus == res.StatusCode {
+				if c.downloadLimiter != nil {
+					res.Body = limitedReadCloser{
+						Reader: newLimitedReader(res.Body, c.downloadLimiter),
+						Closer: res.Body,
+					}
+				}
 				return res, nil
 			}
 		}
@@ -459,12 +1145,62 @@ func (c Client) executeMethod(method string, metadata requestMetadata) (res *htt
 			continue // Retry.
 		}
 
+		// Read the body to be saved later.
+		errBodyBytes, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		// Let the custom parser normalize the error body, saved as a
+		// standard error response for the callers to parse.
+		if c.errorResponseParser != nil {
+			errResponse := c.errorResponseParser(res.StatusCode, res.Header, errBodyBytes)
+			if errResponse.Code != "" {
+				if errBodyBytes, err = xml.Marshal(errResponse); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		// Save the body.
+		errBodySeeker := bytes.NewReader(errBodyBytes)
+		res.Body = ioutil.NopCloser(errBodySeeker)
+
 		// For errors verify if its retryable otherwise fail quickly.
 		errResponse := ToErrorResponse(httpRespToErrorResponse(res, metadata.bucketName, metadata.objectName))
-		// Bucket region if set in error response, we can retry the
-		// request with the new region.
-		if errResponse.Region != "" {
-			c.bucketLocCache.Set(metadata.bucketName, errResponse.Region)
+
+		// Save the body back again.
+		errBodySeeker.Seek(0, 0) // Seek back to starting point.
+		res.Body = ioutil.NopCloser(errBodySeeker)
+		// Bucket region if set in error response, for example on
+		// '301 Moved Permanently' and '307 Temporary Redirect' to
+		// the region of the bucket, differs from the region the
+		// request was sent to. Save it and retry the request with
+		// the new region, only once to never loop between regions.
+		if errResponse.Region != "" && metadata.bucketName != "" && !isRedirected {
+			location, ok := c.bucketLocCache.Get(metadata.bucketName)
+			if !ok {
+				location = "us-east-1"
+			}
+			if location != errResponse.Region {
+				c.bucketLocCache.Set(metadata.bucketName, errResponse.Region)
+				isRedirected = true
+				continue // Retry.
+			}
+		}
+
+		// Server rejected the signing time, measure the server clock
+		// offset again and retry the request with an adjusted time.
+		if errResponse.Code == "RequestTimeTooSkewed" && c.clockSkew.IsEnabled() {
+			if c.clockSkew.Update(res, time.Now().UTC()) == nil {
+				continue // Retry.
+			}
+		}
+
+		// Temporary credentials expired before their expiration,
+		// retrieve them again and retry the request.
+		if errResponse.Code == "ExpiredToken" && c.credentials != nil {
+			c.credentials.Expire()
 			continue // Retry.
 		}
 
@@ -481,9 +1217,21 @@ func (c Client) executeMethod(method string, metadata requestMetadata) (res *htt
 		// For all other cases break out of the retry loop.
 		break
 	}
+	// Retries stopped by the context.
+	if c.ctx != nil && c.ctx.Err() != nil {
+		closeResponse(res)
+		return nil, c.ctx.Err()
+	}
 	return res, err
 }
 
+// withContext - returns a copy of the client cancelling its requests
+// once ctx is done, the client itself is unchanged.
+func (c Client) withContext(ctx context.Context) Client {
+	c.ctx = ctx
+	return c
+}
+
 // newRequest - instantiate a new HTTP request for a given method.
 func (c Client) newRequest(method string, metadata requestMetadata) (req *http.Request, err error) {
 	// If no method is supplied default to 'POST'.
@@ -491,6 +1239,15 @@ func (c Client) newRequest(method string, metadata requestMetadata) (req *http.R
 		method = "POST"
 	}
 
+	// Normalize object name if enabled, a name resolving to nothing
+	// would address the bucket instead.
+	if c.isObjectNameNormalized && metadata.objectName != "" {
+		metadata.objectName = normalizeObjectName(metadata.objectName)
+		if metadata.objectName == "" {
+			return nil, ErrInvalidObjectName("Object name cannot be empty after normalization.")
+		}
+	}
+
 	// Gather location only if bucketName is present.
 	location := "us-east-1" // Default all other requests to "us-east-1".
 	if metadata.bucketName != "" {
@@ -515,32 +1272,58 @@ func (c Client) newRequest(method string, metadata requestMetadata) (req *http.R
 		return nil, err
 	}
 
+	// Get credentials, retrieved again if expired.
+	accessKeyID, secretAccessKey, sessionToken, err := c.getCredentials()
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate presign url if needed, return right here.
 	if metadata.expires != 0 && metadata.presignURL {
 		if c.anonymous {
 			return nil, ErrInvalidArgument("Requests cannot be presigned with anonymous credentials.")
 		}
+		// Set all headers to be signed.
+		for k, v := range metadata.customHeader {
+			req.Header.Set(k, v[0])
+		}
 		if c.signature.isV2() {
 			// Presign URL with signature v2.
-			req = preSignV2(*req, c.accessKeyID, c.secretAccessKey, metadata.expires)
+			req = preSignV2(*req, accessKeyID, secretAccessKey, sessionToken, metadata.expires)
 		} else {
 			// Presign URL with signature v4.
-			req = preSignV4(*req, c.accessKeyID, c.secretAccessKey, location, metadata.expires)
+			req = preSignV4(*req, accessKeyID, secretAccessKey, sessionToken, location, metadata.expires)
 		}
 		return req, nil
 	}
 
+	// Empty payloads are sent without a body, otherwise the request
+	// would be sent with chunked transfer encoding instead of
+	// 'Content-Length: 0'.
+	if metadata.contentLength == 0 {
+		metadata.contentBody = nil
+	}
+
 	// Set content body if available.
 	if metadata.contentBody != nil {
-		req.Body = ioutil.NopCloser(metadata.contentBody)
+		req.Body = ioutil.NopCloser(newLimitedReader(metadata.contentBody, c.uploadLimiter))
 	}
 
-	// set 'Expect' header for the request.
-	req.Header.Set("Expect", "100-continue")
+	// set 'Expect' header for requests with a payload, the payload is
+	// only sent once the server accepts the request with '100
+	// Continue'. Requests rejected upfront, for example for failed
+	// authentication or a missing bucket, return the error response
+	// without sending any payload.
+	if metadata.contentLength > 0 {
+		req.Header.Set("Expect", "100-continue")
+	}
 
 	// set 'User-Agent' header for the request.
 	c.setUserAgent(req)
 
+	// Set custom headers of the client.
+	c.setCustomHeader(req)
+
 	// Set all headers.
 	for k, v := range metadata.customHeader {
 		req.Header.Set(k, v[0])
@@ -559,6 +1342,10 @@ func (c Client) newRequest(method string, metadata requestMetadata) (req *http.R
 			req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sum256([]byte{})))
 			if metadata.contentSHA256Bytes != nil {
 				req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(metadata.contentSHA256Bytes))
+			} else if metadata.contentBody != nil {
+				// Payload streamed without computing its sha256 sum
+				// is sent unsigned.
+				req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
 			}
 		}
 	}
@@ -570,12 +1357,14 @@ func (c Client) newRequest(method string, metadata requestMetadata) (req *http.R
 
 	// Sign the request for all authenticated requests.
 	if !c.anonymous {
+		// Adjust signing time for server clock offset if enabled.
+		c.setSigningTime(req)
 		if c.signature.isV2() {
 			// Add signature version '2' authorization header.
-			req = signV2(*req, c.accessKeyID, c.secretAccessKey)
+			req = signV2(*req, accessKeyID, secretAccessKey, sessionToken)
 		} else if c.signature.isV4() {
 			// Add signature version '4' authorization header.
-			req = signV4(*req, c.accessKeyID, c.secretAccessKey, location)
+			req = signV4(*req, accessKeyID, secretAccessKey, sessionToken, location)
 		}
 	}
 
@@ -583,6 +1372,70 @@ func (c Client) newRequest(method string, metadata requestMetadata) (req *http.R
 	return req, nil
 }
 
+// SignV4Request - signs a request built by the caller with signature
+// version '4' for the given location, empty location signs for
+// 'us-east-1'. Use this to send requests for operations not provided
+// by this library with a transport of your own.
+//
+// The request body must be set before signing since its sha256 sum is
+// signed. Unless X-Amz-Content-Sha256 is already set, the body is read
+// into memory to compute the sum and replaced by the buffered copy.
+func (c Client) SignV4Request(req *http.Request, location string) error {
+	if location == "" {
+		location = "us-east-1"
+	}
+	return c.signRequest(req, SignatureV4, location)
+}
+
+// SignV2Request - signs a request built by the caller with signature
+// version '2'. Use this to send requests for operations not provided
+// by this library with a transport of your own.
+func (c Client) SignV2Request(req *http.Request) error {
+	return c.signRequest(req, SignatureV2, "")
+}
+
+// signRequest - signs req in place with the signature type, clock
+// skew correction is applied if enabled.
+func (c Client) signRequest(req *http.Request, signature SignatureType, location string) error {
+	if req == nil {
+		return ErrInvalidArgument("Request cannot be empty.")
+	}
+	// Get credentials, retrieved again if expired.
+	accessKeyID, secretAccessKey, sessionToken, err := c.getCredentials()
+	if err != nil {
+		return err
+	}
+	if c.anonymous || accessKeyID == "" || secretAccessKey == "" {
+		return ErrInvalidArgument("Requests cannot be signed with anonymous credentials.")
+	}
+
+	// Adjust signing time for server clock offset if enabled.
+	c.signature = signature
+	c.setSigningTime(req)
+
+	if signature.isV2() {
+		*req = *signV2(*req, accessKeyID, secretAccessKey, sessionToken)
+		return nil
+	}
+
+	// Compute sha256 sum of the payload if not set by the caller.
+	if req.Header.Get("X-Amz-Content-Sha256") == "" {
+		var payload []byte
+		if req.Body != nil {
+			payload, err = ioutil.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return err
+			}
+			req.Body = ioutil.NopCloser(bytes.NewReader(payload))
+			req.ContentLength = int64(len(payload))
+		}
+		req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sum256(payload)))
+	}
+	*req = *signV4(*req, accessKeyID, secretAccessKey, sessionToken, location)
+	return nil
+}
+
 // set User agent.
 func (c Client) setUserAgent(req *http.Request) {
 	req.Header.Set("User-Agent", libraryUserAgent)
@@ -591,6 +1444,16 @@ func (c Client) setUserAgent(req *http.Request) {
 	}
 }
 
+// setCustomHeader - set custom headers and host of the client.
+func (c Client) setCustomHeader(req *http.Request) {
+	for k, v := range c.customHeader {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	if c.customHost != "" {
+		req.Host = c.customHost
+	}
+}
+
 // makeTargetURL make a new target url.
 func (c Client) makeTargetURL(bucketName, objectName, bucketLocation string, queryValues url.Values) (*url.URL, error) {
 	// Save host.
@@ -608,7 +1471,8 @@ func (c Client) makeTargetURL(bucketName, objectName, bucketLocation string, que
 	// endpoint URL.
 	if bucketName != "" {
 		// Save if target url will have buckets which suppport virtual host.
-		isVirtualHostStyle := isVirtualHostSupported(c.endpointURL, bucketName)
+		// Google Cloud Storage compatibility mode always uses path style.
+		isVirtualHostStyle := isVirtualHostSupported(c.endpointURL, bucketName) && !c.isGCSCompatible
 
 		// If endpoint supports virtual host style use that always.
 		// Currently only S3 and Google Cloud Storage would support
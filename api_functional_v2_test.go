@@ -149,8 +149,8 @@ func TestGetObjectClosedTwiceV2(t *testing.T) {
 	if err := r.Close(); err != nil {
 		t.Fatal("Error:", err)
 	}
-	if err := r.Close(); err == nil {
-		t.Fatal("Error: object is already closed, should return error")
+	if err := r.Close(); err != minio.ErrAlreadyClosed {
+		t.Fatal("Error: object is already closed, should return ErrAlreadyClosed, got", err)
 	}
 
 	err = c.RemoveObject(bucketName, objectName)
@@ -219,9 +219,11 @@ func TestRemovePartiallyUploadedV2(t *testing.T) {
 	if err.Error() != "Proactively closed to be verified later." {
 		t.Fatal("Error:", err)
 	}
-	err = c.RemoveIncompleteUpload(bucketName, objectName)
-	if err != nil {
-		t.Fatal("Error:", err)
+	// Failed upload must be aborted.
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	for upload := range c.ListIncompleteUploads(bucketName, objectName, false, doneCh) {
+		t.Fatal("Error: failed upload was not aborted", upload)
 	}
 	err = c.RemoveBucket(bucketName)
 	if err != nil {
@@ -467,6 +469,15 @@ func TestMakeBucketRegionsV2(t *testing.T) {
 	if err = c.RemoveBucket(bucketName + ".withperiod"); err != nil {
 		t.Fatal("Error:", err, bucketName+".withperiod")
 	}
+
+	// Make a new bucket with an empty region, created in 'us-east-1'.
+	if err = c.MakeBucket(bucketName, "private", ""); err != nil {
+		t.Fatal("Error:", err, bucketName)
+	}
+
+	if err = c.RemoveBucket(bucketName); err != nil {
+		t.Fatal("Error:", err, bucketName)
+	}
 }
 
 // Tests get object ReaderSeeker interface methods.
@@ -563,6 +574,13 @@ func TestGetObjectReadSeekFunctionalV2(t *testing.T) {
 	if err != nil {
 		t.Fatal("Error:", err)
 	}
+	if n != st.Size-offset {
+		t.Fatalf("Error: number of bytes seeked back does not match, want %v, got %v\n", st.Size-offset, n)
+	}
+	n, err = r.Seek(0, 0)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
 	if n != 0 {
 		t.Fatalf("Error: number of bytes seeked back does not match, want 0, got %v\n", n)
 	}
@@ -876,6 +894,9 @@ func TestFunctionalV2(t *testing.T) {
 	objFound := false
 	isRecursive := true // Recursive is true.
 	for obj := range c.ListObjects(bucketName, objectName, isRecursive, doneCh) {
+		if obj.Err != nil {
+			t.Fatal("Error:", obj.Err)
+		}
 		if obj.Key == objectName {
 			objFound = true
 			break
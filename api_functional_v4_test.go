@@ -97,6 +97,10 @@ func TestMakeBucketError(t *testing.T) {
 		minio.ToErrorResponse(err).Code != "BucketAlreadyOwnedByYou" {
 		t.Fatal("Error: Invalid error returned by server", err)
 	}
+	// Verify existing bucket is accepted when owned by us.
+	if err = c.MakeBucketIfNotExists(bucketName, "private", "eu-central-1"); err != nil {
+		t.Fatal("Error:", err, bucketName)
+	}
 	if err = c.RemoveBucket(bucketName); err != nil {
 		t.Fatal("Error:", err, bucketName)
 	}
@@ -151,6 +155,15 @@ func TestMakeBucketRegions(t *testing.T) {
 	if err = c.RemoveBucket(bucketName + ".withperiod"); err != nil {
 		t.Fatal("Error:", err, bucketName+".withperiod")
 	}
+
+	// Make a new bucket with an empty region, created in 'us-east-1'.
+	if err = c.MakeBucket(bucketName, "private", ""); err != nil {
+		t.Fatal("Error:", err, bucketName)
+	}
+
+	if err = c.RemoveBucket(bucketName); err != nil {
+		t.Fatal("Error:", err, bucketName)
+	}
 }
 
 // Test get object reader to not throw error on being closed twice.
@@ -224,8 +237,8 @@ func TestGetObjectClosedTwice(t *testing.T) {
 	if err := r.Close(); err != nil {
 		t.Fatal("Error:", err)
 	}
-	if err := r.Close(); err == nil {
-		t.Fatal("Error: object is already closed, should return error")
+	if err := r.Close(); err != minio.ErrAlreadyClosed {
+		t.Fatal("Error: object is already closed, should return ErrAlreadyClosed, got", err)
 	}
 
 	err = c.RemoveObject(bucketName, objectName)
@@ -297,9 +310,11 @@ func TestRemovePartiallyUploaded(t *testing.T) {
 	if err.Error() != "Proactively closed to be verified later." {
 		t.Fatal("Error:", err)
 	}
-	err = c.RemoveIncompleteUpload(bucketName, objectName)
-	if err != nil {
-		t.Fatal("Error:", err)
+	// Failed upload must be aborted.
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	for upload := range c.ListIncompleteUploads(bucketName, objectName, false, doneCh) {
+		t.Fatal("Error: failed upload was not aborted", upload)
 	}
 	err = c.RemoveBucket(bucketName)
 	if err != nil {
@@ -592,6 +607,13 @@ func TestGetObjectReadSeekFunctional(t *testing.T) {
 	if err != nil {
 		t.Fatal("Error:", err)
 	}
+	if n != st.Size-offset {
+		t.Fatalf("Error: number of bytes seeked back does not match, want %v, got %v\n", st.Size-offset, n)
+	}
+	n, err = r.Seek(0, 0)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
 	if n != 0 {
 		t.Fatalf("Error: number of bytes seeked back does not match, want 0, got %v\n", n)
 	}
@@ -778,6 +800,71 @@ func TestGetObjectReadAtFunctional(t *testing.T) {
 	}
 }
 
+// Tests reading from a Requester Pays bucket of another account, set
+// with REQUESTER_PAYS_BUCKET.
+func TestRequesterPaysFunctional(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping functional tests for short runs")
+	}
+	bucketName := os.Getenv("REQUESTER_PAYS_BUCKET")
+	if bucketName == "" {
+		t.Skip("skipping requester pays tests, REQUESTER_PAYS_BUCKET is not set")
+	}
+
+	// Instantiate new minio client object.
+	c, err := minio.New(
+		"s3.amazonaws.com",
+		os.Getenv("ACCESS_KEY"),
+		os.Getenv("SECRET_KEY"),
+		false,
+	)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	// Set user agent.
+	c.SetAppInfo("Minio-go-FunctionalTest", "0.1.0")
+
+	// Requests without accepting the charges are denied.
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	for objInfo := range c.ListObjects(bucketName, "", false, doneCh) {
+		if minio.ToErrorResponse(objInfo.Err).Code != "AccessDenied" {
+			t.Fatal("Error: expected AccessDenied, got", objInfo.Err)
+		}
+		break
+	}
+
+	c.SetRequesterPays(true)
+	var objectName string
+	for objInfo := range c.ListObjects(bucketName, "", true, doneCh) {
+		if objInfo.Err != nil {
+			t.Fatal("Error:", objInfo.Err, bucketName)
+		}
+		objectName = objInfo.Key
+		break
+	}
+	if objectName == "" {
+		t.Skip("skipping requester pays tests, bucket is empty")
+	}
+	objInfo, err := c.StatObject(bucketName, objectName)
+	if err != nil {
+		t.Fatal("Error:", err, bucketName, objectName)
+	}
+	reader, err := c.GetObject(bucketName, objectName)
+	if err != nil {
+		t.Fatal("Error:", err, bucketName, objectName)
+	}
+	defer reader.Close()
+	n, err := io.Copy(ioutil.Discard, reader)
+	if err != nil {
+		t.Fatal("Error:", err, bucketName, objectName)
+	}
+	if n != objInfo.Size {
+		t.Fatalf("Error: read %d bytes, expected %d", n, objInfo.Size)
+	}
+}
+
 // Tests comprehensive list of all methods.
 func TestFunctional(t *testing.T) {
 	if testing.Short() {
@@ -905,6 +992,9 @@ func TestFunctional(t *testing.T) {
 	objFound := false
 	isRecursive := true // Recursive is true.
 	for obj := range c.ListObjects(bucketName, objectName, isRecursive, doneCh) {
+		if obj.Err != nil {
+			t.Fatal("Error:", obj.Err)
+		}
 		if obj.Key == objectName {
 			objFound = true
 			break
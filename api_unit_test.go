@@ -18,14 +18,37 @@ package minio
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"io/ioutil"
+	"log"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type customReader struct{}
@@ -83,7 +106,7 @@ func TestGetReaderSize(t *testing.T) {
 	doneCh := make(chan struct{})
 	// objectInfo.
 	objectInfo := ObjectInfo{Size: 10}
-	objectReader := newObject(reqCh, resCh, doneCh, objectInfo)
+	objectReader := newObject("bucket", reqCh, resCh, doneCh, objectInfo, nil, nil)
 	defer objectReader.Close()
 
 	size, err = getReaderSize(objectReader)
@@ -172,6 +195,13 @@ func TestBucketNames(t *testing.T) {
 		{"my", ErrInvalidBucketName("Bucket name cannot be smaller than 3 characters.")},
 		{"", ErrInvalidBucketName("Bucket name cannot be empty.")},
 		{"my..bucket", ErrInvalidBucketName("Bucket name cannot have successive periods.")},
+		{"my.-bucket", ErrInvalidBucketName("Bucket name cannot have a '-' dash next to a '.' dot.")},
+		{"my-.bucket", ErrInvalidBucketName("Bucket name cannot have a '-' dash next to a '.' dot.")},
+		{"MyBucket", ErrInvalidBucketName("Bucket name contains invalid characters.")},
+		{"my_bucket", ErrInvalidBucketName("Bucket name contains invalid characters.")},
+		{strings.Repeat("a", 64), ErrInvalidBucketName("Bucket name cannot be greater than 63 characters.")},
+		{"192.168.5.4", ErrInvalidBucketName("Bucket name cannot be an IP address.")},
+		{"192.168.5.bucket", nil},
 		{"my.bucket.com", nil},
 		{"my-bucket", nil},
 		{"123my-bucket", nil},
@@ -185,6 +215,42 @@ func TestBucketNames(t *testing.T) {
 	}
 }
 
+// Tests valid object names.
+func TestObjectNames(t *testing.T) {
+	objects := []struct {
+		name  string
+		valid error
+	}{
+		{"", ErrInvalidObjectName("Object name cannot be empty.")},
+		{" ", ErrInvalidObjectName("Object name cannot be empty.")},
+		{strings.Repeat("a", 1024), nil},
+		{strings.Repeat("é", 512), nil},
+		{strings.Repeat("é", 513), ErrInvalidObjectName("Object name cannot be greater than 1024 bytes in UTF-8.")},
+		{"invalid\xff", ErrInvalidObjectName("Object name with non UTF-8 strings are not supported.")},
+		{"dir/object name+é", nil},
+	}
+	for i, o := range objects {
+		if err := isValidObjectName(o.name); err != o.valid {
+			t.Fatalf("Test %d: Error: %v", i+1, err)
+		}
+	}
+
+	// Invalid names fail before any request is sent.
+	c, err := New("localhost:1", "", "", false)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.MakeBucket("My_Bucket", "private", ""); ToErrorResponse(err).Code != "InvalidBucketName" {
+		t.Fatalf("Error: expected InvalidBucketName, got %v", err)
+	}
+	if _, err = c.PutObject("bucket", "", strings.NewReader("data"), ""); ToErrorResponse(err).Message != "Object name cannot be empty." {
+		t.Fatalf("Error: expected invalid object name, got %v", err)
+	}
+	if _, err = c.GetObject("192.168.5.4", "object"); ToErrorResponse(err).Code != "InvalidBucketName" {
+		t.Fatalf("Error: expected InvalidBucketName, got %v", err)
+	}
+}
+
 // Tests temp file.
 func TestTempFile(t *testing.T) {
 	tmpFile, err := newTempFile("testing")
@@ -280,42 +346,42 @@ func TestSignatureCalculation(t *testing.T) {
 	if err != nil {
 		t.Fatal("Error:", err)
 	}
-	req = signV4(*req, "", "", "us-east-1")
+	req = signV4(*req, "", "", "", "us-east-1")
 	if req.Header.Get("Authorization") != "" {
 		t.Fatal("Error: anonymous credentials should not have Authorization header.")
 	}
 
-	req = preSignV4(*req, "", "", "us-east-1", 0)
+	req = preSignV4(*req, "", "", "", "us-east-1", 0)
 	if strings.Contains(req.URL.RawQuery, "X-Amz-Signature") {
 		t.Fatal("Error: anonymous credentials should not have Signature query resource.")
 	}
 
-	req = signV2(*req, "", "")
+	req = signV2(*req, "", "", "")
 	if req.Header.Get("Authorization") != "" {
 		t.Fatal("Error: anonymous credentials should not have Authorization header.")
 	}
 
-	req = preSignV2(*req, "", "", 0)
+	req = preSignV2(*req, "", "", "", 0)
 	if strings.Contains(req.URL.RawQuery, "Signature") {
 		t.Fatal("Error: anonymous credentials should not have Signature query resource.")
 	}
 
-	req = signV4(*req, "ACCESS-KEY", "SECRET-KEY", "us-east-1")
+	req = signV4(*req, "ACCESS-KEY", "SECRET-KEY", "", "us-east-1")
 	if req.Header.Get("Authorization") == "" {
 		t.Fatal("Error: normal credentials should have Authorization header.")
 	}
 
-	req = preSignV4(*req, "ACCESS-KEY", "SECRET-KEY", "us-east-1", 0)
+	req = preSignV4(*req, "ACCESS-KEY", "SECRET-KEY", "", "us-east-1", 0)
 	if !strings.Contains(req.URL.RawQuery, "X-Amz-Signature") {
 		t.Fatal("Error: normal credentials should have Signature query resource.")
 	}
 
-	req = signV2(*req, "ACCESS-KEY", "SECRET-KEY")
+	req = signV2(*req, "ACCESS-KEY", "SECRET-KEY", "")
 	if req.Header.Get("Authorization") == "" {
 		t.Fatal("Error: normal credentials should have Authorization header.")
 	}
 
-	req = preSignV2(*req, "ACCESS-KEY", "SECRET-KEY", 0)
+	req = preSignV2(*req, "ACCESS-KEY", "SECRET-KEY", "", 0)
 	if !strings.Contains(req.URL.RawQuery, "Signature") {
 		t.Fatal("Error: normal credentials should not have Signature query resource.")
 	}
@@ -397,6 +463,71 @@ func TestPartSize(t *testing.T) {
 	}
 }
 
+// Tests part info of uploads for common object sizes, with optimal
+// and fixed part sizes.
+func TestPartInfo(t *testing.T) {
+	testCases := []struct {
+		objectSize      int64
+		fixedPartSize   int64
+		totalPartsCount int
+		partSize        int64
+		lastPartSize    int64
+		shouldPass      bool
+	}{
+		// Optimal part sizes.
+		{0, 0, 1, minPartSize, 0, true},
+		{minPartSize, 0, 1, minPartSize, minPartSize, true},
+		{maxPartsCount*minPartSize + 1, 0, 5001, 2 * minPartSize, 1, true},
+		{50 * 1024 * 1024 * 1024, 0, 5120, 2 * minPartSize, 2 * minPartSize, true},
+		{maxMultipartPutObjectSize, 0, 9987, 550502400, 241172480, true},
+		{maxMultipartPutObjectSize + 1, 0, 0, 0, 0, false},
+		// Fixed part sizes.
+		{0, 64 * 1024 * 1024, 1, 64 * 1024 * 1024, 0, true},
+		{50 * 1024 * 1024 * 1024, 64 * 1024 * 1024, 800, 64 * 1024 * 1024, 64 * 1024 * 1024, true},
+		{50*1024*1024*1024 + 1, 64 * 1024 * 1024, 801, 64 * 1024 * 1024, 1, true},
+		{-1, 64 * 1024 * 1024, maxPartsCount, 64 * 1024 * 1024, 64 * 1024 * 1024, true},
+		{maxMultipartPutObjectSize, minPartSize, 0, 0, 0, false},
+	}
+	for i, testCase := range testCases {
+		c, err := New("localhost:9000", "", "", true)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if err = c.SetPartSize(testCase.fixedPartSize); err != nil {
+			t.Fatal("Error:", err)
+		}
+		totalPartsCount, partSize, lastPartSize, err := c.partInfo(testCase.objectSize)
+		if err != nil && testCase.shouldPass {
+			t.Fatalf("Test %d: unexpected error %v", i+1, err)
+		}
+		if err == nil && !testCase.shouldPass {
+			t.Fatalf("Test %d: expected to fail", i+1)
+		}
+		if !testCase.shouldPass {
+			continue
+		}
+		if totalPartsCount != testCase.totalPartsCount || partSize != testCase.partSize || lastPartSize != testCase.lastPartSize {
+			t.Fatalf("Test %d: expected %d parts of %d bytes, last part %d bytes, got %d parts of %d bytes, last part %d bytes",
+				i+1, testCase.totalPartsCount, testCase.partSize, testCase.lastPartSize, totalPartsCount, partSize, lastPartSize)
+		}
+		if totalPartsCount > maxPartsCount {
+			t.Fatalf("Test %d: %d parts are more than the maximum", i+1, totalPartsCount)
+		}
+	}
+
+	// Part size must be within limits.
+	c, err := New("localhost:9000", "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SetPartSize(minPartSize - 1); err == nil {
+		t.Fatal("Error: part size below minimum should fail")
+	}
+	if err = c.SetPartSize(maxPartSize + 1); err == nil {
+		t.Fatal("Error: part size above maximum should fail")
+	}
+}
+
 // Tests query values to URL encoding.
 func TestQueryURLEncoding(t *testing.T) {
 	urlValues := make(url.Values)
@@ -597,3 +728,6847 @@ func TestValidEndpointURL(t *testing.T) {
 		}
 	}
 }
+
+// Tests object tags validation.
+func TestObjectTags(t *testing.T) {
+	testCases := []struct {
+		tags       map[string]string
+		shouldPass bool
+	}{
+		{map[string]string{}, true},
+		{map[string]string{"project": "minio", "cost-center": "1234"}, true},
+		{map[string]string{"": "value"}, false},
+		{map[string]string{strings.Repeat("k", 129): "value"}, false},
+		{map[string]string{"key": strings.Repeat("v", 257)}, false},
+		{map[string]string{"1": "", "2": "", "3": "", "4": "", "5": "", "6": "", "7": "", "8": "", "9": "", "10": "", "11": ""}, false},
+	}
+	for i, testCase := range testCases {
+		err := isValidObjectTags(testCase.tags)
+		if err != nil && testCase.shouldPass {
+			t.Errorf("Test %d: Expected to pass, but failed with: <ERROR> %s", i+1, err.Error())
+		}
+		if err == nil && !testCase.shouldPass {
+			t.Errorf("Test %d: Expected to fail, but passed", i+1)
+		}
+	}
+
+	// Verify tags survive a round trip through the tagging container.
+	tags := map[string]string{"project": "minio", "cost-center": "1234"}
+	gotTags := newTagging(tags).toMap()
+	if len(gotTags) != len(tags) {
+		t.Fatalf("Error: expected %d tags, got %d", len(tags), len(gotTags))
+	}
+	for key, value := range tags {
+		if gotTags[key] != value {
+			t.Fatalf("Error: expected tag %s=%s, got %s", key, value, gotTags[key])
+		}
+	}
+}
+
+// Tests bucket lifecycle validation and marshaling.
+func TestBucketLifecycle(t *testing.T) {
+	lifecycle := BucketLifecycle{
+		Rules: []LifecycleRule{{
+			ID:     "abort-incomplete-uploads",
+			Status: LifecycleEnabled,
+			AbortIncompleteMultipartUpload: &AbortIncompleteMultipartUpload{
+				DaysAfterInitiation: 1,
+			},
+		}},
+	}
+	if err := isValidBucketLifecycle(lifecycle); err != nil {
+		t.Fatal("Error:", err)
+	}
+	lifecycleBytes, err := xml.Marshal(lifecycle)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	want := "<LifecycleConfiguration><Rule><ID>abort-incomplete-uploads</ID><Prefix></Prefix><Status>Enabled</Status><AbortIncompleteMultipartUpload><DaysAfterInitiation>1</DaysAfterInitiation></AbortIncompleteMultipartUpload></Rule></LifecycleConfiguration>"
+	if string(lifecycleBytes) != want {
+		t.Fatalf("Error: expected %s, got %s", want, string(lifecycleBytes))
+	}
+
+	// Rule without any action should fail.
+	lifecycle.Rules[0].AbortIncompleteMultipartUpload = nil
+	if err = isValidBucketLifecycle(lifecycle); err == nil {
+		t.Fatal("Error: should fail")
+	}
+	// Expiration should have either days or date.
+	lifecycle.Rules[0].Expiration = &LifecycleExpiration{}
+	if err = isValidBucketLifecycle(lifecycle); err == nil {
+		t.Fatal("Error: should fail")
+	}
+	lifecycle.Rules[0].Expiration.Days = 30
+	if err = isValidBucketLifecycle(lifecycle); err != nil {
+		t.Fatal("Error:", err)
+	}
+	// Invalid status should fail.
+	lifecycle.Rules[0].Status = "enabled"
+	if err = isValidBucketLifecycle(lifecycle); err == nil {
+		t.Fatal("Error: should fail")
+	}
+}
+
+// Tests bucket CORS validation and configuration round trip.
+func TestBucketCORS(t *testing.T) {
+	cors := BucketCORS{
+		Rules: []CORSRule{{
+			AllowedOrigins: []string{"https://*.example.com"},
+			AllowedMethods: []string{"PUT", "POST"},
+			AllowedHeaders: []string{"*"},
+			ExposeHeaders:  []string{"ETag"},
+			MaxAgeSeconds:  3000,
+		}},
+	}
+	want := "<CORSConfiguration><CORSRule><AllowedOrigin>https://*.example.com</AllowedOrigin><AllowedMethod>PUT</AllowedMethod><AllowedMethod>POST</AllowedMethod><AllowedHeader>*</AllowedHeader><ExposeHeader>ETag</ExposeHeader><MaxAgeSeconds>3000</MaxAgeSeconds></CORSRule></CORSConfiguration>"
+
+	var corsXML string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["cors"]; !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.Method {
+		case "PUT":
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil || r.Header.Get("Content-Md5") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			corsXML = string(body)
+		case "DELETE":
+			corsXML = ""
+			w.WriteHeader(http.StatusNoContent)
+		case "GET":
+			if corsXML == "" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `<Error><Code>NoSuchCORSConfiguration</Code><Message>The CORS configuration does not exist</Message></Error>`)
+				return
+			}
+			fmt.Fprint(w, corsXML)
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	if err = c.SetBucketCORS("bucket", cors); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if corsXML != want {
+		t.Fatalf("Error: expected %s, got %s", want, corsXML)
+	}
+	gotCORS, err := c.GetBucketCORS("bucket")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	gotCORS.XMLName = cors.XMLName
+	if !reflect.DeepEqual(gotCORS, cors) {
+		t.Fatalf("Error: expected %#v, got %#v", cors, gotCORS)
+	}
+	if err = c.RemoveBucketCORS("bucket"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if _, err = c.GetBucketCORS("bucket"); ToErrorResponse(err).Code != "NoSuchCORSConfiguration" {
+		t.Fatalf("Error: expected NoSuchCORSConfiguration, got %v", err)
+	}
+
+	// Invalid configurations fail before any request.
+	testCases := []CORSRule{
+		{AllowedMethods: []string{"GET"}},
+		{AllowedOrigins: []string{"*"}},
+		{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"PATCH"}},
+		{AllowedOrigins: []string{"https://*.*.example.com"}, AllowedMethods: []string{"GET"}},
+		{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}, MaxAgeSeconds: -1},
+	}
+	for i, rule := range testCases {
+		if err = c.SetBucketCORS("bucket", BucketCORS{Rules: []CORSRule{rule}}); ToErrorResponse(err).Code != "InvalidArgument" {
+			t.Fatalf("Test %d: Error: expected InvalidArgument, got %v", i+1, err)
+		}
+	}
+	if err = c.SetBucketCORS("bucket", BucketCORS{}); err == nil {
+		t.Fatal("Error: configuration without rules should fail")
+	}
+}
+
+// Tests access control policies with explicit grants.
+func TestAccessControlPolicy(t *testing.T) {
+	policyXML := `<?xml version="1.0" encoding="UTF-8"?>
+<AccessControlPolicy xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Owner><ID>owner-id</ID><DisplayName>owner</DisplayName></Owner><AccessControlList>` +
+		`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser"><ID>owner-id</ID><DisplayName>owner</DisplayName></Grantee><Permission>FULL_CONTROL</Permission></Grant>` +
+		`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="Group"><URI>http://acs.amazonaws.com/groups/global/AllUsers</URI></Grantee><Permission>READ</Permission></Grant>` +
+		`</AccessControlList></AccessControlPolicy>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["acl"]; !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Method == "PUT" {
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			policyXML = string(body)
+			return
+		}
+		fmt.Fprint(w, policyXML)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	policy, err := c.GetBucketACLPolicy("bucket")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if policy.Owner.ID != "owner-id" || len(policy.Grants) != 2 ||
+		policy.Grants[0].Grantee.Type != GranteeCanonicalUser || policy.Grants[0].Permission != PermissionFullControl ||
+		policy.Grants[1].Grantee.Type != GranteeGroup || policy.Grants[1].Grantee.URI != AllUsersURI {
+		t.Fatalf("Error: unexpected policy %#v", policy)
+	}
+	acl, err := c.GetBucketACL("bucket")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if acl != "public-read" {
+		t.Fatalf("Error: expected public-read, got %s", acl)
+	}
+
+	// Grant read to a specific user, encoded with xsi:type.
+	policy.Grants = append(policy.Grants, Grant{
+		Grantee:    Grantee{Type: GranteeAmazonCustomerByEmail, EmailAddress: "user@example.com"},
+		Permission: PermissionRead,
+	})
+	if err = c.SetBucketACLPolicy("bucket", policy); err != nil {
+		t.Fatal("Error:", err)
+	}
+	want := `<Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="AmazonCustomerByEmail"><EmailAddress>user@example.com</EmailAddress></Grantee>`
+	if !strings.Contains(policyXML, want) {
+		t.Fatalf("Error: expected %s in %s", want, policyXML)
+	}
+	policy, err = c.GetBucketACLPolicy("bucket")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(policy.Grants) != 3 || policy.Grants[2].Grantee.Type != GranteeAmazonCustomerByEmail || policy.Grants[2].Grantee.EmailAddress != "user@example.com" {
+		t.Fatalf("Error: unexpected policy %#v", policy)
+	}
+
+	// Grants must name their grantee and have a known permission.
+	invalidGrants := []Grant{
+		{Grantee: Grantee{Type: GranteeCanonicalUser}, Permission: PermissionRead},
+		{Grantee: Grantee{Type: GranteeGroup}, Permission: PermissionRead},
+		{Grantee: Grantee{Type: "User", ID: "id"}, Permission: PermissionRead},
+		{Grantee: Grantee{Type: GranteeCanonicalUser, ID: "id"}, Permission: "read"},
+	}
+	for i, g := range invalidGrants {
+		if err = c.SetBucketACLPolicy("bucket", AccessControlPolicy{Grants: []Grant{g}}); err == nil {
+			t.Fatalf("Test %d: Error: invalid grant should fail", i+1)
+		}
+	}
+}
+
+// Tests object ACLs, canned and with explicit grants.
+func TestObjectACL(t *testing.T) {
+	acls := map[string]string{"/bucket/object": "private"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["acl"]; !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		acl, ok := acls[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == "PUT" {
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			acls[r.URL.Path] = r.Header.Get("x-amz-acl")
+			if len(body) > 0 {
+				acls[r.URL.Path] = string(body)
+			}
+			return
+		}
+		var grants string
+		if acl == "public-read" {
+			grants = `<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="Group"><URI>http://acs.amazonaws.com/groups/global/AllUsers</URI></Grantee><Permission>READ</Permission></Grant>`
+		}
+		fmt.Fprintf(w, `<AccessControlPolicy><Owner><ID>owner-id</ID></Owner><AccessControlList>`+
+			`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser"><ID>owner-id</ID></Grantee><Permission>FULL_CONTROL</Permission></Grant>`+
+			`%s</AccessControlList></AccessControlPolicy>`, grants)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	if err = c.SetObjectACL("bucket", "object", "public-read"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	acl, err := c.GetObjectACL("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if acl != "public-read" {
+		t.Fatalf("Error: expected public-read, got %s", acl)
+	}
+
+	// Grant read to a specific user.
+	policy := AccessControlPolicy{
+		Owner: Owner{ID: "owner-id"},
+		Grants: []Grant{{
+			Grantee:    Grantee{Type: GranteeCanonicalUser, ID: "user-id"},
+			Permission: PermissionRead,
+		}},
+	}
+	if err = c.SetObjectACLPolicy("bucket", "object", policy); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(acls["/bucket/object"], `xsi:type="CanonicalUser"><ID>user-id</ID>`) {
+		t.Fatalf("Error: unexpected policy %s", acls["/bucket/object"])
+	}
+
+	// Missing objects and unrecognized ACLs.
+	if err = c.SetObjectACL("bucket", "missing", "public-read"); ToErrorResponse(err).Code != "NoSuchKey" {
+		t.Fatalf("Error: expected NoSuchKey, got %v", err)
+	}
+	if _, err = c.GetObjectACL("bucket", "missing"); ToErrorResponse(err).Code != "NoSuchKey" {
+		t.Fatalf("Error: expected NoSuchKey, got %v", err)
+	}
+	if err = c.SetObjectACL("bucket", "object", "public"); err == nil {
+		t.Fatal("Error: unrecognized ACL should fail")
+	}
+}
+
+// Tests reporting of public buckets and objects from their ACLs.
+func TestIsPublic(t *testing.T) {
+	allUsersRead := `<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="Group"><URI>http://acs.amazonaws.com/groups/global/AllUsers</URI></Grantee><Permission>READ</Permission></Grant>`
+	authenticatedRead := `<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="Group"><URI>http://acs.amazonaws.com/groups/global/AuthenticatedUsers</URI></Grantee><Permission>READ</Permission></Grant>`
+	grants := map[string]string{
+		"/public/":        allUsersRead,
+		"/private/":       "",
+		"/public/private": authenticatedRead,
+		"/private/public": allUsersRead,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["acl"]; !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		grant, ok := grants[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `<AccessControlPolicy><Owner><ID>owner-id</ID></Owner><AccessControlList>`+
+			`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser"><ID>owner-id</ID></Grantee><Permission>FULL_CONTROL</Permission></Grant>`+
+			`%s</AccessControlList></AccessControlPolicy>`, grant)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	for bucketName, want := range map[string]bool{"public": true, "private": false} {
+		isPublic, err := c.IsBucketPublic(bucketName)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if isPublic != want {
+			t.Fatalf("Error: bucket %s expected public %t", bucketName, want)
+		}
+	}
+	for objectName, want := range map[string]bool{"public/private": false, "private/public": true} {
+		isPublic, err := c.IsObjectPublic(path.Dir(objectName), path.Base(objectName))
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if isPublic != want {
+			t.Fatalf("Error: object %s expected public %t", objectName, want)
+		}
+	}
+	if _, err = c.IsObjectPublic("public", "missing"); ToErrorResponse(err).Code != "NoSuchKey" {
+		t.Fatalf("Error: expected NoSuchKey, got %v", err)
+	}
+}
+
+// Tests bucket versioning status and configuration parsing.
+func TestBucketVersioning(t *testing.T) {
+	want := map[VersioningStatus]bool{
+		VersioningEnabled:     true,
+		VersioningSuspended:   true,
+		VersioningUnversioned: false,
+		"enabled":             false,
+	}
+	for status, ok := range want {
+		if status.isValidVersioningStatus() != ok {
+			t.Fatalf("Error: unexpected validity for status %s", status)
+		}
+	}
+
+	testCases := []struct {
+		body      string
+		status    VersioningStatus
+		mfaDelete bool
+	}{
+		{`<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"/>`, VersioningUnversioned, false},
+		{`<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Status>Enabled</Status></VersioningConfiguration>`, VersioningEnabled, false},
+		{`<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Status>Suspended</Status><MfaDelete>Enabled</MfaDelete></VersioningConfiguration>`, VersioningSuspended, true},
+	}
+	for i, testCase := range testCases {
+		versioning := BucketVersioningConfiguration{}
+		if err := xmlDecoder(strings.NewReader(testCase.body), &versioning); err != nil {
+			t.Fatalf("Test %d: Error: %s", i+1, err)
+		}
+		if versioning.Status != testCase.status {
+			t.Fatalf("Test %d: Error: expected status %s, got %s", i+1, testCase.status, versioning.Status)
+		}
+		if versioning.IsMFADeleteEnabled() != testCase.mfaDelete {
+			t.Fatalf("Test %d: Error: expected MFA delete %v", i+1, testCase.mfaDelete)
+		}
+	}
+}
+
+// Tests the versioning configuration is sent with its Content-MD5.
+func TestSetBucketVersioning(t *testing.T) {
+	var versioningXML string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["versioning"]; !ok || r.Method != "PUT" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil || r.Header.Get("Content-Md5") != base64.StdEncoding.EncodeToString(sumMD5(body)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		versioningXML = string(body)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	if err = c.SetBucketVersioning("bucket", VersioningEnabled); err != nil {
+		t.Fatal("Error:", err)
+	}
+	want := "<VersioningConfiguration><Status>Enabled</Status></VersioningConfiguration>"
+	if versioningXML != want {
+		t.Fatalf("Error: expected %s, got %s", want, versioningXML)
+	}
+	if err = c.SetBucketVersioning("bucket", VersioningUnversioned); err == nil {
+		t.Fatal("Error: expected an error for the unversioned status")
+	}
+}
+
+// Tests decoding of interleaved versions and delete markers.
+func TestListVersionsResult(t *testing.T) {
+	body := `<ListVersionsResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+<Name>bucket</Name><Prefix></Prefix><KeyMarker></KeyMarker><VersionIdMarker></VersionIdMarker>
+<MaxKeys>1000</MaxKeys><IsTruncated>true</IsTruncated>
+<NextKeyMarker>photo.jpg</NextKeyMarker><NextVersionIdMarker>v1</NextVersionIdMarker>
+<DeleteMarker><Key>photo.jpg</Key><VersionId>v3</VersionId><IsLatest>true</IsLatest></DeleteMarker>
+<Version><Key>photo.jpg</Key><VersionId>v2</VersionId><IsLatest>false</IsLatest><Size>10</Size></Version>
+<Version><Key>photo.jpg</Key><VersionId>v1</VersionId><IsLatest>false</IsLatest><Size>20</Size></Version>
+</ListVersionsResult>`
+	result := listVersionsResult{}
+	if err := xmlDecoder(strings.NewReader(body), &result); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !result.IsTruncated || result.NextKeyMarker != "photo.jpg" || result.NextVersionIDMarker != "v1" {
+		t.Fatal("Error: markers not decoded properly")
+	}
+	want := []struct {
+		element   string
+		versionID string
+		isLatest  bool
+	}{
+		{"DeleteMarker", "v3", true},
+		{"Version", "v2", false},
+		{"Version", "v1", false},
+	}
+	if len(result.Versions) != len(want) {
+		t.Fatalf("Error: expected %d versions, got %d", len(want), len(result.Versions))
+	}
+	for i, w := range want {
+		version := result.Versions[i]
+		if version.XMLName.Local != w.element || version.VersionID != w.versionID || version.IsLatest != w.isLatest {
+			t.Fatalf("Error: unexpected version %d: %#v", i, version)
+		}
+	}
+}
+
+// Tests versions listing continues from the key and version id markers.
+func TestListObjectVersions(t *testing.T) {
+	pages := map[string]string{
+		"": `<ListVersionsResult><IsTruncated>true</IsTruncated>
+<NextKeyMarker>photo.jpg</NextKeyMarker><NextVersionIdMarker>v2</NextVersionIdMarker>
+<DeleteMarker><Key>photo.jpg</Key><VersionId>v3</VersionId><IsLatest>true</IsLatest><LastModified>2016-12-23T00:00:00.000Z</LastModified></DeleteMarker>
+<Version><Key>photo.jpg</Key><VersionId>v2</VersionId><IsLatest>false</IsLatest><Size>10</Size></Version>
+</ListVersionsResult>`,
+		"photo.jpg/v2": `<ListVersionsResult><IsTruncated>false</IsTruncated>
+<Version><Key>photo.jpg</Key><VersionId>v1</VersionId><IsLatest>false</IsLatest><Size>20</Size></Version>
+<Version><Key>text.txt</Key><VersionId>null</VersionId><IsLatest>true</IsLatest><Size>30</Size></Version>
+</ListVersionsResult>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		marker := query.Get("key-marker")
+		if marker != "" {
+			marker += "/" + query.Get("version-id-marker")
+		}
+		page, ok := pages[marker]
+		if _, versions := query["versions"]; !versions || !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	var versions []string
+	for object := range c.ListObjectVersions("bucket", "", true, doneCh) {
+		if object.Err != nil {
+			t.Fatal("Error:", object.Err)
+		}
+		versions = append(versions, fmt.Sprintf("%s/%s/%t/%t", object.Key, object.VersionID, object.IsLatest, object.IsDeleteMarker))
+	}
+	expected := []string{
+		"photo.jpg/v3/true/true",
+		"photo.jpg/v2/false/false",
+		"photo.jpg/v1/false/false",
+		"text.txt/null/true/false",
+	}
+	if !reflect.DeepEqual(versions, expected) {
+		t.Fatalf("Error: expected versions %v, got %v", expected, versions)
+	}
+}
+
+// Tests failures to remove object versions are reported.
+func TestRemoveObjectVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("versionId") == "locked" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied because object protected by object lock.</Message></Error>`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.RemoveObject("bucket", "object"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.RemoveObjectVersion("bucket", "object", "v1"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.RemoveObjectVersion("bucket", "object", "locked"); ToErrorResponse(err).Code != "AccessDenied" {
+		t.Fatalf("Error: expected AccessDenied, got %v", err)
+	}
+}
+
+// Tests bucket notification marshaling.
+func TestBucketNotification(t *testing.T) {
+	queueConfig := QueueConfig{Queue: "arn:aws:sqs:us-east-1:444455556666:s3notificationqueue"}
+	queueConfig.AddEvents(ObjectCreatedAll)
+	queueConfig.AddFilterPrefix("photos/")
+	queueConfig.AddFilterSuffix(".jpg")
+	// Replaces the previous suffix filter rule.
+	queueConfig.AddFilterSuffix(".png")
+
+	notification := BucketNotification{}
+	notification.QueueConfigs = append(notification.QueueConfigs, queueConfig)
+	if err := isValidBucketNotification(notification); err != nil {
+		t.Fatal("Error:", err)
+	}
+	notificationBytes, err := xml.Marshal(notification)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	want := "<NotificationConfiguration><QueueConfiguration><Event>s3:ObjectCreated:*</Event><Filter><S3Key><FilterRule><Name>prefix</Name><Value>photos/</Value></FilterRule><FilterRule><Name>suffix</Name><Value>.png</Value></FilterRule></S3Key></Filter><Queue>arn:aws:sqs:us-east-1:444455556666:s3notificationqueue</Queue></QueueConfiguration></NotificationConfiguration>"
+	if string(notificationBytes) != want {
+		t.Fatalf("Error: expected %s, got %s", want, string(notificationBytes))
+	}
+
+	// Verify the configuration decodes back.
+	gotNotification := BucketNotification{}
+	if err = xmlDecoder(bytes.NewReader(notificationBytes), &gotNotification); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(gotNotification.QueueConfigs) != 1 || gotNotification.QueueConfigs[0].Queue != queueConfig.Queue {
+		t.Fatal("Error: queue configuration not decoded properly")
+	}
+
+	// Configuration without events should fail.
+	notification.QueueConfigs[0].Events = nil
+	if err = isValidBucketNotification(notification); err == nil {
+		t.Fatal("Error: should fail")
+	}
+}
+
+// Tests decoding of bucket notification events stream.
+func TestListenBucketNotification(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("prefix") != "photos/" || len(r.URL.Query()["events"]) != 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"Records":[{"eventName":"s3:ObjectCreated:Put","s3":{"bucket":{"name":"bucket"},"object":{"key":"photos/1.jpg","size":10}}}]}`+"\n")
+		// Keep alive.
+		fmt.Fprint(w, " ")
+		fmt.Fprint(w, `{"Records":[{"eventName":"s3:ObjectRemoved:Delete","s3":{"bucket":{"name":"bucket"},"object":{"key":"photos/2.jpg"}}}]}`+"\n")
+		w.(http.Flusher).Flush()
+		// Hold the connection open until the client goes away.
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	doneCh := make(chan struct{})
+	events := []string{"s3:ObjectCreated:*", "s3:ObjectRemoved:*"}
+	notificationInfoCh := c.ListenBucketNotification("bucket", "photos/", ".jpg", events, doneCh)
+	wantEvents := []string{"s3:ObjectCreated:Put", "s3:ObjectRemoved:Delete"}
+	for _, wantEvent := range wantEvents {
+		notificationInfo := <-notificationInfoCh
+		if notificationInfo.Err != nil {
+			t.Fatal("Error:", notificationInfo.Err)
+		}
+		if len(notificationInfo.Records) != 1 || notificationInfo.Records[0].EventName != wantEvent {
+			t.Fatalf("Error: expected event %s, got %#v", wantEvent, notificationInfo.Records)
+		}
+	}
+	close(doneCh)
+	// Channel should be closed once doneCh is closed.
+	for range notificationInfoCh {
+	}
+}
+
+// Tests dropped connections are retried right away once connected,
+// the back off starts over.
+func TestListenBucketNotificationReconnect(t *testing.T) {
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Each connection sends one event, then drops.
+		n := atomic.AddInt32(&connections, 1)
+		fmt.Fprintf(w, `{"Records":[{"eventName":"s3:ObjectCreated:Put","s3":{"bucket":{"name":"bucket"},"object":{"key":"%d.jpg"}}}]}`+"\n", n)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	notificationInfoCh := c.ListenBucketNotification("bucket", "", "", []string{"s3:ObjectCreated:*"}, doneCh)
+	timeout := time.After(3 * time.Second)
+	for i := 0; i < 6; i++ {
+		select {
+		case notificationInfo := <-notificationInfoCh:
+			if notificationInfo.Err != nil {
+				t.Fatal("Error:", notificationInfo.Err)
+			}
+		case <-timeout:
+			t.Fatalf("Error: received %d events, reconnects are backing off", i)
+		}
+	}
+}
+
+// Tests servers closing the connection before sending any record are
+// retried with the back off.
+func TestListenBucketNotificationEmptyStream(t *testing.T) {
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&connections, 1)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	doneCh := make(chan struct{})
+	notificationInfoCh := c.ListenBucketNotification("bucket", "", "", []string{"s3:ObjectCreated:*"}, doneCh)
+	time.Sleep(time.Second)
+	close(doneCh)
+	for range notificationInfoCh {
+	}
+	if n := atomic.LoadInt32(&connections); n > 5 {
+		t.Fatalf("Error: expected reconnects to back off, got %d connections", n)
+	}
+}
+
+// Tests splitting compose sources into copy parts.
+func TestCalculateCopyParts(t *testing.T) {
+	src1, err := NewSource("bucket", "object1")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	src2, err := NewSource("bucket", "object2")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = src2.SetRange(10, 20); err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	// Non final sources smaller than 5MiB should fail.
+	if _, err = calculateCopyParts([]Source{src1, src2}, []int64{minPartSize - 1, 11}); err == nil {
+		t.Fatal("Error: should fail")
+	}
+
+	// Sources larger than 5GiB are split into multiple parts.
+	copyParts, err := calculateCopyParts([]Source{src1, src2}, []int64{maxPartSize + minPartSize, 11})
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(copyParts) != 3 {
+		t.Fatalf("Error: expected 3 copy parts, got %d", len(copyParts))
+	}
+	if copyParts[0].start != 0 || copyParts[1].start != copyParts[0].end+1 || copyParts[1].end != maxPartSize+minPartSize-1 {
+		t.Fatalf("Error: unexpected copy part ranges %#v", copyParts[:2])
+	}
+	if copyParts[2].start != 10 || copyParts[2].end != 20 {
+		t.Fatalf("Error: unexpected copy part range %d-%d", copyParts[2].start, copyParts[2].end)
+	}
+
+	// Invalid ranges should fail.
+	if err = src2.SetRange(20, 10); err == nil {
+		t.Fatal("Error: should fail")
+	}
+}
+
+// Tests copying objects with copied and replaced metadata.
+func TestCopyObject(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			w.Header().Set("Content-Length", "1024")
+			w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+			return
+		}
+		if r.Method != "PUT" || r.URL.Path != "/bucket/object" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		header = r.Header
+		fmt.Fprint(w, `<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	source, err := NewSource("bucket", "source")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	// Metadata is copied by default.
+	dest, err := NewDestination("bucket", "object", "")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.CopyObject(dest, source); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if header.Get("x-amz-copy-source") != "/bucket/source" || header.Get("x-amz-metadata-directive") != "COPY" {
+		t.Fatalf("Error: unexpected copy headers %v", header)
+	}
+
+	// Replacing metadata requires new metadata.
+	if err = dest.ReplaceMetadata(nil); err == nil {
+		t.Fatal("Error: replacing metadata without metadata should fail")
+	}
+	dest, err = NewDestination("bucket", "object", "text/plain")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = dest.ReplaceMetadata(map[string]string{"owner": "me", "X-Amz-Meta-Team": "storage"}); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.CopyObject(dest, source); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if header.Get("x-amz-metadata-directive") != "REPLACE" || header.Get("Content-Type") != "text/plain" ||
+		header.Get("x-amz-meta-owner") != "me" || header.Get("x-amz-meta-team") != "storage" {
+		t.Fatalf("Error: unexpected replace headers %v", header)
+	}
+
+	// Copying onto itself requires replacing metadata.
+	self, err := NewSource("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.CopyObject(dest, self); err != nil {
+		t.Fatal("Error:", err)
+	}
+	dest, err = NewDestination("bucket", "object", "text/plain")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.CopyObject(dest, self); err == nil {
+		t.Fatal("Error: copying onto itself without replacing metadata should fail")
+	}
+}
+
+// Tests copying objects larger than 5GiB in parts.
+func TestCopyObjectMultipart(t *testing.T) {
+	var initHeader http.Header
+	var copyRanges, copyConds []string
+	var completed bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == "HEAD":
+			w.Header().Set("Content-Length", strconv.FormatInt(maxSinglePutObjectSize+1024, 10))
+			w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+			w.Header().Set("ETag", `"source-etag"`)
+			w.Header().Set("Content-Type", "video/mp4")
+			w.Header().Set("X-Amz-Meta-Owner", "me")
+		case r.Method == "POST" && len(query["uploads"]) > 0:
+			initHeader = r.Header
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><UploadId>upload-id</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == "PUT" && query.Get("uploadId") == "upload-id":
+			copyRanges = append(copyRanges, r.Header.Get("x-amz-copy-source-range"))
+			copyConds = append(copyConds, r.Header.Get("x-amz-copy-source-if-match"))
+			fmt.Fprintf(w, `<CopyPartResult><ETag>"etag-%s"</ETag></CopyPartResult>`, query.Get("partNumber"))
+		case r.Method == "POST" && query.Get("uploadId") == "upload-id":
+			completed = true
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><ETag>"etag-2"</ETag></CompleteMultipartUploadResult>`)
+		case r.Method == "PUT":
+			// Single copies of more than 5GiB are refused.
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `<Error><Code>InvalidRequest</Code><Message>The specified copy source is larger than the maximum allowable size for a copy source: 5368709120</Message></Error>`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	source, err := NewSource("bucket", "source")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	dest, err := NewDestination("bucket", "object", "")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.CopyObject(dest, source); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !completed {
+		t.Fatal("Error: multipart copy was not completed")
+	}
+	// Parts are split evenly.
+	half := int64(maxSinglePutObjectSize+1024) / 2
+	wantRanges := []string{
+		fmt.Sprintf("bytes=0-%d", half-1),
+		fmt.Sprintf("bytes=%d-%d", half, maxSinglePutObjectSize+1023),
+	}
+	if !reflect.DeepEqual(copyRanges, wantRanges) {
+		t.Fatalf("Error: expected ranges %v, got %v", wantRanges, copyRanges)
+	}
+	for _, cond := range copyConds {
+		if cond != "source-etag" {
+			t.Fatalf("Error: expected parts guarded by the source ETag, got %q", cond)
+		}
+	}
+	if initHeader.Get("Content-Type") != "video/mp4" || initHeader.Get("X-Amz-Meta-Owner") != "me" {
+		t.Fatalf("Error: source metadata not carried over, got %v", initHeader)
+	}
+	if len(source.conditions) != 0 {
+		t.Fatal("Error: caller's source conditions were modified")
+	}
+}
+
+// Tests removing all objects under a prefix.
+func TestRemoveObjectsByPrefix(t *testing.T) {
+	var deleteRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == "GET" && len(query["versioning"]) > 0:
+			fmt.Fprint(w, `<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></VersioningConfiguration>`)
+		case r.Method == "GET":
+			if query.Get("prefix") != "photos/" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `<ListBucketResult><Name>bucket</Name><IsTruncated>false</IsTruncated>`+
+				`<Contents><Key>photos/1.jpg</Key><Size>1</Size></Contents>`+
+				`<Contents><Key>photos/2.jpg</Key><Size>1</Size></Contents></ListBucketResult>`)
+		case r.Method == "POST" && len(query["delete"]) > 0:
+			deleteRequests++
+			if r.Header.Get("Content-Md5") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			var deleteReq deleteMultiObjects
+			if err := xml.NewDecoder(r.Body).Decode(&deleteReq); err != nil || len(deleteReq.Objects) != 2 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `<DeleteResult><Error><Key>photos/2.jpg</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error></DeleteResult>`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	var removeErrs []RemoveObjectError
+	for removeErr := range c.RemoveObjectsByPrefix("bucket", "photos/", doneCh) {
+		removeErrs = append(removeErrs, removeErr)
+	}
+	if deleteRequests != 1 {
+		t.Fatalf("Error: expected 1 multi objects delete request, got %d", deleteRequests)
+	}
+	if len(removeErrs) != 1 {
+		t.Fatalf("Error: expected 1 failure, got %#v", removeErrs)
+	}
+	if removeErrs[0].ObjectName != "photos/2.jpg" || ToErrorResponse(removeErrs[0].Err).Code != "AccessDenied" {
+		t.Fatalf("Error: unexpected failure %#v", removeErrs[0])
+	}
+}
+
+// Tests objects refused by servers without multi objects delete are
+// reported.
+func TestRemoveObjectsByPrefixOneByOne(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == "GET" && len(query["versioning"]) > 0:
+			fmt.Fprint(w, `<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></VersioningConfiguration>`)
+		case r.Method == "GET":
+			fmt.Fprint(w, `<ListBucketResult><Name>bucket</Name><IsTruncated>false</IsTruncated>`+
+				`<Contents><Key>photos/1.jpg</Key><Size>1</Size></Contents>`+
+				`<Contents><Key>photos/2.jpg</Key><Size>1</Size></Contents></ListBucketResult>`)
+		case r.Method == "POST" && len(query["delete"]) > 0:
+			w.WriteHeader(http.StatusNotImplemented)
+			fmt.Fprint(w, `<Error><Code>NotImplemented</Code><Message>A header you provided implies functionality that is not implemented</Message></Error>`)
+		case r.Method == "DELETE" && r.URL.Path == "/bucket/photos/2.jpg":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+		case r.Method == "DELETE":
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	var removeErrs []RemoveObjectError
+	for removeErr := range c.RemoveObjectsByPrefix("bucket", "photos/", doneCh) {
+		removeErrs = append(removeErrs, removeErr)
+	}
+	if len(deleted) != 1 || deleted[0] != "/bucket/photos/1.jpg" {
+		t.Fatalf("Error: unexpected objects removed %v", deleted)
+	}
+	if len(removeErrs) != 1 {
+		t.Fatalf("Error: expected 1 failure, got %#v", removeErrs)
+	}
+	if removeErrs[0].ObjectName != "photos/2.jpg" || ToErrorResponse(removeErrs[0].Err).Code != "AccessDenied" {
+		t.Fatalf("Error: unexpected failure %#v", removeErrs[0])
+	}
+}
+
+// Tests calculating md5sum of a seekable reader.
+func TestComputeMD5(t *testing.T) {
+	reader := bytes.NewReader([]byte("helloworld"))
+	// Skip 'hello'.
+	if _, err := reader.Seek(5, 0); err != nil {
+		t.Fatal("Error:", err)
+	}
+	md5Sum, err := computeMD5(reader, 5)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !bytes.Equal(md5Sum, sumMD5([]byte("world"))) {
+		t.Fatalf("Error: unexpected md5sum %x", md5Sum)
+	}
+	// Reader offset should be unchanged.
+	offset, err := reader.Seek(0, 1)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if offset != 5 {
+		t.Fatalf("Error: expected offset 5, got %d", offset)
+	}
+}
+
+// Tests uploading with multipart disabled.
+func TestPutObjectMultipartDisabled(t *testing.T) {
+	var putRequests int
+	var putSize int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Multipart requests are not expected.
+		if r.Method != "PUT" || r.URL.RawQuery != "" {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		putRequests++
+		n, err := io.Copy(ioutil.Discard, r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		putSize = n
+		w.Header().Set("ETag", "\"etag\"")
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.SetMultipartDisabled(true)
+
+	size := int64(minPartSize + 1)
+	n, err := c.PutObject("bucket", "object", bytes.NewReader(make([]byte, size)), "")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if n != size || putSize != size {
+		t.Fatalf("Error: expected %d bytes, got %d uploaded and %d received", size, n, putSize)
+	}
+	if putRequests != 1 {
+		t.Fatalf("Error: expected 1 PUT request, got %d", putRequests)
+	}
+}
+
+// Tests multipart threshold.
+func TestSetMultipartThreshold(t *testing.T) {
+	var putRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Multipart requests are not expected.
+		if r.Method != "PUT" || r.URL.RawQuery != "" {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		putRequests++
+		io.Copy(ioutil.Discard, r.Body)
+		w.Header().Set("ETag", "\"etag\"")
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SetMultipartThreshold(minPartSize - 1); err == nil {
+		t.Fatal("Error: threshold smaller than minimum part size should fail")
+	}
+	if err = c.SetMultipartThreshold(maxSinglePutObjectSize + 1); err == nil {
+		t.Fatal("Error: threshold larger than maximum single PUT size should fail")
+	}
+	if err = c.SetMultipartThreshold(2 * minPartSize); err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	// Objects below the threshold are uploaded with a single PUT.
+	size := int64(minPartSize + 1)
+	n, err := c.PutObject("bucket", "object", bytes.NewReader(make([]byte, size)), "")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if n != size || putRequests != 1 {
+		t.Fatalf("Error: expected 1 PUT request of %d bytes, got %d requests of %d bytes", size, putRequests, n)
+	}
+}
+
+// Tests reading gzip encoded objects.
+func TestGetObjectDecompressed(t *testing.T) {
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	if _, err := gzipWriter.Write([]byte("hello world")); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal("Error:", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.Header.Get("Accept-Encoding") != "identity" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Length", strconv.Itoa(compressed.Len()))
+		w.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	// Raw access returns compressed data.
+	object, err := c.GetObject("bucket", "object.gz")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	data, err := ioutil.ReadAll(object)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	object.Close()
+	if !bytes.Equal(data, compressed.Bytes()) {
+		t.Fatal("Error: GetObject should return compressed data")
+	}
+
+	decompressed, err := c.GetObjectDecompressed("bucket", "object.gz")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer decompressed.Close()
+	st, err := decompressed.Stat()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if st.Size != -1 {
+		t.Fatalf("Error: expected unknown size, got %d", st.Size)
+	}
+	data, err = ioutil.ReadAll(decompressed)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if string(data) != "hello world" {
+		t.Fatalf("Error: unexpected data %q", data)
+	}
+}
+
+// Tests content type detection of files.
+func TestDetectContentType(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "minio-go")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	testCases := []struct {
+		fileName    string
+		data        []byte
+		contentType string
+	}{
+		// Detected from extension.
+		{"image.png", []byte("hello world"), "image/png"},
+		// Detected from contents.
+		{"image", []byte("\x89PNG\x0D\x0A\x1A\x0A"), "image/png"},
+		{"text", []byte("hello world"), "text/plain; charset=utf-8"},
+		{"empty", []byte{}, "text/plain; charset=utf-8"},
+	}
+	for i, testCase := range testCases {
+		filePath := filepath.Join(tmpDir, testCase.fileName)
+		if err = ioutil.WriteFile(filePath, testCase.data, 0600); err != nil {
+			t.Fatal("Error:", err)
+		}
+		fileReader, err := os.Open(filePath)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		contentType, err := detectContentType(fileReader, filePath)
+		if err != nil {
+			t.Fatalf("Test %d: Error: %s", i+1, err)
+		}
+		if contentType != testCase.contentType {
+			t.Fatalf("Test %d: expected %s, got %s", i+1, testCase.contentType, contentType)
+		}
+		// File offset should be untouched.
+		offset, err := fileReader.Seek(0, 1)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if offset != 0 {
+			t.Fatalf("Test %d: expected offset 0, got %d", i+1, offset)
+		}
+		fileReader.Close()
+	}
+}
+
+// Tests ranged object reads, also of servers ignoring the range.
+func TestGetObjectRange(t *testing.T) {
+	data := []byte("0123456789")
+	var ignoreRange bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ignoreRange {
+			r.Header.Del("Range")
+		}
+		http.ServeContent(w, r, "object", time.Now(), bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	testCases := []struct {
+		start, end int64
+		expected   string
+	}{
+		{0, 0, "0"},
+		{2, 5, "2345"},
+		// Range beyond the end of the object is truncated.
+		{8, 20, "89"},
+	}
+	for _, ignoreRange = range []bool{false, true} {
+		for i, testCase := range testCases {
+			reader, objectInfo, err := c.GetObjectRange("bucket", "object", testCase.start, testCase.end)
+			if err != nil {
+				t.Fatalf("Test %d: Error: %s", i+1, err)
+			}
+			buf, err := ioutil.ReadAll(reader)
+			reader.Close()
+			if err != nil {
+				t.Fatalf("Test %d: Error: %s", i+1, err)
+			}
+			if string(buf) != testCase.expected {
+				t.Fatalf("Test %d: expected %q, got %q, range ignored %v", i+1, testCase.expected, buf, ignoreRange)
+			}
+			if objectInfo.Size != int64(len(data)) {
+				t.Fatalf("Test %d: expected size %d, got %d", i+1, len(data), objectInfo.Size)
+			}
+		}
+
+		// Invalid ranges.
+		if _, _, err = c.GetObjectRange("bucket", "object", 5, 2); err == nil {
+			t.Fatal("Error: end before start should fail")
+		}
+		_, _, err = c.GetObjectRange("bucket", "object", 20, 30)
+		if ToErrorResponse(err).Code != "InvalidRange" {
+			t.Fatalf("Error: expected InvalidRange, got %v", err)
+		}
+	}
+}
+
+// Tests stat reports the total object size after ranged reads.
+func TestObjectReadAtStat(t *testing.T) {
+	data := make([]byte, 1024)
+	var mutex sync.Mutex
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mutex.Unlock()
+		http.ServeContent(w, r, "object", time.Now(), bytes.NewReader(data))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	object, err := c.GetObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer object.Close()
+	if _, err = object.ReadAt(make([]byte, 100), 500); err != nil {
+		t.Fatal("Error:", err)
+	}
+	objectInfo, err := object.Stat()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if objectInfo.Size != int64(len(data)) {
+		t.Fatalf("Error: expected size %d, got %d", len(data), objectInfo.Size)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if ranges[len(ranges)-1] != "bytes=500-599" {
+		t.Fatalf("Error: expected a ranged read, got %v", ranges)
+	}
+
+	testCases := []struct {
+		contentRange string
+		size         int64
+		shouldPass   bool
+	}{
+		{"bytes 500-599/1024", 1024, true},
+		{"bytes 0-0/1", 1, true},
+		// Total size unknown.
+		{"bytes 500-599/*", -1, false},
+		{"500-599/1024", -1, false},
+		{"", -1, false},
+	}
+	for i, testCase := range testCases {
+		size, err := parseContentRangeSize(testCase.contentRange)
+		if (err == nil) != testCase.shouldPass || size != testCase.size {
+			t.Fatalf("Test %d: Error: unexpected size %d, error %v", i+1, size, err)
+		}
+	}
+}
+
+// Tests ReadAt and Read after reaching the end of the object, the end
+// of one does not end the other.
+func TestObjectReadAtAfterEOF(t *testing.T) {
+	data := []byte("hello, world")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "object", time.Now(), bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	// ReadAt after Read reached the end.
+	object, err := c.GetObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer object.Close()
+	if _, err = ioutil.ReadAll(object); err != nil {
+		t.Fatal("Error:", err)
+	}
+	buf := make([]byte, 3)
+	if n, err := object.ReadAt(buf, 2); err != nil || string(buf[:n]) != "llo" {
+		t.Fatalf("Error: ReadAt after Read returned %q, %v", buf[:n], err)
+	}
+
+	// ReadAt and Read after ReadAt reached the end.
+	object, err = c.GetObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer object.Close()
+	big := make([]byte, 100)
+	if n, err := object.ReadAt(big, 0); err != io.EOF || string(big[:n]) != string(data) {
+		t.Fatalf("Error: ReadAt returned %q, %v", big[:n], err)
+	}
+	if n, err := object.ReadAt(buf, 2); err != nil || string(buf[:n]) != "llo" {
+		t.Fatalf("Error: ReadAt after ReadAt returned %q, %v", buf[:n], err)
+	}
+	if n, err := object.Read(buf); err != nil || string(buf[:n]) != "hel" {
+		t.Fatalf("Error: Read after ReadAt returned %q, %v", buf[:n], err)
+	}
+}
+
+// Tests ReadAt after the object stream failed, ranges are fetched
+// independently of the stream.
+func TestObjectReadAtAfterStreamError(t *testing.T) {
+	data := []byte("hello, world")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			http.ServeContent(w, r, "object", time.Now(), bytes.NewReader(data))
+			return
+		}
+		// Close the connection of the stream after half the body.
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Write(data[:6])
+		w.(http.Flusher).Flush()
+		if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+			conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	object, err := c.GetObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer object.Close()
+	if _, err = ioutil.ReadAll(object); ToErrorResponse(err).Code != "UnexpectedEOF" {
+		t.Fatal("Error: expected UnexpectedEOF, got", err)
+	}
+	buf := make([]byte, 5)
+	if n, err := object.ReadAt(buf, 7); err != io.EOF || string(buf[:n]) != "world" {
+		t.Fatalf("Error: ReadAt after the stream failed returned %q, %v", buf[:n], err)
+	}
+}
+
+// Tests independent ReadAt ranges fail for objects overwritten
+// meanwhile and for servers ignoring the range.
+func TestObjectReadAtRangeChecks(t *testing.T) {
+	var mutex sync.Mutex
+	data, etag := []byte("hello, world"), `"etag-1"`
+	var ignoreRange bool
+	modTime := time.Now()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		content := data
+		w.Header().Set("ETag", etag)
+		if ignoreRange {
+			r.Header.Del("Range")
+		}
+		mutex.Unlock()
+		http.ServeContent(w, r, "object", modTime, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	object, err := c.GetObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer object.Close()
+	buf := make([]byte, 5)
+	if n, err := object.ReadAt(buf, 7); err != io.EOF || string(buf[:n]) != "world" {
+		t.Fatalf("Error: ReadAt returned %q, %v", buf[:n], err)
+	}
+
+	// Ranges of the overwritten object are not mixed in.
+	mutex.Lock()
+	data, etag = []byte("HELLO, WORLD"), `"etag-2"`
+	mutex.Unlock()
+	if n, err := object.ReadAt(buf, 7); ToErrorResponse(err).Code != "PreconditionFailed" {
+		t.Fatalf("Error: expected object changed error, got %q, %v", buf[:n], err)
+	}
+
+	// The whole object is not read as the range.
+	mutex.Lock()
+	ignoreRange = true
+	mutex.Unlock()
+	object, err = c.GetObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer object.Close()
+	if n, err := object.ReadAt(buf, 7); ToErrorResponse(err).Code != "NotImplemented" {
+		t.Fatalf("Error: expected NotImplemented, got %q, %v", buf[:n], err)
+	}
+}
+
+// Tests concurrent ReadAt calls, run with -race.
+func TestObjectConcurrentReadAt(t *testing.T) {
+	data := make([]byte, 1024)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "object", time.Now(), bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	object, err := c.GetObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer object.Close()
+
+	// Read the first bytes sequentially.
+	buf := make([]byte, 10)
+	if _, err = io.ReadFull(object, buf); err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(offset int64) {
+			defer wg.Done()
+			buf := make([]byte, 32)
+			n, err := object.ReadAt(buf, offset)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			if !bytes.Equal(buf[:n], data[offset:offset+32]) {
+				errCh <- fmt.Errorf("unexpected data at offset %d", offset)
+			}
+		}(int64(i * 61))
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		t.Fatal("Error:", err)
+	}
+
+	// Sequential Read continues where it left off.
+	if _, err = io.ReadFull(object, buf); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !bytes.Equal(buf, data[10:20]) {
+		t.Fatalf("Error: unexpected data %v after ReadAt", buf)
+	}
+
+	// Reading to the end, with Read and ReadAt, does not end the
+	// ranges read concurrently at earlier offsets.
+	errCh = make(chan error, 64)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if _, err := io.Copy(ioutil.Discard, object); err != nil {
+			errCh <- err
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if _, err := object.ReadAt(make([]byte, 2048), 20); err != io.EOF {
+			errCh <- fmt.Errorf("expected EOF, got %v", err)
+		}
+	}()
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(offset int64) {
+			defer wg.Done()
+			buf := make([]byte, 32)
+			for j := 0; j < 8; j++ {
+				n, err := object.ReadAt(buf, offset)
+				if err != nil {
+					errCh <- err
+					return
+				}
+				if !bytes.Equal(buf[:n], data[offset:offset+32]) {
+					errCh <- fmt.Errorf("unexpected data at offset %d", offset)
+					return
+				}
+			}
+		}(int64(i * 61))
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		t.Fatal("Error:", err)
+	}
+}
+
+// Tests concurrent ReadAt calls fetch their ranges in parallel.
+func TestObjectParallelReadAt(t *testing.T) {
+	data := make([]byte, 1024)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	var mutex sync.Mutex
+	var rangeRequests int
+	var timedOut bool
+	bothArrived := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			// Hold range requests until two are in flight.
+			mutex.Lock()
+			rangeRequests++
+			if rangeRequests == 2 {
+				close(bothArrived)
+			}
+			mutex.Unlock()
+			select {
+			case <-bothArrived:
+			case <-time.After(2 * time.Second):
+				mutex.Lock()
+				timedOut = true
+				mutex.Unlock()
+			}
+		}
+		http.ServeContent(w, r, "object", time.Now(), bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	object, err := c.GetObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer object.Close()
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, 2)
+	for _, offset := range []int64{100, 1000} {
+		wg.Add(1)
+		go func(offset int64) {
+			defer wg.Done()
+			buf := make([]byte, 100)
+			n, err := object.ReadAt(buf, offset)
+			if err != nil && err != io.EOF {
+				errCh <- err
+				return
+			}
+			expected := data[offset:]
+			if len(expected) > len(buf) {
+				expected = expected[:len(buf)]
+			}
+			if !bytes.Equal(buf[:n], expected) {
+				errCh <- fmt.Errorf("unexpected data of %d bytes at offset %d", n, offset)
+			}
+		}(offset)
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		t.Fatal("Error:", err)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if timedOut || rangeRequests != 2 {
+		t.Fatal("Error: ReadAt calls were not issued in parallel")
+	}
+}
+
+// Tests listing objects page by page with continuation tokens.
+func TestListObjectsPage(t *testing.T) {
+	keys := []string{"a", "b", "c", "d", "e"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		maxKeys, err := strconv.Atoi(query.Get("max-keys"))
+		if err != nil || query.Get("delimiter") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var contents string
+		var count int
+		truncated := false
+		for _, key := range keys {
+			if key <= query.Get("marker") {
+				continue
+			}
+			if count == maxKeys {
+				truncated = true
+				break
+			}
+			contents += "<Contents><Key>" + key + "</Key><Size>1</Size></Contents>"
+			count++
+		}
+		fmt.Fprintf(w, "<ListBucketResult><IsTruncated>%t</IsTruncated>%s</ListBucketResult>", truncated, contents)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	var listed []string
+	var pages int
+	var token string
+	for {
+		objects, nextToken, err := c.ListObjectsPage("bucket", "", token, 2)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		pages++
+		for _, object := range objects {
+			listed = append(listed, object.Key)
+		}
+		if nextToken == "" {
+			break
+		}
+		token = nextToken
+	}
+	if pages != 3 || strings.Join(listed, ",") != "a,b,c,d,e" {
+		t.Fatalf("Error: unexpected listing %v in %d pages", listed, pages)
+	}
+
+	if _, _, err = c.ListObjectsPage("bucket", "", "", -1); err == nil {
+		t.Fatal("Error: negative max keys should fail")
+	}
+}
+
+// Tests listing incomplete uploads with and without size.
+func TestListIncompleteUploadsWithSize(t *testing.T) {
+	var listPartsRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case len(query["uploads"]) > 0:
+			fmt.Fprint(w, `<ListMultipartUploadsResult><IsTruncated>false</IsTruncated>`+
+				`<Upload><Key>object</Key><UploadId>upload1</UploadId><Initiated>2016-01-02T15:04:05.000Z</Initiated></Upload>`+
+				`</ListMultipartUploadsResult>`)
+		case query.Get("uploadId") == "upload1":
+			listPartsRequests++
+			fmt.Fprint(w, `<ListPartsResult><IsTruncated>false</IsTruncated>`+
+				`<Part><PartNumber>1</PartNumber><Size>5242880</Size></Part>`+
+				`<Part><PartNumber>2</PartNumber><Size>10</Size></Part></ListPartsResult>`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	initiated := time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)
+	for upload := range c.ListIncompleteUploads("bucket", "", true, doneCh) {
+		if upload.Err != nil {
+			t.Fatal("Error:", upload.Err)
+		}
+		if upload.Size != 0 || !upload.Initiated.Equal(initiated) {
+			t.Fatalf("Error: unexpected upload %#v", upload)
+		}
+	}
+	if listPartsRequests != 0 {
+		t.Fatalf("Error: expected no list parts requests, got %d", listPartsRequests)
+	}
+	for upload := range c.ListIncompleteUploadsWithSize("bucket", "", true, doneCh) {
+		if upload.Err != nil {
+			t.Fatal("Error:", upload.Err)
+		}
+		if upload.Size != 5242890 || !upload.Initiated.Equal(initiated) {
+			t.Fatalf("Error: unexpected upload %#v", upload)
+		}
+	}
+	if listPartsRequests != 1 {
+		t.Fatalf("Error: expected 1 list parts request, got %d", listPartsRequests)
+	}
+}
+
+// Tests counting and removing old incomplete uploads.
+func TestRemoveIncompleteUploads(t *testing.T) {
+	oldInitiated := time.Now().UTC().Add(-48 * time.Hour).Format(time.RFC3339)
+	newInitiated := time.Now().UTC().Format(time.RFC3339)
+	var aborted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == "GET" && len(query["uploads"]) > 0:
+			fmt.Fprint(w, `<ListMultipartUploadsResult><IsTruncated>false</IsTruncated>`+
+				`<Upload><Key>old</Key><UploadId>upload1</UploadId><Initiated>`+oldInitiated+`</Initiated></Upload>`+
+				`<Upload><Key>new</Key><UploadId>upload2</UploadId><Initiated>`+newInitiated+`</Initiated></Upload>`+
+				`</ListMultipartUploadsResult>`)
+		case r.Method == "GET" && query.Get("uploadId") != "":
+			fmt.Fprint(w, `<ListPartsResult><IsTruncated>false</IsTruncated>`+
+				`<Part><PartNumber>1</PartNumber><Size>100</Size></Part></ListPartsResult>`)
+		case r.Method == "DELETE" && query.Get("uploadId") != "":
+			aborted = append(aborted, query.Get("uploadId"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	info, err := c.CountIncompleteUploads("bucket", "", 0)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if info.Count != 2 || info.Size != 200 {
+		t.Fatalf("Error: unexpected info %#v", info)
+	}
+	info, err = c.CountIncompleteUploads("bucket", "", 24*time.Hour)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if info.Count != 1 || info.Size != 100 {
+		t.Fatalf("Error: unexpected info %#v", info)
+	}
+	if len(aborted) != 0 {
+		t.Fatalf("Error: counting should not abort uploads, aborted %v", aborted)
+	}
+
+	count, err := c.RemoveIncompleteUploads("bucket", "", 24*time.Hour)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if count != 1 || len(aborted) != 1 || aborted[0] != "upload1" {
+		t.Fatalf("Error: expected only upload1 to be aborted, aborted %v", aborted)
+	}
+}
+
+// Tests buckets without region are created in 'us-east-1' without a
+// location constraint.
+func TestMakeBucketEmptyRegion(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil || r.Method != "PUT" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body = string(data)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	testCases := []struct {
+		location string
+		body     string
+		region   string
+	}{
+		{"", "", "us-east-1"},
+		{"us-east-1", "", "us-east-1"},
+		{"eu-west-1", "<LocationConstraint>eu-west-1</LocationConstraint>", "eu-west-1"},
+	}
+	for i, testCase := range testCases {
+		bucketName := fmt.Sprintf("bucket%d", i+1)
+		if err = c.MakeBucket(bucketName, "private", testCase.location); err != nil {
+			t.Fatalf("Test %d: Error: %s", i+1, err)
+		}
+		if (testCase.body == "" && body != "") || !strings.Contains(body, testCase.body) {
+			t.Fatalf("Test %d: Error: unexpected body %q", i+1, body)
+		}
+		if region, ok := c.bucketLocCache.Get(bucketName); !ok || region != testCase.region {
+			t.Fatalf("Test %d: Error: expected region %s, got %s", i+1, testCase.region, region)
+		}
+	}
+}
+
+// Tests buckets are created with object lock enabled.
+func TestMakeBucketWithObjectLock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+		if r.Method != "PUT" || r.Header.Get("x-amz-bucket-object-lock-enabled") != "true" {
+			w.WriteHeader(http.StatusNotImplemented)
+			fmt.Fprint(w, `<Error><Code>NotImplemented</Code><Message>A header you provided implies functionality that is not implemented</Message></Error>`)
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.MakeBucketWithObjectLock("bucket", "private", ""); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.MakeBucket("bucket", "private", ""); ToErrorResponse(err).Code != "NotImplemented" {
+		t.Fatalf("Error: expected NotImplemented, got %v", err)
+	}
+	// Regions are left to the server.
+	if err = c.MakeBucketWithObjectLock("bucket", "private", "ap-future-1"); err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	// Google Cloud Storage fails before any request.
+	c, err = NewGCS("storage.googleapis.com", "ACCESS-KEY", "SECRET-KEY", false)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.MakeBucketWithObjectLock("bucket", "private", ""); ToErrorResponse(err).Code != "NotImplemented" {
+		t.Fatalf("Error: expected NotImplemented, got %v", err)
+	}
+}
+
+// Tests existing buckets are only accepted when owned by the caller.
+func TestMakeBucketIfNotExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+		switch strings.Trim(r.URL.Path, "/") {
+		case "owned":
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `<Error><Code>BucketAlreadyOwnedByYou</Code><Message>Your previous request to create the named bucket succeeded and you already own it.</Message></Error>`)
+		case "taken":
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `<Error><Code>BucketAlreadyExists</Code><Message>The requested bucket name is not available.</Message></Error>`)
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	testCases := []struct {
+		bucketName string
+		code       string
+	}{
+		{"new", ""},
+		{"owned", ""},
+		{"taken", "BucketAlreadyExists"},
+	}
+	for i, testCase := range testCases {
+		err = c.MakeBucketIfNotExists(testCase.bucketName, "private", "")
+		if ToErrorResponse(err).Code != testCase.code {
+			t.Fatalf("Test %d: Error: expected code %q, got %v", i+1, testCase.code, err)
+		}
+	}
+	if err = c.MakeBucket("owned", "private", ""); ToErrorResponse(err).Code != "BucketAlreadyOwnedByYou" {
+		t.Fatalf("Error: expected MakeBucket to fail, got %v", err)
+	}
+}
+
+// Tests object lock retention and legal hold.
+func TestObjectLock(t *testing.T) {
+	retainUntilDate := time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == "GET" && len(query["versioning"]) > 0:
+			fmt.Fprint(w, `<VersioningConfiguration></VersioningConfiguration>`)
+		case r.URL.Path == "/unversioned/object":
+			io.Copy(ioutil.Discard, r.Body)
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `<Error><Code>InvalidRequest</Code><Message>Bucket is missing Object Lock Configuration</Message></Error>`)
+		case r.Method == "PUT" && len(query["retention"]) > 0:
+			var retention objectRetention
+			if err := xml.NewDecoder(r.Body).Decode(&retention); err != nil ||
+				retention.Mode != RetentionCompliance || !retention.RetainUntilDate.Equal(retainUntilDate) {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		case r.Method == "GET" && len(query["retention"]) > 0:
+			fmt.Fprint(w, `<Retention><Mode>COMPLIANCE</Mode><RetainUntilDate>2030-01-02T15:04:05Z</RetainUntilDate></Retention>`)
+		case r.Method == "GET" && len(query["legal-hold"]) > 0:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchObjectLockConfiguration</Code><Message>The specified object does not have a ObjectLock configuration</Message></Error>`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	if err = c.PutObjectRetention("bucket", "object", "", "compliance", retainUntilDate); err == nil {
+		t.Fatal("Error: invalid retention mode should fail")
+	}
+	if err = c.PutObjectRetention("bucket", "object", "", RetentionCompliance, retainUntilDate); err != nil {
+		t.Fatal("Error:", err)
+	}
+	mode, date, err := c.GetObjectRetention("bucket", "object", "")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if mode != RetentionCompliance || date == nil || !date.Equal(retainUntilDate) {
+		t.Fatalf("Error: unexpected retention %s %v", mode, date)
+	}
+	status, err := c.GetObjectLegalHold("bucket", "object", "")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if status != LegalHoldOff {
+		t.Fatalf("Error: expected legal hold %s, got %s", LegalHoldOff, status)
+	}
+
+	// Buckets without versioning report it clearly.
+	err = c.PutObjectLegalHold("unversioned", "object", "", LegalHoldOn)
+	if errResp := ToErrorResponse(err); errResp.Code != "InvalidArgument" || !strings.Contains(errResp.Message, "versioning") {
+		t.Fatalf("Error: expected versioning error, got %v", err)
+	}
+}
+
+// Tests healing of buckets and objects.
+func TestHeal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || len(r.URL.Query()["heal"]) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.Header.Get("x-minio-operation") {
+		case "bucket":
+		case "object":
+			if r.Header.Get("x-minio-dry-run") != "yes" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `<HealResult><State>partial</State></HealResult>`)
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	result, err := c.HealBucket("bucket", false)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if result.State != HealOK {
+		t.Fatalf("Error: expected state %s, got %s", HealOK, result.State)
+	}
+	result, err = c.HealObject("bucket", "object", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if result.State != HealPartial {
+		t.Fatalf("Error: expected state %s, got %s", HealPartial, result.State)
+	}
+
+	// Healing is not supported by Amazon S3.
+	s3Client, err := New("s3.amazonaws.com", "", "", false)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if _, err = s3Client.HealBucket("bucket", false); ToErrorResponse(err).Code != "NotImplemented" {
+		t.Fatalf("Error: expected NotImplemented, got %v", err)
+	}
+}
+
+// Tests endpoint health checks.
+func TestPing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Authorization"), "VALID-ACCESS-KEY") {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>InvalidAccessKeyId</Code><Message>The access key Id you provided does not exist in our records.</Message></Error>`)
+			return
+		}
+		fmt.Fprint(w, `<ListAllMyBucketsResult><Buckets></Buckets></ListAllMyBucketsResult>`)
+	}))
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	c, err := New(u.Host, "VALID-ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.Ping(); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !c.IsOnline() {
+		t.Fatal("Error: endpoint should be online")
+	}
+
+	// Rejected credentials.
+	c, err = New(u.Host, "WRONG-ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.Ping(); ToErrorResponse(err).Code != "InvalidAccessKeyId" {
+		t.Fatalf("Error: expected InvalidAccessKeyId, got %v", err)
+	}
+
+	// Unreachable endpoint.
+	server.Close()
+	if err = c.Ping(); ToErrorResponse(err).Code != "EndpointUnreachable" {
+		t.Fatalf("Error: expected EndpointUnreachable, got %v", err)
+	}
+	if c.IsOnline() {
+		t.Fatal("Error: endpoint should be offline")
+	}
+}
+
+// Tests measuring and adjusting for server clock skew.
+func TestClockSkew(t *testing.T) {
+	// Server clock is an hour ahead of the local clock.
+	skew := time.Hour
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverTime := time.Now().UTC().Add(skew)
+		w.Header().Set("Date", serverTime.Format(http.TimeFormat))
+		if r.Header.Get("Authorization") != "" {
+			date, err := time.Parse(iso8601DateFormat, r.Header.Get("X-Amz-Date"))
+			if err != nil || serverTime.Sub(date) > 15*time.Minute {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `<Error><Code>RequestTimeTooSkewed</Code><Message>The difference between the request time and the server's time is too large.</Message></Error>`)
+				return
+			}
+		}
+		fmt.Fprint(w, `<ListAllMyBucketsResult><Buckets></Buckets></ListAllMyBucketsResult>`)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	serverTime, err := c.GetServerTime()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if d := serverTime.Sub(time.Now().UTC().Add(skew)); d < -2*time.Second || d > 2*time.Second {
+		t.Fatalf("Error: unexpected server time %s", serverTime)
+	}
+
+	// Requests are rejected without adjustment.
+	if _, err = c.ListBuckets(); ToErrorResponse(err).Code != "RequestTimeTooSkewed" {
+		t.Fatalf("Error: expected RequestTimeTooSkewed, got %v", err)
+	}
+
+	// Measured offset is used once adjustment is enabled.
+	offset, err := c.GetClockSkew()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if d := offset - skew; d < -2*time.Second || d > 2*time.Second {
+		t.Fatalf("Error: expected clock skew of %s, got %s", skew, offset)
+	}
+	c.SetClockSkewAdjustment(true)
+	if _, err = c.ListBuckets(); err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	// Offset is measured again on a rejected request.
+	skew = -time.Hour
+	if _, err = c.ListBuckets(); err != nil {
+		t.Fatal("Error:", err)
+	}
+}
+
+// Tests resolving credentials from a chain of providers.
+func TestCredentials(t *testing.T) {
+	// Save and clear the environment used by the providers.
+	envKeys := []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_ACCESS_KEY", "AWS_SECRET_KEY",
+		"AWS_SESSION_TOKEN", "AWS_SHARED_CREDENTIALS_FILE", "AWS_PROFILE", "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"}
+	for _, key := range envKeys {
+		defer os.Setenv(key, os.Getenv(key))
+		os.Unsetenv(key)
+	}
+
+	// Explicit credentials win.
+	accessKeyID, secretAccessKey, sessionToken, err := NewCredentials(&StaticProvider{AccessKeyID: "STATIC-ACCESS", SecretAccessKey: "STATIC-SECRET"}, &EnvProvider{}).Get()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if accessKeyID != "STATIC-ACCESS" || secretAccessKey != "STATIC-SECRET" || sessionToken != "" {
+		t.Fatalf("Error: unexpected credentials %s %s %s", accessKeyID, secretAccessKey, sessionToken)
+	}
+
+	// Empty explicit credentials fall back to the environment.
+	os.Setenv("AWS_ACCESS_KEY_ID", "ENV-ACCESS")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "ENV-SECRET")
+	os.Setenv("AWS_SESSION_TOKEN", "ENV-TOKEN")
+	accessKeyID, secretAccessKey, sessionToken, err = NewCredentials(&StaticProvider{}, &EnvProvider{}).Get()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if accessKeyID != "ENV-ACCESS" || secretAccessKey != "ENV-SECRET" || sessionToken != "ENV-TOKEN" {
+		t.Fatalf("Error: unexpected credentials %s %s %s", accessKeyID, secretAccessKey, sessionToken)
+	}
+	os.Unsetenv("AWS_ACCESS_KEY_ID")
+	os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+
+	// Shared credentials file profiles.
+	tmpDir, err := ioutil.TempDir("", "minio-go-credentials")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	filename := filepath.Join(tmpDir, "credentials")
+	data := "# comment\n[default]\naws_access_key_id = FILE-ACCESS\naws_secret_access_key = FILE-SECRET\n\n[other]\naws_access_key_id=OTHER-ACCESS\naws_secret_access_key=OTHER-SECRET\naws_session_token=OTHER-TOKEN\n"
+	if err = ioutil.WriteFile(filename, []byte(data), 0600); err != nil {
+		t.Fatal("Error:", err)
+	}
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filename)
+	accessKeyID, secretAccessKey, sessionToken, err = NewCredentials(&EnvProvider{}, &FileProvider{}).Get()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if accessKeyID != "FILE-ACCESS" || secretAccessKey != "FILE-SECRET" {
+		t.Fatalf("Error: unexpected credentials %s %s %s", accessKeyID, secretAccessKey, sessionToken)
+	}
+	os.Setenv("AWS_PROFILE", "other")
+	accessKeyID, secretAccessKey, sessionToken, err = (&FileProvider{}).Retrieve()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if accessKeyID != "OTHER-ACCESS" || secretAccessKey != "OTHER-SECRET" || sessionToken != "OTHER-TOKEN" {
+		t.Fatalf("Error: unexpected credentials %s %s %s", accessKeyID, secretAccessKey, sessionToken)
+	}
+	if _, _, _, err = (&FileProvider{Profile: "missing"}).Retrieve(); err == nil {
+		t.Fatal("Error: missing profile should fail")
+	}
+
+	// EC2 instance metadata.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case iamSecurityCredentialsPath:
+			fmt.Fprint(w, "my-role\n")
+		case iamSecurityCredentialsPath + "my-role":
+			fmt.Fprint(w, `{"Code": "Success", "AccessKeyId": "IAM-ACCESS", "SecretAccessKey": "IAM-SECRET", "Token": "IAM-TOKEN"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	accessKeyID, secretAccessKey, sessionToken, err = (&IAMProvider{Endpoint: server.URL}).Retrieve()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if accessKeyID != "IAM-ACCESS" || secretAccessKey != "IAM-SECRET" || sessionToken != "IAM-TOKEN" {
+		t.Fatalf("Error: unexpected credentials %s %s %s", accessKeyID, secretAccessKey, sessionToken)
+	}
+
+	// No credentials in any provider.
+	if _, err = NewWithCredentials("play.minio.io:9000", NewCredentials(&StaticProvider{}, &EnvProvider{}), false); err == nil {
+		t.Fatal("Error: empty credentials chain should fail")
+	}
+	c, err := NewWithCredentials("play.minio.io:9000", NewCredentials(&StaticProvider{AccessKeyID: "STATIC-ACCESS", SecretAccessKey: "STATIC-SECRET"}), false)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if c.anonymous {
+		t.Fatal("Error: client should not be anonymous")
+	}
+}
+
+// Tests signing with session token of temporary credentials.
+func TestSessionToken(t *testing.T) {
+	req, err := http.NewRequest("GET", "http://s3.amazonaws.com/bucket/object", nil)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	signedReq := signV4(*req, "ACCESS-KEY", "SECRET-KEY", "SESSION-TOKEN", "us-east-1")
+	if signedReq.Header.Get("X-Amz-Security-Token") != "SESSION-TOKEN" {
+		t.Fatal("Error: session token header missing")
+	}
+	if !strings.Contains(signedReq.Header.Get("Authorization"), "x-amz-security-token") {
+		t.Fatal("Error: session token should be a signed header")
+	}
+	signedReq = signV2(*req, "ACCESS-KEY", "SECRET-KEY", "SESSION-TOKEN")
+	if signedReq.Header.Get("X-Amz-Security-Token") != "SESSION-TOKEN" {
+		t.Fatal("Error: session token header missing")
+	}
+
+	req, err = http.NewRequest("GET", "http://s3.amazonaws.com/bucket/object", nil)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	presignedReq := preSignV4(*req, "ACCESS-KEY", "SECRET-KEY", "SESSION-TOKEN", "us-east-1", 3600)
+	if presignedReq.URL.Query().Get("X-Amz-Security-Token") != "SESSION-TOKEN" {
+		t.Fatal("Error: session token query missing")
+	}
+	presignedReq = preSignV2(*req, "ACCESS-KEY", "SECRET-KEY", "SESSION-TOKEN", 3600)
+	if presignedReq.URL.Query().Get("x-amz-security-token") != "SESSION-TOKEN" {
+		t.Fatal("Error: session token query missing")
+	}
+
+	// Client sends the session token with every request.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Security-Token") != "SESSION-TOKEN" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>InvalidToken</Code><Message>The provided token is malformed or otherwise invalid.</Message></Error>`)
+			return
+		}
+		fmt.Fprint(w, `<ListAllMyBucketsResult><Buckets></Buckets></ListAllMyBucketsResult>`)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := NewWithCredentials(u.Host, NewCredentials(&StaticProvider{
+		AccessKeyID:     "ACCESS-KEY",
+		SecretAccessKey: "SECRET-KEY",
+		SessionToken:    "SESSION-TOKEN",
+	}), true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if _, err = c.ListBuckets(); err != nil {
+		t.Fatal("Error:", err)
+	}
+}
+
+// Tests retrieving temporary credentials again once expired.
+func TestCredentialsRefresh(t *testing.T) {
+	var mutex sync.Mutex
+	var assumeRoleCount int
+	expiration := time.Now().UTC().Add(30 * time.Second)
+	stsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Authorization"), "/us-east-1/sts/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<ErrorResponse><Error><Code>SignatureDoesNotMatch</Code><Message>The request signature we calculated does not match the signature you provided.</Message></Error><RequestId>1</RequestId></ErrorResponse>`)
+			return
+		}
+		if r.FormValue("Action") != "AssumeRole" || r.FormValue("RoleArn") != "arn:aws:iam::123456789012:role/my-role" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `<ErrorResponse><Error><Code>InvalidAction</Code><Message>Could not find operation.</Message></Error><RequestId>2</RequestId></ErrorResponse>`)
+			return
+		}
+		mutex.Lock()
+		assumeRoleCount++
+		token := fmt.Sprintf("TOKEN-%d", assumeRoleCount)
+		mutex.Unlock()
+		fmt.Fprintf(w, `<AssumeRoleResponse><AssumeRoleResult><Credentials><AccessKeyId>TEMP-ACCESS</AccessKeyId><SecretAccessKey>TEMP-SECRET</SecretAccessKey><SessionToken>%s</SessionToken><Expiration>%s</Expiration></Credentials></AssumeRoleResult></AssumeRoleResponse>`,
+			token, expiration.Format(time.RFC3339))
+	}))
+	defer stsServer.Close()
+
+	// Requests are only accepted with the latest session token.
+	s3Server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		token := fmt.Sprintf("TOKEN-%d", assumeRoleCount)
+		mutex.Unlock()
+		if r.Header.Get("X-Amz-Security-Token") != token {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `<Error><Code>ExpiredToken</Code><Message>The provided token has expired.</Message></Error>`)
+			return
+		}
+		fmt.Fprint(w, `<ListAllMyBucketsResult><Buckets></Buckets></ListAllMyBucketsResult>`)
+	}))
+	defer s3Server.Close()
+	u, err := url.Parse(s3Server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	provider := &STSAssumeRoleProvider{
+		Endpoint:        stsServer.URL,
+		AccessKeyID:     "ACCESS-KEY",
+		SecretAccessKey: "SECRET-KEY",
+		RoleARN:         "arn:aws:iam::123456789012:role/my-role",
+	}
+	c, err := NewWithCredentials(u.Host, NewCredentials(provider), true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	// Credentials expiring within the expiry window are retrieved
+	// again before every request.
+	for i := 0; i < 2; i++ {
+		if _, err = c.ListBuckets(); err != nil {
+			t.Fatal("Error:", err)
+		}
+	}
+	if assumeRoleCount != 3 {
+		t.Fatalf("Error: expected 3 AssumeRole requests, got %d", assumeRoleCount)
+	}
+
+	// Valid credentials are cached.
+	expiration = time.Now().UTC().Add(time.Hour)
+	c.credentials.Expire()
+	for i := 0; i < 2; i++ {
+		if _, err = c.ListBuckets(); err != nil {
+			t.Fatal("Error:", err)
+		}
+	}
+	if assumeRoleCount != 4 {
+		t.Fatalf("Error: expected 4 AssumeRole requests, got %d", assumeRoleCount)
+	}
+
+	// Security Token Service errors.
+	provider = &STSAssumeRoleProvider{
+		Endpoint:        stsServer.URL,
+		AccessKeyID:     "ACCESS-KEY",
+		SecretAccessKey: "SECRET-KEY",
+		RoleARN:         "arn:aws:iam::123456789012:role/other-role",
+	}
+	if _, _, _, err = provider.Retrieve(); ToErrorResponse(err).Code != "InvalidAction" {
+		t.Fatalf("Error: expected InvalidAction, got %v", err)
+	}
+}
+
+// Tests signing a single PutObject for an explicit region.
+func TestPutObjectWithRegion(t *testing.T) {
+	var signedRegions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+		if _, ok := r.URL.Query()["location"]; ok {
+			fmt.Fprint(w, `<LocationConstraint>us-west-2</LocationConstraint>`)
+			return
+		}
+		// Credential=ACCESS-KEY/<date>/<region>/s3/aws4_request
+		credential := strings.SplitN(r.Header.Get("Authorization"), "/", 4)
+		if len(credential) == 4 {
+			signedRegions = append(signedRegions, credential[2])
+		}
+		w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	c, err := NewV4(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if _, err = c.PutObjectWithRegion("bucket", "object", bytes.NewReader([]byte("data")), "", "eu-west-1"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(signedRegions) != 1 || signedRegions[0] != "eu-west-1" {
+		t.Fatalf("Error: expected request signed for eu-west-1, got %v", signedRegions)
+	}
+	// Client state is not modified.
+	if _, ok := c.bucketLocCache.Get("bucket"); ok {
+		t.Fatal("Error: region override should not be cached")
+	}
+	if _, err = c.PutObject("bucket", "object", bytes.NewReader([]byte("data")), ""); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(signedRegions) != 2 || signedRegions[1] != "us-west-2" {
+		t.Fatalf("Error: expected request signed for us-west-2, got %v", signedRegions)
+	}
+}
+
+// Tests presigning with headers declared to be sent at use time.
+func TestPresignedPutObjectWithHeaders(t *testing.T) {
+	req, err := http.NewRequest("PUT", "http://localhost:9000/bucket/object?X-Amz-Expires=3600", nil)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	req.Header.Set("Content-Type", "image/png")
+	req.Header.Set("X-Amz-Acl", "public-read")
+	req.Header.Set("User-Agent", "minio-go")
+	expectedCanonicalRequest := strings.Join([]string{
+		"PUT",
+		"/bucket/object",
+		"X-Amz-Expires=3600",
+		"content-type:image/png",
+		"host:localhost:9000",
+		"x-amz-acl:public-read",
+		"",
+		"content-type;host;x-amz-acl",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	if canonicalRequest := getCanonicalRequest(*req, presignIgnoredHeaders); canonicalRequest != expectedCanonicalRequest {
+		t.Fatalf("Error: expected canonical request\n%s\ngot\n%s", expectedCanonicalRequest, canonicalRequest)
+	}
+
+	c, err := NewV4("localhost:9000", "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+	headers := make(http.Header)
+	headers.Set("Content-Type", "image/png")
+	headers.Set("X-Amz-Acl", "public-read")
+	presignedURL, err := c.PresignedPutObjectWithHeaders("bucket", "object", time.Hour, headers)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	u, err := url.Parse(presignedURL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if signedHeaders := u.Query().Get("X-Amz-SignedHeaders"); signedHeaders != "content-type;host;x-amz-acl" {
+		t.Fatalf("Error: unexpected signed headers %s", signedHeaders)
+	}
+}
+
+// Tests object names are normalized only if enabled, and addressed
+// the same way by every operation otherwise.
+func TestObjectNameNormalization(t *testing.T) {
+	names := []struct {
+		name, normalized string
+	}{
+		{"/foo/../bar", "bar"},
+		{"foo//bar/./baz", "foo/bar/baz"},
+		{"../../foo", "foo"},
+		{"dir//", "dir/"},
+		{"a b+c/é", "a b+c/é"},
+		{"foo/..", ""},
+	}
+	for i, n := range names {
+		if normalized := normalizeObjectName(n.name); normalized != n.normalized {
+			t.Fatalf("Test %d: Error: expected %q, got %q", i+1, n.normalized, normalized)
+		}
+	}
+
+	var requestURIs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+		requestURIs = append(requestURIs, r.RequestURI)
+		w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", "0")
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	testCases := []struct {
+		normalize  bool
+		requestURI string
+	}{
+		{false, "/bucket/foo//bar/./baz"},
+		{true, "/bucket/foo/bar/baz"},
+	}
+	for i, testCase := range testCases {
+		c.SetObjectNameNormalization(testCase.normalize)
+		requestURIs = nil
+		if _, err = c.PutObject("bucket", "foo//bar/./baz", strings.NewReader(""), ""); err != nil {
+			t.Fatalf("Test %d: Error: %s", i+1, err)
+		}
+		if _, err = c.StatObject("bucket", "foo//bar/./baz"); err != nil {
+			t.Fatalf("Test %d: Error: %s", i+1, err)
+		}
+		presignedURL, err := c.PresignedGetObject("bucket", "foo//bar/./baz", time.Hour, nil)
+		if err != nil {
+			t.Fatalf("Test %d: Error: %s", i+1, err)
+		}
+		presignedURI := strings.TrimPrefix(presignedURL, server.URL)
+		if len(requestURIs) != 2 || requestURIs[0] != testCase.requestURI || requestURIs[1] != testCase.requestURI ||
+			strings.SplitN(presignedURI, "?", 2)[0] != testCase.requestURI {
+			t.Fatalf("Test %d: Error: unexpected request URIs %v, presigned %s", i+1, requestURIs, presignedURI)
+		}
+	}
+
+	// Names resolving to nothing never address the bucket.
+	if _, err = c.StatObject("bucket", "foo/.."); err == nil {
+		t.Fatal("Error: empty normalized name should fail")
+	}
+}
+
+// Tests object names with special characters are requested with the
+// same encoded path they are signed with.
+func TestObjectNameEncoding(t *testing.T) {
+	objectNames := []string{
+		"a b+c%d/é",
+		"a&b=c;d:e@f,g$h",
+		"!'()*[]^`{}|~",
+		"question?mark#hash",
+		"日本語/ファイル",
+	}
+	var requestPath, decodedPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+		requestPath = strings.SplitN(r.RequestURI, "?", 2)[0]
+		decodedPath = r.URL.Path
+		w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	for _, signature := range []SignatureType{SignatureV2, SignatureV4} {
+		c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if err = c.SetSignatureType(signature); err != nil {
+			t.Fatal("Error:", err)
+		}
+		c.bucketLocCache.Set("bucket", "us-east-1")
+		for i, objectName := range objectNames {
+			if _, err = c.PutObject("bucket", objectName, strings.NewReader(""), ""); err != nil {
+				t.Fatalf("Test %d: Error: %s", i+1, err)
+			}
+			// Server must see the object name as is, and the path
+			// as it was signed.
+			signedPath := urlEncodePath("/bucket/" + objectName)
+			if decodedPath != "/bucket/"+objectName || requestPath != signedPath {
+				t.Fatalf("Test %d: %s: Error: requested %s (%s), signed %s", i+1, signature, requestPath, decodedPath, signedPath)
+			}
+			presignedURL, err := c.PresignedGetObject("bucket", objectName, time.Hour, nil)
+			if err != nil {
+				t.Fatalf("Test %d: Error: %s", i+1, err)
+			}
+			if presignedPath := strings.SplitN(strings.TrimPrefix(presignedURL, server.URL), "?", 2)[0]; presignedPath != signedPath {
+				t.Fatalf("Test %d: %s: Error: presigned %s, signed %s", i+1, signature, presignedPath, signedPath)
+			}
+		}
+	}
+
+	// Signature version '2' signs sub-resource values without URL
+	// encoding.
+	req, err := http.NewRequest("GET", "http://localhost:9000/bucket/a%20b?response-content-disposition=attachment%3B%20filename%3D%22a%20b.txt%22", nil)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	var buf bytes.Buffer
+	writeCanonicalizedResource(&buf, *req)
+	if want := `/bucket/a%20b?response-content-disposition=attachment; filename="a b.txt"`; buf.String() != want {
+		t.Fatalf("Error: expected canonicalized resource %s, got %s", want, buf.String())
+	}
+}
+
+// Tests presigned URLs with an explicit region are generated without
+// any request.
+func TestPresignedWithRegion(t *testing.T) {
+	// Unreachable endpoint, any request would fail.
+	c, err := New("localhost:1", "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	getURL, err := c.PresignedGetObjectWithRegion("bucket", "object", time.Hour, nil, "eu-west-1")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	putURL, err := c.PresignedPutObjectWithRegion("bucket", "object", time.Hour, "eu-west-1")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	for _, presignedURL := range []string{getURL, putURL} {
+		u, err := url.Parse(presignedURL)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if credential := u.Query().Get("X-Amz-Credential"); !strings.Contains(credential, "/eu-west-1/s3/") {
+			t.Fatalf("Error: unexpected credential %s", credential)
+		}
+	}
+
+	// Client state is not modified.
+	if _, ok := c.bucketLocCache.Get("bucket"); ok {
+		t.Fatal("Error: bucket location should not be cached")
+	}
+	if _, err = c.PresignedGetObjectWithRegion("bucket", "object", time.Hour, nil, ""); err == nil {
+		t.Fatal("Error: empty region should fail")
+	}
+}
+
+// Tests Google Cloud Storage compatibility mode.
+func TestGCSCompatibility(t *testing.T) {
+	var deletedObjects []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS ACCESS-KEY:") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		query := r.URL.Query()
+		switch {
+		case r.Method == "GET" && len(query["versioning"]) > 0:
+			fmt.Fprint(w, `<VersioningConfiguration></VersioningConfiguration>`)
+		case r.Method == "GET" && r.URL.Path == "/bucket/":
+			fmt.Fprint(w, `<ListBucketResult><Name>bucket</Name><IsTruncated>false</IsTruncated>`+
+				`<Contents><Key>photos/1.jpg</Key><Size>1</Size></Contents>`+
+				`<Contents><Key>photos/2.jpg</Key><Size>1</Size></Contents></ListBucketResult>`)
+		case r.Method == "DELETE":
+			deletedObjects = append(deletedObjects, strings.TrimPrefix(r.URL.Path, "/bucket/"))
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == "HEAD":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == "GET":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message><Details>No such object: bucket/missing</Details></Error>`)
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	c, err := NewGCS(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !c.signature.isV2() {
+		t.Fatal("Error: Google Cloud Storage should use signature version '2'")
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	// Path style is used even for Google Cloud Storage.
+	gcsClient, err := NewGCS("storage.googleapis.com", "ACCESS-KEY", "SECRET-KEY", false)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	targetURL, err := gcsClient.makeTargetURL("bucket", "object", "", nil)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if targetURL.String() != "https://storage.googleapis.com/bucket/object" {
+		t.Fatalf("Error: expected path style URL, got %s", targetURL)
+	}
+
+	// Objects are removed one by one.
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	for removeErr := range c.RemoveObjectsByPrefix("bucket", "photos/", doneCh) {
+		t.Fatal("Error:", removeErr.Err)
+	}
+	if len(deletedObjects) != 2 {
+		t.Fatalf("Error: expected 2 objects removed one by one, got %v", deletedObjects)
+	}
+
+	// Error details are part of the message.
+	_, err = c.GetObject("bucket", "missing")
+	errResp := ToErrorResponse(err)
+	if errResp.Code != "NoSuchKey" || !strings.Contains(errResp.Message, "No such object: bucket/missing") {
+		t.Fatalf("Error: unexpected error %v", err)
+	}
+}
+
+// Tests overriding the detected signature type.
+func TestSetSignatureType(t *testing.T) {
+	c, err := New("localhost:9000", "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if c.GetSignatureType() != SignatureV4 {
+		t.Fatalf("Error: expected SignatureV4, got %s", c.GetSignatureType())
+	}
+	gcsClient, err := New("storage.googleapis.com", "ACCESS-KEY", "SECRET-KEY", false)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if gcsClient.GetSignatureType() != SignatureV2 {
+		t.Fatalf("Error: expected SignatureV2, got %s", gcsClient.GetSignatureType())
+	}
+
+	for _, signature := range []SignatureType{SignatureV2, SignatureAnonymous, SignatureV4} {
+		if err = c.SetSignatureType(signature); err != nil {
+			t.Fatal("Error:", err)
+		}
+		if c.GetSignatureType() != signature {
+			t.Fatalf("Error: expected %s, got %s", signature, c.GetSignatureType())
+		}
+	}
+	if err = c.SetSignatureType(SignatureType(42)); err == nil {
+		t.Fatal("Error: unknown signature type should fail")
+	}
+
+	// Clients without credentials stay anonymous.
+	c, err = New("localhost:9000", "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SetSignatureType(SignatureV2); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if c.GetSignatureType() != SignatureAnonymous {
+		t.Fatalf("Error: expected SignatureAnonymous, got %s", c.GetSignatureType())
+	}
+}
+
+// Tests streaming a reader of known size as a single PUT.
+func TestPutObjectSized(t *testing.T) {
+	var uploaded []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil || r.Method != "PUT" || r.ContentLength != int64(len(data)) ||
+			r.Header.Get("X-Amz-Content-Sha256") != unsignedPayload {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		uploaded = data
+		w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := NewV4(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	// Non seekable reader with more data than the object size.
+	reader := io.MultiReader(strings.NewReader("hello "), strings.NewReader("world, trailing data"))
+	n, err := c.PutObjectSized("bucket", "object", reader, 11, "text/plain")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if n != 11 || string(uploaded) != "hello world" {
+		t.Fatalf("Error: unexpected upload of %d bytes %q", n, uploaded)
+	}
+
+	// Reader shorter than the object size.
+	if _, err = c.PutObjectSized("bucket", "object", io.MultiReader(strings.NewReader("short")), 11, "text/plain"); err == nil {
+		t.Fatal("Error: short reader should fail")
+	}
+	if _, err = c.PutObjectSized("bucket", "object", reader, -1, "text/plain"); err == nil {
+		t.Fatal("Error: negative size should fail")
+	}
+}
+
+// Tests FPutObject streams single PUT uploads from the file.
+func TestFPutObjectStream(t *testing.T) {
+	var uploaded int64
+	var uploadedMD5 string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hash := md5.New()
+		n, err := io.Copy(hash, r.Body)
+		if err != nil || r.Method != "PUT" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		uploaded, uploadedMD5 = n, fmt.Sprintf("%x", hash.Sum(nil))
+		w.Header().Set("ETag", `"`+uploadedMD5+`"`)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+	c.SetMultipartDisabled(true)
+
+	// File of the largest size that would be staged in memory.
+	size := int64(minPartSize)
+	file, err := ioutil.TempFile("", "minio-go-test")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer os.Remove(file.Name())
+	hash := md5.New()
+	if _, err = io.CopyN(io.MultiWriter(file, hash), bytes.NewReader(bytes.Repeat([]byte("a"), int(size))), size); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = file.Close(); err != nil {
+		t.Fatal("Error:", err)
+	}
+	expectedMD5 := fmt.Sprintf("%x", hash.Sum(nil))
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	n, err := c.FPutObject("bucket", "object", file.Name(), "application/octet-stream")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	runtime.ReadMemStats(&after)
+	if n != size || uploaded != size || uploadedMD5 != expectedMD5 {
+		t.Fatalf("Error: unexpected upload of %d bytes, md5 %s", uploaded, uploadedMD5)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > uint64(size/4) {
+		t.Fatalf("Error: upload of %d bytes allocated %d bytes", size, allocated)
+	}
+}
+
+// Tests objects are only created if they do not exist.
+func TestPutObjectIfNotExists(t *testing.T) {
+	objects := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil || r.Method != "PUT" || r.Header.Get("If-None-Match") != "*" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if _, ok := objects[r.URL.Path]; ok {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			if r.URL.Path != "/bucket/no-body" {
+				fmt.Fprint(w, `<Error><Code>PreconditionFailed</Code><Message>At least one of the pre-conditions you specified did not hold</Message></Error>`)
+			}
+			return
+		}
+		objects[r.URL.Path] = data
+		w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	for _, objectName := range []string{"lock", "no-body"} {
+		if _, err = c.PutObjectIfNotExists("bucket", objectName, strings.NewReader("owner-1"), "text/plain"); err != nil {
+			t.Fatal("Error:", err)
+		}
+		_, err = c.PutObjectIfNotExists("bucket", objectName, strings.NewReader("owner-2"), "text/plain")
+		if ToErrorResponse(err).Code != "PreconditionFailed" {
+			t.Fatalf("Error: expected PreconditionFailed, got %v", err)
+		}
+		if string(objects["/bucket/"+objectName]) != "owner-1" {
+			t.Fatalf("Error: object %s was overwritten with %q", objectName, objects["/bucket/"+objectName])
+		}
+	}
+}
+
+// Tests error responses of non-standard servers are normalized by a
+// custom parser.
+func TestSetErrorResponseParser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error": "NoSuchKey", "reason": "object missing"}`)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	// Default parsing falls back to the status code.
+	_, err = c.GetObjectTagging("bucket", "object")
+	if errResp := ToErrorResponse(err); errResp.Code != "NoSuchKey" || errResp.Message != "The specified key does not exist." {
+		t.Fatalf("Error: unexpected default error %#v", errResp)
+	}
+
+	var parsed int
+	c.SetErrorResponseParser(func(statusCode int, header http.Header, body []byte) ErrorResponse {
+		parsed++
+		if statusCode != http.StatusNotFound || !bytes.Contains(body, []byte("object missing")) {
+			return ErrorResponse{}
+		}
+		return ErrorResponse{Code: "NoSuchKey", Message: "object missing"}
+	})
+	_, err = c.GetObjectTagging("bucket", "object")
+	if errResp := ToErrorResponse(err); parsed != 1 || errResp.Code != "NoSuchKey" || errResp.Message != "object missing" {
+		t.Fatalf("Error: unexpected parsed error %#v", errResp)
+	}
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	io.ReadSeeker
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadSeeker.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// Tests payload is not sent for requests rejected upfront.
+func TestExpectContinue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Expect") != "100-continue" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// Reject without reading the payload.
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied.</Message></Error>`)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	transport := &http.Transport{ExpectContinueTimeout: time.Second}
+	defer transport.CloseIdleConnections()
+	c.SetCustomTransport(transport)
+
+	reader := &countingReader{ReadSeeker: bytes.NewReader(make([]byte, 1024*1024))}
+	_, err = c.putObjectDo("bucket", "object", reader, nil, nil, 1024*1024, "", nil)
+	if ToErrorResponse(err).Code != "AccessDenied" {
+		t.Fatalf("Error: expected AccessDenied, got %v", err)
+	}
+	if reader.n != 0 {
+		t.Fatalf("Error: expected no payload sent, %d bytes were read", reader.n)
+	}
+}
+
+// Tests staging buffers are reset before reuse.
+func TestBufferPool(t *testing.T) {
+	pool := newBufferPool(1024)
+	buf := pool.Get()
+	buf.WriteString("object data")
+	pool.Put(buf)
+	for i := 0; i < 10; i++ {
+		if buf = pool.Get(); buf.Len() != 0 {
+			t.Fatalf("Error: expected empty buffer, got %q", buf.String())
+		}
+		pool.Put(buf)
+	}
+
+	// Buffers larger than the pool size are not kept.
+	buf = pool.Get()
+	buf.Write(make([]byte, 2048))
+	pool.Put(buf)
+	if buf.Len() != 2048 {
+		t.Fatal("Error: oversized buffer should not be reset and pooled")
+	}
+
+	c, err := New("localhost:9000", "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SetBufferPoolSize(-1); err == nil {
+		t.Fatal("Error: negative buffer pool size should fail")
+	}
+	if err = c.SetBufferPoolSize(0); err != nil {
+		t.Fatal("Error:", err)
+	}
+	buf = c.bufferPool.Get()
+	c.bufferPool.Put(buf)
+}
+
+// Benchmarks allocations of many small uploads.
+func BenchmarkPutObjectSmall(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+		w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		b.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		b.Fatal("Error:", err)
+	}
+	data := bytes.Repeat([]byte("a"), 64*1024)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err = c.PutObject("bucket", "object", bytes.NewReader(data), ""); err != nil {
+			b.Fatal("Error:", err)
+		}
+	}
+}
+
+// Tests ReadAt fetches exactly its range with a single request and
+// leaves the object stream as is.
+func TestObjectReadAtRange(t *testing.T) {
+	data := make([]byte, 1024)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	var mutex sync.Mutex
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mutex.Unlock()
+		http.ServeContent(w, r, "object", time.Now(), bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	object, err := c.GetObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer object.Close()
+
+	buf := make([]byte, 100)
+	for _, offset := range []int64{500, 100, 1000} {
+		n, err := object.ReadAt(buf, offset)
+		if err != nil && err != io.EOF {
+			t.Fatal("Error:", err)
+		}
+		expected := data[offset:]
+		if len(expected) > len(buf) {
+			expected = expected[:len(buf)]
+		}
+		if !bytes.Equal(buf[:n], expected) {
+			t.Fatalf("Error: unexpected data at offset %d", offset)
+		}
+	}
+	// ReadAt at the stream offset continues the stream.
+	if _, err = object.ReadAt(buf, 0); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !bytes.Equal(buf, data[:100]) {
+		t.Fatal("Error: unexpected data at offset 0")
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	expected := []string{"", "bytes=500-599", "bytes=100-199", "bytes=1000-1023"}
+	if strings.Join(ranges, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Error: expected ranges %v, got %v", expected, ranges)
+	}
+}
+
+// Benchmarks allocations of repeated ReadAt calls into a preallocated
+// buffer.
+func BenchmarkObjectReadAt(b *testing.B) {
+	data := bytes.Repeat([]byte("a"), 1024*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "object", time.Now(), bytes.NewReader(data))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		b.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		b.Fatal("Error:", err)
+	}
+	object, err := c.GetObject("bucket", "object")
+	if err != nil {
+		b.Fatal("Error:", err)
+	}
+	defer object.Close()
+	buf := make([]byte, 64*1024)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		offset := int64(i%15) * int64(len(buf))
+		if _, err = object.ReadAt(buf, offset); err != nil {
+			b.Fatal("Error:", err)
+		}
+	}
+}
+
+// Tests stat of many objects with bounded concurrency.
+func TestStatObjects(t *testing.T) {
+	var mutex sync.Mutex
+	var inFlight, maxInFlight int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mutex.Unlock()
+		defer func() {
+			mutex.Lock()
+			inFlight--
+			mutex.Unlock()
+		}()
+		time.Sleep(10 * time.Millisecond)
+		if strings.HasPrefix(r.URL.Path, "/bucket/missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(r.URL.Path)))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	objectNames := make(chan string)
+	go func() {
+		defer close(objectNames)
+		for i := 0; i < 20; i++ {
+			objectNames <- fmt.Sprintf("object%d", i)
+		}
+		objectNames <- "missing"
+	}()
+
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	results := make(map[string]ObjectInfo)
+	for objInfo := range c.StatObjects("bucket", objectNames, 4, doneCh) {
+		results[objInfo.Key] = objInfo
+	}
+	if len(results) != 21 {
+		t.Fatalf("Error: expected 21 results, got %d", len(results))
+	}
+	for i := 0; i < 20; i++ {
+		objectName := fmt.Sprintf("object%d", i)
+		objInfo := results[objectName]
+		if objInfo.Err != nil {
+			t.Fatal("Error:", objInfo.Err)
+		}
+		if objInfo.Size != int64(len("/bucket/"+objectName)) {
+			t.Fatalf("Error: unexpected size %d for %s", objInfo.Size, objectName)
+		}
+	}
+	if results["missing"].Err == nil {
+		t.Fatal("Error: stat of missing object should fail")
+	}
+	if maxInFlight > 4 {
+		t.Fatalf("Error: expected at most 4 requests in flight, got %d", maxInFlight)
+	}
+
+	// Invalid concurrency.
+	objInfo := <-c.StatObjects("bucket", objectNames, 0, doneCh)
+	if objInfo.Err == nil {
+		t.Fatal("Error: zero concurrency should fail")
+	}
+}
+
+// Tests signing of requests built by the caller.
+func TestSignRequest(t *testing.T) {
+	c, err := New("localhost:9000", "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	payload := []byte("select payload")
+	req, err := http.NewRequest("POST", "http://localhost:9000/bucket/object?select&select-type=2", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SignV4Request(req, "eu-west-1"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.HasPrefix(req.Header.Get("Authorization"), signV4Algorithm+" Credential=ACCESS-KEY/") ||
+		!strings.Contains(req.Header.Get("Authorization"), "/eu-west-1/s3/aws4_request") {
+		t.Fatalf("Error: unexpected authorization %s", req.Header.Get("Authorization"))
+	}
+	if req.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum256(payload)) {
+		t.Fatal("Error: payload sha256 sum is not set")
+	}
+	// Body is still readable after signing.
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !bytes.Equal(body, payload) {
+		t.Fatal("Error: body was not preserved")
+	}
+
+	req, err = http.NewRequest("GET", "http://localhost:9000/bucket/object", nil)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SignV2Request(req); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.HasPrefix(req.Header.Get("Authorization"), signV2Algorithm+" ACCESS-KEY:") {
+		t.Fatalf("Error: unexpected authorization %s", req.Header.Get("Authorization"))
+	}
+
+	// Anonymous clients cannot sign.
+	c, err = New("localhost:9000", "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SignV4Request(req, ""); err == nil {
+		t.Fatal("Error: anonymous client should not sign")
+	}
+}
+
+// encodeSelectMessage - encodes an event stream message with string
+// headers.
+func encodeSelectMessage(headers [][2]string, payload []byte) []byte {
+	var headerBytes bytes.Buffer
+	for _, header := range headers {
+		headerBytes.WriteByte(byte(len(header[0])))
+		headerBytes.WriteString(header[0])
+		headerBytes.WriteByte(selectHeaderValueString)
+		binary.Write(&headerBytes, binary.BigEndian, uint16(len(header[1])))
+		headerBytes.WriteString(header[1])
+	}
+	var message bytes.Buffer
+	binary.Write(&message, binary.BigEndian, uint32(selectPreludeLength+headerBytes.Len()+len(payload)+selectMessageCRCLength))
+	binary.Write(&message, binary.BigEndian, uint32(headerBytes.Len()))
+	binary.Write(&message, binary.BigEndian, crc32.ChecksumIEEE(message.Bytes()))
+	message.Write(headerBytes.Bytes())
+	message.Write(payload)
+	binary.Write(&message, binary.BigEndian, crc32.ChecksumIEEE(message.Bytes()))
+	return message.Bytes()
+}
+
+// Tests decoding of SelectObjectContent event streams.
+func TestSelectObjectContent(t *testing.T) {
+	recordsEvent := func(records string) []byte {
+		return encodeSelectMessage([][2]string{
+			{":message-type", "event"},
+			{":event-type", "Records"},
+			{":content-type", "application/octet-stream"},
+		}, []byte(records))
+	}
+	statsEvent := encodeSelectMessage([][2]string{
+		{":message-type", "event"},
+		{":event-type", "Stats"},
+	}, []byte("<Stats><BytesScanned>100</BytesScanned><BytesProcessed>100</BytesProcessed><BytesReturned>8</BytesReturned></Stats>"))
+	endEvent := encodeSelectMessage([][2]string{
+		{":message-type", "event"},
+		{":event-type", "End"},
+	}, nil)
+	errorEvent := encodeSelectMessage([][2]string{
+		{":message-type", "error"},
+		{":error-code", "CSVParsingError"},
+		{":error-message", "Invalid CSV."},
+	}, nil)
+
+	var stream []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.Method != "POST" || query.Get("select-type") != "2" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if _, ok := query["select"]; !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var request selectObjectContentRequest
+		if err := xml.NewDecoder(r.Body).Decode(&request); err != nil || request.ExpressionType != "SQL" || request.InputSerialization.CSV == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write(stream)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	opts := SelectOptions{
+		Expression: "select s._1 from S3Object s",
+		InputSerialization: SelectInputSerialization{
+			CSV: &CSVInputOptions{FileHeaderInfo: "NONE"},
+		},
+		OutputSerialization: SelectOutputSerialization{
+			CSV: &CSVOutputOptions{},
+		},
+	}
+
+	// Records are concatenated, stats are set once done.
+	stream = bytes.Join([][]byte{recordsEvent("a\nb\n"), recordsEvent("c\nd\n"), statsEvent, endEvent}, nil)
+	results, err := c.SelectObjectContent("bucket", "object.csv", opts)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	records, err := ioutil.ReadAll(results)
+	results.Close()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if string(records) != "a\nb\nc\nd\n" {
+		t.Fatalf("Error: unexpected records %q", records)
+	}
+	if stats := results.Stats(); stats == nil || stats.BytesScanned != 100 || stats.BytesReturned != 8 {
+		t.Fatalf("Error: unexpected stats %v", stats)
+	}
+
+	// Server side errors are returned after the records sent before.
+	stream = bytes.Join([][]byte{recordsEvent("a\n"), errorEvent}, nil)
+	results, err = c.SelectObjectContent("bucket", "object.csv", opts)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	records, err = ioutil.ReadAll(results)
+	results.Close()
+	if string(records) != "a\n" || ToErrorResponse(err).Code != "CSVParsingError" {
+		t.Fatalf("Error: unexpected records %q and error %v", records, err)
+	}
+
+	// Streams without end event are truncated.
+	stream = recordsEvent("a\n")
+	results, err = c.SelectObjectContent("bucket", "object.csv", opts)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	_, err = ioutil.ReadAll(results)
+	results.Close()
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("Error: expected unexpected EOF, got %v", err)
+	}
+
+	// Corrupted messages are rejected.
+	stream = recordsEvent("a\n")
+	stream[len(stream)-1] ^= 0xff
+	results, err = c.SelectObjectContent("bucket", "object.csv", opts)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	_, err = ioutil.ReadAll(results)
+	results.Close()
+	if ToErrorResponse(err).Code != "InternalError" {
+		t.Fatalf("Error: expected checksum mismatch, got %v", err)
+	}
+
+	// Empty SQL is rejected before sending.
+	opts.Expression = " "
+	if _, err = c.SelectObjectContent("bucket", "object.csv", opts); err == nil {
+		t.Fatal("Error: empty SQL expression should fail")
+	}
+}
+
+// Tests upload and download of empty objects.
+func TestPutObjectEmpty(t *testing.T) {
+	var mutex sync.Mutex
+	objects := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch r.Method {
+		case "PUT":
+			// Empty payloads must have an explicit length.
+			if len(r.TransferEncoding) != 0 || r.Header.Get("Content-Length") != "0" || r.URL.Query().Get("uploadId") != "" {
+				w.WriteHeader(http.StatusLengthRequired)
+				return
+			}
+			data, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			objects[r.URL.Path] = data
+		case "HEAD", "GET":
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			w.Write(data)
+		default:
+			// Empty objects must not use multipart.
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	readers := map[string]io.Reader{
+		"sized":   bytes.NewReader(nil),
+		"unsized": io.LimitReader(bytes.NewReader(nil), 0),
+	}
+	for objectName, reader := range readers {
+		n, err := c.PutObject("bucket", objectName, reader, "")
+		if err != nil {
+			t.Fatalf("Error: %s: %v", objectName, err)
+		}
+		if n != 0 {
+			t.Fatalf("Error: %s: expected 0 bytes uploaded, got %d", objectName, n)
+		}
+
+		object, err := c.GetObject("bucket", objectName)
+		if err != nil {
+			t.Fatalf("Error: %s: %v", objectName, err)
+		}
+		objInfo, err := object.Stat()
+		if err != nil {
+			t.Fatalf("Error: %s: %v", objectName, err)
+		}
+		if objInfo.Size != 0 {
+			t.Fatalf("Error: %s: expected size 0, got %d", objectName, objInfo.Size)
+		}
+		if n, err := object.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+			t.Fatalf("Error: %s: expected immediate EOF, got %d, %v", objectName, n, err)
+		}
+		object.Close()
+	}
+}
+
+// Tests listing buckets of a region.
+func TestListBucketsByRegion(t *testing.T) {
+	locations := map[string]string{
+		"bucket1": "",
+		"bucket2": "eu-west-1",
+		"bucket3": "EU",
+		"bucket4": "us-west-2",
+		"bucket5": "error",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			var buckets string
+			for _, bucketName := range []string{"bucket1", "bucket2", "bucket3", "bucket4", "bucket5"} {
+				buckets += "<Bucket><Name>" + bucketName + "</Name></Bucket>"
+			}
+			fmt.Fprint(w, "<ListAllMyBucketsResult><Buckets>"+buckets+"</Buckets></ListAllMyBucketsResult>")
+			return
+		}
+		location := locations[strings.Trim(r.URL.Path, "/")]
+		if _, ok := r.URL.Query()["location"]; !ok || location == "error" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, "<LocationConstraint>"+location+"</LocationConstraint>")
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	buckets, locationErrs, err := c.ListBucketsByRegion("eu-west-1")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(buckets) != 2 || buckets[0].Name != "bucket2" || buckets[1].Name != "bucket3" {
+		t.Fatalf("Error: unexpected buckets %v", buckets)
+	}
+	if len(locationErrs) != 1 || locationErrs[0].BucketName != "bucket5" || locationErrs[0].Err == nil {
+		t.Fatalf("Error: unexpected location errors %v", locationErrs)
+	}
+	// Resolved locations are cached.
+	if location, ok := c.bucketLocCache.Get("bucket1"); !ok || location != "us-east-1" {
+		t.Fatalf("Error: expected cached location us-east-1, got %s", location)
+	}
+
+	if _, _, err = c.ListBucketsByRegion(""); err == nil {
+		t.Fatal("Error: empty region should fail")
+	}
+}
+
+// Tests requests redirected to the region of a bucket are retried once
+// in that region.
+func TestRegionRedirect(t *testing.T) {
+	var mutex sync.Mutex
+	var regions []string
+	bucketRegion := "eu-west-1"
+	isMoving := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		mutex.Lock()
+		defer mutex.Unlock()
+		// Region the request is signed for.
+		credential := strings.Split(r.Header.Get("Authorization"), "/")
+		region := credential[2]
+		regions = append(regions, region)
+		if isMoving {
+			// Bucket is always in another region.
+			bucketRegion = region + "-next"
+		}
+		if region != bucketRegion {
+			w.Header().Set("x-amz-bucket-region", bucketRegion)
+			// Redirects must not be followed by the http client.
+			w.Header().Set("Location", "http://"+r.Host+"/redirected")
+			if r.Method == "HEAD" {
+				w.WriteHeader(http.StatusTemporaryRedirect)
+				return
+			}
+			w.WriteHeader(http.StatusMovedPermanently)
+			fmt.Fprint(w, "<Error><Code>PermanentRedirect</Code><Message>The bucket you are attempting to access must be addressed using the specified endpoint.</Message></Error>")
+			return
+		}
+		if r.URL.Path == "/redirected" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Method == "HEAD" {
+			w.Header().Set("x-amz-bucket-region", bucketRegion)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", "\"etag\"")
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	// PUT is retried in the region of the bucket.
+	if _, err = c.PutObject("bucket", "object", bytes.NewReader([]byte("data")), ""); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if strings.Join(regions, ",") != "us-east-1,eu-west-1" {
+		t.Fatalf("Error: unexpected signing regions %v", regions)
+	}
+	if location, _ := c.bucketLocCache.Get("bucket"); location != "eu-west-1" {
+		t.Fatalf("Error: expected cached location eu-west-1, got %s", location)
+	}
+
+	// Errors in the region of the bucket are not retried.
+	regions = nil
+	if _, err = c.StatObject("bucket", "object"); ToErrorResponse(err).Code != "NoSuchKey" {
+		t.Fatalf("Error: expected NoSuchKey, got %v", err)
+	}
+	if len(regions) != 1 {
+		t.Fatalf("Error: expected a single request, got %v", regions)
+	}
+
+	// Redirects between regions are retried only once.
+	regions = nil
+	isMoving = true
+	c.bucketLocCache.Set("bucket", "us-east-1")
+	if _, err = c.StatObject("bucket", "object"); err == nil {
+		t.Fatal("Error: redirect loop should fail")
+	}
+	if strings.Join(regions, ",") != "us-east-1,us-east-1-next" {
+		t.Fatalf("Error: unexpected signing regions %v", regions)
+	}
+}
+
+// Tests transport timeouts of the client.
+func TestTransportTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SetConnectTimeout(time.Second); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SetIdleConnTimeout(time.Minute); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SetResponseHeaderTimeout(50 * time.Millisecond); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if c.SetResponseHeaderTimeout(-1) == nil {
+		t.Fatal("Error: negative timeout should fail")
+	}
+
+	// Default transport shared by all clients is not modified.
+	defaultTransport := http.DefaultTransport.(*http.Transport)
+	if defaultTransport.ResponseHeaderTimeout != 0 || defaultTransport.IdleConnTimeout == time.Minute {
+		t.Fatal("Error: default transport should not be modified")
+	}
+	tr := c.httpClient.Transport.(*http.Transport)
+	if tr.ResponseHeaderTimeout != 50*time.Millisecond || tr.IdleConnTimeout != time.Minute {
+		t.Fatal("Error: transport timeouts are not set")
+	}
+
+	start := time.Now()
+	if err = c.BucketExists("bucket"); err == nil {
+		t.Fatal("Error: request should time out")
+	}
+	if time.Since(start) > 400*time.Millisecond {
+		t.Fatalf("Error: request timed out after %v", time.Since(start))
+	}
+
+	// Only transports of type *http.Transport can be configured.
+	c.SetCustomTransport(customRoundTripper{})
+	if c.SetIdleConnTimeout(time.Minute) == nil {
+		t.Fatal("Error: custom round tripper should not be configured")
+	}
+}
+
+type customRoundTripper struct{}
+
+func (customRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// Tests operations on a closed object return ErrAlreadyClosed.
+func TestObjectAlreadyClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "object", time.Now(), bytes.NewReader([]byte("data")))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	object, err := c.GetObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = object.Close(); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = object.Close(); err != ErrAlreadyClosed {
+		t.Fatalf("Error: expected ErrAlreadyClosed, got %v", err)
+	}
+	if _, err = object.Read(make([]byte, 1)); err != ErrAlreadyClosed {
+		t.Fatalf("Error: expected ErrAlreadyClosed, got %v", err)
+	}
+	if _, err = object.ReadAt(make([]byte, 1), 1); err != ErrAlreadyClosed {
+		t.Fatalf("Error: expected ErrAlreadyClosed, got %v", err)
+	}
+}
+
+// Tests upload and download bandwidth limits hold across concurrent
+// requests.
+func TestBandwidthLimit(t *testing.T) {
+	const limit = 64 * 1024
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "PUT":
+			if _, err := ioutil.ReadAll(r.Body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("ETag", `"etag"`)
+		case "GET":
+			w.Write(make([]byte, 2*limit))
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SetUploadBandwidthLimit(-1); err == nil {
+		t.Fatal("Error: negative limit should fail")
+	}
+	if err = c.SetUploadBandwidthLimit(limit); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SetDownloadBandwidthLimit(limit); err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	// Two concurrent uploads of a full bucket each need another
+	// second of tokens.
+	start := time.Now()
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = c.PutObject("bucket", "object", bytes.NewReader(make([]byte, limit)), "")
+		}(i)
+	}
+	wg.Wait()
+	for _, err = range errs {
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Fatalf("Error: uploads exceeded the limit, took %v", elapsed)
+	}
+
+	start = time.Now()
+	resp, err := c.executeMethod("GET", requestMetadata{bucketName: "bucket", objectName: "object"})
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || len(data) != 2*limit {
+		t.Fatalf("Error: unexpected download of %d bytes, %v", len(data), err)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Fatalf("Error: download exceeded the limit, took %v", elapsed)
+	}
+
+	// Removing the limits.
+	if err = c.SetUploadBandwidthLimit(0); err != nil || c.uploadLimiter != nil {
+		t.Fatal("Error: upload limit not removed", err)
+	}
+}
+
+// Tests verification of multipart upload ETags for stream, file and
+// ReaderAt uploads.
+func TestMultipartETagVerification(t *testing.T) {
+	var mutex sync.Mutex
+	var isCorrupted bool
+	partSums := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		query := r.URL.Query()
+		switch {
+		case r.Method == "GET" && len(query["uploads"]) > 0:
+			fmt.Fprint(w, `<ListMultipartUploadsResult><IsTruncated>false</IsTruncated></ListMultipartUploadsResult>`)
+		case r.Method == "POST" && len(query["uploads"]) > 0:
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><UploadId>upload-id</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == "PUT" && query.Get("uploadId") == "upload-id":
+			data, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			sum := md5.Sum(data)
+			partSums[query.Get("partNumber")] = sum[:]
+			w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+		case r.Method == "POST" && query.Get("uploadId") == "upload-id":
+			hashMD5 := md5.New()
+			for i := 1; i <= len(partSums); i++ {
+				hashMD5.Write(partSums[strconv.Itoa(i)])
+			}
+			if isCorrupted {
+				hashMD5.Write([]byte("corrupted"))
+			}
+			fmt.Fprintf(w, `<CompleteMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><ETag>"%s-%d"</ETag></CompleteMultipartUploadResult>`,
+				hex.EncodeToString(hashMD5.Sum(nil)), len(partSums))
+			partSums = make(map[string][]byte)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+	c.SetMultipartETagVerification(true)
+
+	data := bytes.Repeat([]byte("a"), minPartSize+1024)
+	file, err := ioutil.TempFile("", "minio-etag")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	if _, err = file.Write(data); err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	uploads := map[string]func() error{
+		"stream": func() error {
+			_, err := c.PutObject("bucket", "object", io.MultiReader(bytes.NewReader(data)), "")
+			return err
+		},
+		"readat": func() error {
+			_, err := c.PutObject("bucket", "object", bytes.NewReader(data), "")
+			return err
+		},
+		"file": func() error {
+			_, err := c.FPutObject("bucket", "object", file.Name(), "")
+			return err
+		},
+	}
+	for name, upload := range uploads {
+		isCorrupted = false
+		if err = upload(); err != nil {
+			t.Fatalf("Error: %s: %v", name, err)
+		}
+		isCorrupted = true
+		if err = upload(); ToErrorResponse(err).Code != "BadDigest" {
+			t.Fatalf("Error: %s: expected BadDigest, got %v", name, err)
+		}
+	}
+}
+
+// Tests custom headers are sent and signed on all requests.
+func TestCustomHeader(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := NewV4(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+	c.SetCustomHeader("x-amz-request-payer", "requester")
+
+	if err = c.BucketExists("bucket"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if header.Get("X-Amz-Request-Payer") != "requester" {
+		t.Fatalf("Error: custom header not sent, got %v", header)
+	}
+	if !strings.Contains(header.Get("Authorization"), "x-amz-request-payer") {
+		t.Fatalf("Error: custom header not signed, got %s", header.Get("Authorization"))
+	}
+
+	// Headers of a single call are added to the client headers.
+	call := c.WithCustomHeader(http.Header{"x-amz-expected-bucket-owner": {"123456789012"}})
+	if err = call.BucketExists("bucket"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if header.Get("X-Amz-Expected-Bucket-Owner") != "123456789012" || header.Get("X-Amz-Request-Payer") != "requester" {
+		t.Fatalf("Error: call headers not sent, got %v", header)
+	}
+	if err = c.BucketExists("bucket"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if header.Get("X-Amz-Expected-Bucket-Owner") != "" {
+		t.Fatal("Error: call headers leaked into the client")
+	}
+
+	// An empty value removes the header, copies are unchanged.
+	c.SetCustomHeader("x-amz-request-payer", "")
+	if err = c.BucketExists("bucket"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if header.Get("X-Amz-Request-Payer") != "" {
+		t.Fatal("Error: custom header not removed")
+	}
+	if err = call.BucketExists("bucket"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if header.Get("X-Amz-Request-Payer") != "requester" {
+		t.Fatal("Error: removing a header changed copies of the client")
+	}
+}
+
+// Tests the host header is overridden and signed, while requests still
+// go to the endpoint.
+func TestCustomHost(t *testing.T) {
+	var host string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := NewV4(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	c.SetCustomHost("gateway.example.com")
+	if err = c.BucketExists("bucket"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if host != "gateway.example.com" {
+		t.Fatalf("Error: expected custom host, got %s", host)
+	}
+	req, err := http.NewRequest("GET", server.URL+"/bucket/", nil)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	req.Host = "gateway.example.com"
+	if headers := getCanonicalHeaders(*req, ignoredHeaders); headers != "host:gateway.example.com\n" {
+		t.Fatalf("Error: expected custom host signed, got %q", headers)
+	}
+
+	// An empty host restores the endpoint.
+	c.SetCustomHost("")
+	if err = c.BucketExists("bucket"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if host != u.Host {
+		t.Fatalf("Error: expected endpoint host %s, got %s", u.Host, host)
+	}
+}
+
+// Tests endpoints with certificates of private authorities are trusted
+// once configured.
+func TestTLSConfig(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	// Rejected handshakes are expected.
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", false)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.SetConnectTimeout(5 * time.Second)
+	if err = c.BucketExists("bucket"); err == nil {
+		t.Fatal("Error: unknown certificate authority should fail")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	config := &tls.Config{RootCAs: pool}
+	if err = c.SetTLSConfig(config); err != nil {
+		t.Fatal("Error:", err)
+	}
+	// Later changes to the config have no effect.
+	config.RootCAs = nil
+	if err = c.BucketExists("bucket"); err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	if err = c.SetTLSConfig(nil); err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.httpClient.Transport.(*http.Transport).CloseIdleConnections()
+	if err = c.BucketExists("bucket"); err == nil {
+		t.Fatal("Error: default config should not trust the certificate authority")
+	}
+}
+
+// Tests requests to Requester Pays buckets accept the charges.
+func TestRequesterPays(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-amz-request-payer") != "requester" ||
+			!strings.Contains(r.Header.Get("Authorization"), "x-amz-request-payer") {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+			return
+		}
+		w.Header().Set("Content-Length", "5")
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == "GET" {
+			w.Write([]byte("hello"))
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := NewV4(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	if _, err = c.StatObject("bucket", "object"); ToErrorResponse(err).Code != "AccessDenied" {
+		t.Fatal("Error: expected AccessDenied, got", err)
+	}
+	if _, err = c.WithRequesterPays().StatObject("bucket", "object"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.SetRequesterPays(true)
+	if _, err = c.StatObject("bucket", "object"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.SetRequesterPays(false)
+	if _, err = c.StatObject("bucket", "object"); ToErrorResponse(err).Code != "AccessDenied" {
+		t.Fatal("Error: expected AccessDenied, got", err)
+	}
+}
+
+// Tests force removal of buckets with objects and incomplete uploads.
+func TestRemoveBucketWithForce(t *testing.T) {
+	var mutex sync.Mutex
+	var objects, uploads map[string]bool
+	var isBucketRemoved bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		query := r.URL.Query()
+		switch {
+		case r.Method == "GET" && len(query["versioning"]) > 0:
+			fmt.Fprint(w, `<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></VersioningConfiguration>`)
+		case r.Method == "GET" && len(query["uploads"]) > 0:
+			fmt.Fprint(w, `<ListMultipartUploadsResult><IsTruncated>false</IsTruncated>`)
+			for key := range uploads {
+				fmt.Fprintf(w, `<Upload><Key>%s</Key><UploadId>upload-%s</UploadId><Initiated>2016-01-02T15:04:05.000Z</Initiated></Upload>`, key, key)
+			}
+			fmt.Fprint(w, `</ListMultipartUploadsResult>`)
+		case r.Method == "DELETE" && query.Get("uploadId") != "":
+			delete(uploads, strings.TrimPrefix(r.URL.Path, "/bucket/"))
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == "GET":
+			fmt.Fprint(w, `<ListBucketResult><Name>bucket</Name><IsTruncated>false</IsTruncated>`)
+			for key := range objects {
+				fmt.Fprintf(w, `<Contents><Key>%s</Key><Size>1</Size></Contents>`, key)
+			}
+			fmt.Fprint(w, `</ListBucketResult>`)
+		case r.Method == "POST" && len(query["delete"]) > 0:
+			var deleteReq deleteMultiObjects
+			if err := xml.NewDecoder(r.Body).Decode(&deleteReq); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `<DeleteResult>`)
+			for _, object := range deleteReq.Objects {
+				if object.Key == "locked" {
+					fmt.Fprint(w, `<Error><Key>locked</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+					continue
+				}
+				delete(objects, object.Key)
+			}
+			fmt.Fprint(w, `</DeleteResult>`)
+		case r.Method == "DELETE" && r.URL.Path == "/bucket/":
+			if len(objects) != 0 || len(uploads) != 0 {
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprint(w, `<Error><Code>BucketNotEmpty</Code><Message>The bucket you tried to delete is not empty</Message></Error>`)
+				return
+			}
+			isBucketRemoved = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	// Objects which cannot be removed keep the bucket in place.
+	objects = map[string]bool{"a": true, "dir/b": true, "locked": true}
+	uploads = map[string]bool{"c": true}
+	if err = c.RemoveBucketWithForce("bucket", doneCh); ToErrorResponse(err).Code != "AccessDenied" {
+		t.Fatal("Error: expected AccessDenied, got", err)
+	}
+	if isBucketRemoved || len(objects) != 1 || len(uploads) != 0 {
+		t.Fatalf("Error: unexpected state after failure, objects %v, uploads %v", objects, uploads)
+	}
+
+	objects = map[string]bool{"a": true, "dir/b": true}
+	uploads = map[string]bool{"c": true}
+	if err = c.RemoveBucketWithForce("bucket", doneCh); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !isBucketRemoved {
+		t.Fatal("Error: bucket was not removed")
+	}
+}
+
+// Tests downloading objects to files with and without syncing.
+func TestFGetObjectSync(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		http.ServeContent(w, r, "object", time.Now(), strings.NewReader("hello world"))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	dir, err := ioutil.TempDir("", "minio-fget")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, disabled := range []bool{false, true} {
+		c.SetFileSyncDisabled(disabled)
+		filePath := filepath.Join(dir, "nested", strconv.FormatBool(disabled))
+		if err = c.FGetObject("bucket", "object", filePath); err != nil {
+			t.Fatal("Error:", err)
+		}
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if string(data) != "hello world" {
+			t.Fatalf("Error: unexpected file content %q", data)
+		}
+	}
+}
+
+// Tests resumed downloads of part files, also from servers ignoring
+// the requested range.
+func TestFGetObjectResume(t *testing.T) {
+	var ranges []string
+	var ignoreRange bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == "GET" {
+			ranges = append(ranges, r.Header.Get("Range"))
+			if ignoreRange {
+				r.Header.Del("Range")
+			}
+		}
+		http.ServeContent(w, r, "object", time.Time{}, strings.NewReader("hello world"))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	dir, err := ioutil.TempDir("", "minio-fget")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i, ignore := range []bool{false, true} {
+		ranges, ignoreRange = nil, ignore
+		filePath := filepath.Join(dir, strconv.Itoa(i))
+		if err = ioutil.WriteFile(filePath+"etag.part.minio", []byte("hello"), 0600); err != nil {
+			t.Fatal("Error:", err)
+		}
+		if err = c.FGetObject("bucket", "object", filePath); err != nil {
+			t.Fatalf("Test %d: Error: %s", i+1, err)
+		}
+		if len(ranges) != 1 || ranges[0] != "bytes=5-" {
+			t.Fatalf("Test %d: Error: unexpected ranges %v", i+1, ranges)
+		}
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if string(data) != "hello world" {
+			t.Fatalf("Test %d: Error: unexpected file content %q", i+1, data)
+		}
+	}
+}
+
+
+// Tests failed multipart uploads are aborted unless resumable.
+func TestAbortFailedUpload(t *testing.T) {
+	var mutex sync.Mutex
+	var aborts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		query := r.URL.Query()
+		switch {
+		case r.Method == "GET" && len(query["uploads"]) > 0:
+			fmt.Fprint(w, `<ListMultipartUploadsResult><IsTruncated>false</IsTruncated></ListMultipartUploadsResult>`)
+		case r.Method == "POST" && len(query["uploads"]) > 0:
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><UploadId>upload-id</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == "PUT" && query.Get("uploadId") == "upload-id":
+			ioutil.ReadAll(r.Body)
+			w.Header().Set("ETag", `"etag"`)
+		case r.Method == "DELETE" && query.Get("uploadId") == "upload-id":
+			aborts++
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+	var trace bytes.Buffer
+	c.TraceOn(&trace)
+
+	// Reader failing after the first part.
+	readErr := errors.New("read failed")
+	failingReader := func() io.Reader {
+		pipeReader, pipeWriter := io.Pipe()
+		go func() {
+			pipeWriter.Write(make([]byte, minPartSize+1024))
+			pipeWriter.CloseWithError(readErr)
+		}()
+		return pipeReader
+	}
+
+	if _, err = c.PutObject("bucket", "object", failingReader(), ""); err != readErr {
+		t.Fatal("Error: expected read error, got", err)
+	}
+	if aborts != 1 {
+		t.Fatalf("Error: expected 1 abort, got %d", aborts)
+	}
+	if !strings.Contains(trace.String(), "Aborted multipart upload upload-id of bucket/object") {
+		t.Fatal("Error: abort was not traced")
+	}
+
+	c.SetResumableUploads(true)
+	if _, err = c.PutObject("bucket", "object", failingReader(), ""); err != readErr {
+		t.Fatal("Error: expected read error, got", err)
+	}
+	if aborts != 1 {
+		t.Fatalf("Error: resumable upload was aborted")
+	}
+}
+
+// Tests the metadata of created objects is returned for single PUT
+// and multipart uploads.
+func TestPutObjectWithInfo(t *testing.T) {
+	const expiration = `expiry-date="Fri, 23 Dec 2016 00:00:00 GMT", rule-id="cleanup"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == "GET" && len(query["uploads"]) > 0:
+			fmt.Fprint(w, `<ListMultipartUploadsResult><IsTruncated>false</IsTruncated></ListMultipartUploadsResult>`)
+		case r.Method == "POST" && len(query["uploads"]) > 0:
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><UploadId>upload-id</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == "PUT" && query.Get("uploadId") != "":
+			ioutil.ReadAll(r.Body)
+			w.Header().Set("ETag", `"part-etag"`)
+		case r.Method == "POST" && query.Get("uploadId") != "":
+			w.Header().Set("x-amz-version-id", "multipart-version")
+			w.Header().Set("x-amz-expiration", expiration)
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><ETag>"multipart-etag-2"</ETag></CompleteMultipartUploadResult>`)
+		case r.Method == "PUT":
+			ioutil.ReadAll(r.Body)
+			w.Header().Set("ETag", `"single-etag"`)
+			w.Header().Set("x-amz-version-id", "single-version")
+			w.Header().Set("x-amz-expiration", expiration)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	testCases := []struct {
+		size      int
+		etag      string
+		versionID string
+	}{
+		{11, "single-etag", "single-version"},
+		{minPartSize + 1, "multipart-etag-2", "multipart-version"},
+	}
+	for _, testCase := range testCases {
+		objInfo, err := c.PutObjectWithInfo("bucket", "object", bytes.NewReader(make([]byte, testCase.size)), "")
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if objInfo.Key != "object" || objInfo.Size != int64(testCase.size) || objInfo.ETag != testCase.etag ||
+			objInfo.VersionID != testCase.versionID || objInfo.Expiration != expiration {
+			t.Fatalf("Error: unexpected object info %#v", objInfo)
+		}
+	}
+}
+
+// Tests idle connections are kept for reuse under concurrency.
+func TestMaxIdleConnsPerHost(t *testing.T) {
+	var newConns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&newConns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if c.SetMaxIdleConnsPerHost(-1) == nil {
+		t.Fatal("Error: negative maximum should fail")
+	}
+	const concurrency = 16
+	if err = c.SetMaxIdleConnsPerHost(concurrency); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if http.DefaultTransport.(*http.Transport).MaxIdleConnsPerHost == concurrency {
+		t.Fatal("Error: default transport should not be modified")
+	}
+
+	// Later rounds reuse the connections of the first one.
+	for round := 0; round < 5; round++ {
+		var wg sync.WaitGroup
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := c.BucketExists("bucket"); err != nil {
+					t.Error("Error:", err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+	if n := atomic.LoadInt32(&newConns); n > 2*concurrency {
+		t.Fatalf("Error: connections were not reused, %d opened", n)
+	}
+}
+
+// Benchmarks concurrent requests with the default and a raised number
+// of idle connections per host.
+func BenchmarkMaxIdleConnsPerHost(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		b.Fatal("Error:", err)
+	}
+	for _, maxIdle := range []int{2, 64} {
+		b.Run(strconv.Itoa(maxIdle), func(b *testing.B) {
+			c, err := New(u.Host, "", "", true)
+			if err != nil {
+				b.Fatal("Error:", err)
+			}
+			if err = c.SetMaxIdleConnsPerHost(maxIdle); err != nil {
+				b.Fatal("Error:", err)
+			}
+			b.SetParallelism(16)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if err := c.BucketExists("bucket"); err != nil {
+						b.Error("Error:", err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
+
+// Tests closing partially read objects re-uses or closes their
+// connections instead of leaking them.
+func TestObjectCloseReleasesConnection(t *testing.T) {
+	var newConns, closedConns int32
+	objects := map[string][]byte{
+		"/bucket/small": make([]byte, 64*1024),
+		"/bucket/large": make([]byte, 4*maxDrainSize),
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+		http.ServeContent(w, r, "object", time.Now(), bytes.NewReader(objects[r.URL.Path]))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateNew:
+			atomic.AddInt32(&newConns, 1)
+		case http.StateClosed, http.StateHijacked:
+			atomic.AddInt32(&closedConns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	openAndClose := func(objectName string) {
+		for i := 0; i < 100; i++ {
+			object, err := c.GetObject("bucket", objectName)
+			if err != nil {
+				t.Fatal("Error:", err)
+			}
+			if _, err = object.Read(make([]byte, 1024)); err != nil {
+				t.Fatal("Error:", err)
+			}
+			if err = object.Close(); err != nil {
+				t.Fatal("Error:", err)
+			}
+		}
+	}
+
+	// Small remainders are drained, connections are re-used.
+	openAndClose("small")
+	if n := atomic.LoadInt32(&newConns); n > 10 {
+		t.Fatalf("Error: connections were not re-used, %d opened", n)
+	}
+
+	// Large remainders close the connection.
+	openAndClose("large")
+	for i := 0; i < 100; i++ {
+		if atomic.LoadInt32(&newConns)-atomic.LoadInt32(&closedConns) <= 2 {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("Error: connections leaked, %d opened, %d closed", atomic.LoadInt32(&newConns), atomic.LoadInt32(&closedConns))
+}
+
+// Tests uploader sends the parts of streams in parallel.
+func TestUploader(t *testing.T) {
+	var mutex sync.Mutex
+	var inFlight, maxInFlight int
+	parts := make(map[int][]byte)
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == "GET" && len(query["uploads"]) > 0:
+			fmt.Fprint(w, `<ListMultipartUploadsResult><IsTruncated>false</IsTruncated></ListMultipartUploadsResult>`)
+		case r.Method == "POST" && len(query["uploads"]) > 0:
+			mutex.Lock()
+			contentType = r.Header.Get("Content-Type")
+			mutex.Unlock()
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><UploadId>upload-id</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == "PUT" && query.Get("uploadId") != "":
+			mutex.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mutex.Unlock()
+			data, _ := ioutil.ReadAll(r.Body)
+			time.Sleep(50 * time.Millisecond)
+			partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+			mutex.Lock()
+			inFlight--
+			parts[partNumber] = data
+			mutex.Unlock()
+			w.Header().Set("ETag", fmt.Sprintf(`"%x"`, md5.Sum(data)))
+		case r.Method == "POST" && query.Get("uploadId") != "":
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><ETag>"etag-4"</ETag></CompleteMultipartUploadResult>`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	data := make([]byte, 3*minPartSize+minPartSize/2)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	uploader := NewUploader(*c)
+	uploader.PartSize = minPartSize
+	uploader.Concurrency = 4
+	progress := bytes.NewReader(make([]byte, len(data)))
+	uploader.Progress = progress
+
+	// Stream of unknown size.
+	n, err := uploader.Upload("bucket", "object.json", struct{ io.Reader }{bytes.NewReader(data)})
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if n != int64(len(data)) {
+		t.Fatalf("Error: expected %d bytes uploaded, got %d", len(data), n)
+	}
+	if len(parts) != 4 {
+		t.Fatalf("Error: expected 4 parts, got %d", len(parts))
+	}
+	var uploaded []byte
+	for i := 1; i <= len(parts); i++ {
+		uploaded = append(uploaded, parts[i]...)
+	}
+	if !bytes.Equal(uploaded, data) {
+		t.Fatal("Error: uploaded parts do not match the data")
+	}
+	if maxInFlight < 2 {
+		t.Fatalf("Error: expected parts uploaded in parallel, at most %d in flight", maxInFlight)
+	}
+	if progress.Len() != 0 {
+		t.Fatalf("Error: expected progress to be read fully, %d bytes left", progress.Len())
+	}
+	if contentType != "application/json" {
+		t.Fatalf("Error: expected content type detected from the name, got %s", contentType)
+	}
+
+	contentType, err = detectReaderContentType("object", bytes.NewReader([]byte("<html>")))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if contentType != "text/html; charset=utf-8" {
+		t.Fatalf("Error: expected content type sniffed from the data, got %s", contentType)
+	}
+}
+
+// Tests downloader assembles ranges downloaded in parallel.
+func TestDownloader(t *testing.T) {
+	data := make([]byte, 3500)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	var mutex sync.Mutex
+	var gets, inFlight, maxInFlight int
+	var isRangeIgnored bool
+	etag := `"etag"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		if r.Method == "GET" {
+			gets++
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+		}
+		w.Header().Set("ETag", etag)
+		if r.Method == "GET" && gets > 1 && etag == `"changing-etag"` {
+			w.Header().Set("ETag", `"new-etag"`)
+		}
+		ignoreRange := isRangeIgnored
+		mutex.Unlock()
+		defer func() {
+			mutex.Lock()
+			if r.Method == "GET" {
+				inFlight--
+			}
+			mutex.Unlock()
+		}()
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if ignoreRange {
+			r.Header.Del("Range")
+		}
+		http.ServeContent(w, r, "object", time.Time{}, bytes.NewReader(data))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	dir, err := ioutil.TempDir("", "minio-downloader")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	downloader := NewDownloader(*c)
+	downloader.ChunkSize = 1000
+	downloader.Concurrency = 3
+
+	testCases := []struct {
+		isRangeIgnored bool
+		expectedGets   int
+	}{
+		{false, 4},
+		// Servers ignoring ranges are read with a single request.
+		{true, 1},
+	}
+	for i, testCase := range testCases {
+		gets, maxInFlight, isRangeIgnored = 0, 0, testCase.isRangeIgnored
+		filePath := filepath.Join(dir, strconv.Itoa(i))
+		if err = downloader.Download("bucket", "object", filePath); err != nil {
+			t.Fatalf("Test %d: Error: %s", i+1, err)
+		}
+		downloaded, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if !bytes.Equal(downloaded, data) {
+			t.Fatalf("Test %d: Error: downloaded data does not match", i+1)
+		}
+		if gets != testCase.expectedGets {
+			t.Fatalf("Test %d: Error: expected %d GET requests, got %d", i+1, testCase.expectedGets, gets)
+		}
+		if !testCase.isRangeIgnored && maxInFlight < 2 {
+			t.Fatalf("Test %d: Error: expected ranges downloaded in parallel", i+1)
+		}
+	}
+
+	// Object overwritten during the download.
+	gets, isRangeIgnored, etag = 0, false, `"changing-etag"`
+	filePath := filepath.Join(dir, "changed")
+	err = downloader.Download("bucket", "object", filePath)
+	if ToErrorResponse(err).Code != "PreconditionFailed" {
+		t.Fatalf("Error: expected object changed error, got %v", err)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(files) != len(testCases) {
+		t.Fatalf("Error: expected incomplete download removed, found %d files", len(files))
+	}
+}
+
+// Tests ranges are only served while the object is unchanged.
+func TestGetObjectRangeIfUnchanged(t *testing.T) {
+	var mutex sync.Mutex
+	data, etag := []byte("hello world"), `"etag-1"`
+	var ifRanges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		ifRanges = append(ifRanges, r.Header.Get("If-Range"))
+		// Overwrite the object after the first request.
+		if len(ifRanges) == 2 {
+			data, etag = []byte("HELLO WORLD"), `"etag-2"`
+		}
+		w.Header().Set("ETag", etag)
+		content := data
+		mutex.Unlock()
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		http.ServeContent(w, r, "object", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	reader, objInfo, err := c.GetObjectRangeIfUnchanged("bucket", "object", 0, 4, "etag-1")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	buf, err := ioutil.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if string(buf) != "hello" || objInfo.ETag != "etag-1" {
+		t.Fatalf("Error: unexpected range %q of %s", buf, objInfo.ETag)
+	}
+	if ifRanges[0] != `"etag-1"` {
+		t.Fatalf("Error: expected If-Range header, got %q", ifRanges[0])
+	}
+
+	// Served the whole overwritten object.
+	if _, _, err = c.GetObjectRangeIfUnchanged("bucket", "object", 6, 10, "etag-1"); ToErrorResponse(err).Code != "PreconditionFailed" {
+		t.Fatalf("Error: expected object changed error, got %v", err)
+	}
+
+	// Downloads are restarted once the object changed.
+	mutex.Lock()
+	data, etag, ifRanges = bytes.Repeat([]byte("a"), 3000), `"etag-3"`, nil
+	mutex.Unlock()
+	dir, err := ioutil.TempDir("", "minio-downloader")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer os.RemoveAll(dir)
+	downloader := NewDownloader(*c)
+	downloader.ChunkSize = 1000
+	downloader.Concurrency = 2
+	filePath := filepath.Join(dir, "object")
+	if err = downloader.Download("bucket", "object", filePath); err != nil {
+		t.Fatal("Error:", err)
+	}
+	downloaded, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if string(downloaded) != "HELLO WORLD" {
+		t.Fatalf("Error: expected the overwritten object downloaded, got %d bytes", len(downloaded))
+	}
+}
+
+// Tests the Expires header is sent on single PUT and multipart uploads
+// and read back by StatObject.
+func TestPutObjectWithExpires(t *testing.T) {
+	const expiration = `expiry-date="Fri, 23 Dec 2016 00:00:00 GMT", rule-id="cleanup"`
+	expires := time.Date(2016, 12, 23, 1, 2, 3, 0, time.FixedZone("CET", 3600))
+	const expiresHeader = "Fri, 23 Dec 2016 00:02:03 GMT"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == "GET" && len(query["uploads"]) > 0:
+			fmt.Fprint(w, `<ListMultipartUploadsResult><IsTruncated>false</IsTruncated></ListMultipartUploadsResult>`)
+		case r.Method == "POST" && len(query["uploads"]) > 0:
+			if r.Header.Get("Expires") != expiresHeader {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><UploadId>upload-id</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == "PUT" && query.Get("uploadId") != "":
+			ioutil.ReadAll(r.Body)
+			w.Header().Set("ETag", `"part-etag"`)
+		case r.Method == "POST" && query.Get("uploadId") != "":
+			w.Header().Set("x-amz-expiration", expiration)
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><ETag>"multipart-etag-2"</ETag></CompleteMultipartUploadResult>`)
+		case r.Method == "PUT":
+			ioutil.ReadAll(r.Body)
+			if r.Header.Get("Expires") != expiresHeader {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("ETag", `"single-etag"`)
+			w.Header().Set("x-amz-expiration", expiration)
+		case r.Method == "HEAD":
+			w.Header().Set("ETag", `"single-etag"`)
+			w.Header().Set("Last-Modified", "Fri, 23 Dec 2016 00:00:00 GMT")
+			w.Header().Set("Content-Length", "11")
+			w.Header().Set("Expires", expiresHeader)
+			w.Header().Set("x-amz-expiration", expiration)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	if _, err = c.PutObjectWithExpires("bucket", "object", bytes.NewReader(nil), "", time.Time{}); err == nil {
+		t.Fatal("Error: zero expires time should be rejected")
+	}
+	for _, size := range []int{11, minPartSize + 1} {
+		objInfo, err := c.PutObjectWithExpires("bucket", "object", bytes.NewReader(make([]byte, size)), "", expires)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if objInfo.Size != int64(size) || objInfo.Expiration != expiration {
+			t.Fatalf("Error: unexpected object info %#v", objInfo)
+		}
+	}
+
+	// Headers are only sent by the copy of the client.
+	if c.uploadHeader != nil {
+		t.Fatal("Error: client should be unchanged")
+	}
+
+	objInfo, err := c.StatObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !objInfo.Expires.Equal(expires) || objInfo.Expiration != expiration {
+		t.Fatalf("Error: unexpected object info %#v", objInfo)
+	}
+}
+
+// Tests objects sent with chunked transfer encoding, without
+// Content-Length, are read to the end and their size is looked up with
+// HEAD once needed.
+func TestGetObjectChunked(t *testing.T) {
+	content := []byte("Hello chunked World")
+	var heads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", "Fri, 23 Dec 2016 00:00:00 GMT")
+		switch {
+		case r.Method == "HEAD":
+			atomic.AddInt32(&heads, 1)
+			// Size of 'unknown' cannot be looked up either.
+			if !strings.HasSuffix(r.URL.Path, "/unknown") {
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			}
+		case r.Header.Get("Range") != "":
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+		default:
+			// Flushing before writing the body sends it chunked.
+			w.(http.Flusher).Flush()
+			w.Write(content)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	// Read to the end without knowing the size.
+	object, err := c.GetObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	objInfo, err := object.Stat()
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if objInfo.Size != -1 {
+		t.Fatalf("Error: expected unknown size, got %d", objInfo.Size)
+	}
+	data, err := ioutil.ReadAll(object)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Fatalf("Error: expected %q, got %q", content, data)
+	}
+	if objInfo, err = object.Stat(); err != nil || objInfo.Size != int64(len(content)) {
+		t.Fatalf("Error: expected size %d after reading, got %d, %v", len(content), objInfo.Size, err)
+	}
+	if atomic.LoadInt32(&heads) != 0 {
+		t.Fatal("Error: reading the object should not send HEAD")
+	}
+	object.Close()
+
+	// Seeking relative to the end looks up the size.
+	object, err = c.GetObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	offset, err := object.Seek(0, 2)
+	if err != nil || offset != int64(len(content)) {
+		t.Fatalf("Error: expected offset %d after seeking to the end, got %d, %v", len(content), offset, err)
+	}
+	if offset, err = object.Seek(-7, 2); err != nil || offset != int64(len(content))-7 {
+		t.Fatalf("Error: expected offset %d after seeking before the end, got %d, %v", len(content)-7, offset, err)
+	}
+	if objInfo, err = object.Stat(); err != nil || objInfo.Size != int64(len(content)) {
+		t.Fatalf("Error: expected size %d after seeking, got %d, %v", len(content), objInfo.Size, err)
+	}
+	if atomic.LoadInt32(&heads) != 1 {
+		t.Fatalf("Error: expected 1 HEAD request, got %d", heads)
+	}
+	buf := make([]byte, 7)
+	if n, err := object.ReadAt(buf, 6); err != nil || string(buf[:n]) != "chunked" {
+		t.Fatalf("Error: unexpected ReadAt %q, %v", buf[:n], err)
+	}
+	object.Close()
+
+	// ReadAt of objects whose size is unknowable fails.
+	object, err = c.GetObject("bucket", "unknown")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer object.Close()
+	if _, err = object.ReadAt(buf, 0); err == nil {
+		t.Fatal("Error: ReadAt of an object of unknown size should fail")
+	}
+}
+
+// Tests addresses of the endpoint are looked up once and again after
+// connection failures.
+func TestDNSCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	// Every request opens a new connection.
+	server.Config.SetKeepAlivesEnabled(false)
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	_, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(net.JoinHostPort("minio.test", port), "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SetDNSCache(-1); err == nil {
+		t.Fatal("Error: negative TTL should be rejected")
+	}
+	if err = c.SetDNSCache(time.Hour); err != nil {
+		t.Fatal("Error:", err)
+	}
+	// Connect timeouts keep the cache.
+	if err = c.SetConnectTimeout(10 * time.Second); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if c.dnsCache == nil {
+		t.Fatal("Error: cache should be kept")
+	}
+	var lookups int32
+	c.dnsCache.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		atomic.AddInt32(&lookups, 1)
+		return []string{"127.0.0.1"}, nil
+	}
+	for i := 0; i < 3; i++ {
+		if err = c.BucketExists("bucket"); err != nil {
+			t.Fatal("Error:", err)
+		}
+	}
+	if n := atomic.LoadInt32(&lookups); n != 1 {
+		t.Fatalf("Error: expected 1 lookup, got %d", n)
+	}
+
+	// Failed connections look up the addresses again.
+	var dials []string
+	cache := newDNSCache(time.Hour, func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials = append(dials, addr)
+		return nil, errors.New("connection refused")
+	})
+	cache.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		return []string{"10.0.0.1", "10.0.0.2"}, nil
+	}
+	for i := 0; i < 2; i++ {
+		if _, err = cache.DialContext(context.Background(), "tcp", "minio.test:9000"); err == nil {
+			t.Fatal("Error: dial should fail")
+		}
+		if _, ok := cache.items["minio.test"]; ok {
+			t.Fatal("Error: addresses should be deleted after failed connections")
+		}
+	}
+	expected := []string{"10.0.0.1:9000", "10.0.0.2:9000", "10.0.0.1:9000", "10.0.0.2:9000"}
+	if !reflect.DeepEqual(dials, expected) {
+		t.Fatalf("Error: expected dials %v, got %v", expected, dials)
+	}
+
+	// Disabling the cache looks up every connection.
+	if err = c.SetDNSCache(0); err != nil || c.dnsCache != nil {
+		t.Fatal("Error: cache should be disabled", err)
+	}
+}
+
+// Tests moving objects removes the source only once it is copied.
+func TestMoveObject(t *testing.T) {
+	var mutex sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mutex.Unlock()
+		switch {
+		case r.Method == "HEAD":
+			w.Header().Set("Content-Length", "1024")
+			w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		case r.Method == "PUT" && r.URL.Path == "/bucket/denied":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied.</Message></Error>`)
+		case r.Method == "PUT":
+			fmt.Fprint(w, `<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`)
+		case r.Method == "DELETE" && r.URL.Path == "/bucket/locked":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied.</Message></Error>`)
+		case r.Method == "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	testCases := []struct {
+		sourceName string
+		objectName string
+		requests   []string
+		shouldPass bool
+	}{
+		{"source", "object", []string{"PUT /bucket/object", "DELETE /bucket/source"}, true},
+		// Source is kept if the copy fails.
+		{"source", "denied", []string{"PUT /bucket/denied"}, false},
+		// Moving onto itself would delete the object.
+		{"source", "source", nil, false},
+		// Sources which cannot be removed fail the move.
+		{"locked", "object", []string{"PUT /bucket/object", "DELETE /bucket/locked"}, false},
+	}
+	for i, testCase := range testCases {
+		requests = nil
+		source, err := NewSource("bucket", testCase.sourceName)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		dest, err := NewDestination("bucket", testCase.objectName, "text/plain")
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if err = dest.ReplaceMetadata(nil); err != nil {
+			t.Fatal("Error:", err)
+		}
+		err = c.MoveObject(dest, source)
+		if testCase.shouldPass != (err == nil) {
+			t.Fatalf("Test %d: unexpected error %v", i+1, err)
+		}
+		if !reflect.DeepEqual(requests, testCase.requests) {
+			t.Fatalf("Test %d: expected requests %v, got %v", i+1, testCase.requests, requests)
+		}
+	}
+}
+
+// Tests setting and getting the object lock configuration of buckets.
+func TestObjectLockConfiguration(t *testing.T) {
+	config := ObjectLockConfiguration{
+		Rule: &ObjectLockRule{
+			DefaultRetention: DefaultRetention{
+				Mode: RetentionCompliance,
+				Days: 30,
+			},
+		},
+	}
+	want := "<ObjectLockConfiguration><ObjectLockEnabled>Enabled</ObjectLockEnabled><Rule><DefaultRetention><Mode>COMPLIANCE</Mode><Days>30</Days></DefaultRetention></Rule></ObjectLockConfiguration>"
+
+	var configXML string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["object-lock"]; !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.Method {
+		case "PUT":
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil || r.Header.Get("Content-Md5") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			configXML = string(body)
+		case "GET":
+			if configXML == "" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `<Error><Code>ObjectLockConfigurationNotFoundError</Code><Message>Object Lock configuration does not exist for this bucket</Message></Error>`)
+				return
+			}
+			fmt.Fprint(w, configXML)
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	if _, err = c.GetObjectLockConfiguration("bucket"); ToErrorResponse(err).Code != "ObjectLockConfigurationNotFoundError" {
+		t.Fatalf("Error: expected ObjectLockConfigurationNotFoundError, got %v", err)
+	}
+	if err = c.SetObjectLockConfiguration("bucket", config); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if configXML != want {
+		t.Fatalf("Error: expected %s, got %s", want, configXML)
+	}
+	gotConfig, err := c.GetObjectLockConfiguration("bucket")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	config.XMLName = gotConfig.XMLName
+	config.ObjectLockEnabled = "Enabled"
+	if !reflect.DeepEqual(gotConfig, config) {
+		t.Fatalf("Error: expected %#v, got %#v", config, gotConfig)
+	}
+
+	// Invalid configurations fail before any request.
+	testCases := []ObjectLockConfiguration{
+		{ObjectLockEnabled: "Disabled"},
+		{Rule: &ObjectLockRule{DefaultRetention: DefaultRetention{Mode: "LOCKED", Days: 1}}},
+		{Rule: &ObjectLockRule{DefaultRetention: DefaultRetention{Mode: RetentionGovernance}}},
+		{Rule: &ObjectLockRule{DefaultRetention: DefaultRetention{Mode: RetentionGovernance, Days: 1, Years: 1}}},
+		{Rule: &ObjectLockRule{DefaultRetention: DefaultRetention{Mode: RetentionGovernance, Days: -1}}},
+	}
+	for i, testCase := range testCases {
+		if err = c.SetObjectLockConfiguration("bucket", testCase); ToErrorResponse(err).Code != "InvalidArgument" {
+			t.Fatalf("Test %d: Error: expected InvalidArgument, got %v", i+1, err)
+		}
+	}
+}
+
+// Tests parts of resumed and new multipart uploads are reported once
+// acknowledged.
+func TestUploaderPartComplete(t *testing.T) {
+	data := make([]byte, 2*minPartSize+10)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	partETag := func(part []byte) string {
+		return fmt.Sprintf("%x", md5.Sum(part))
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == "GET" && len(query["uploads"]) > 0:
+			fmt.Fprint(w, `<ListMultipartUploadsResult><IsTruncated>false</IsTruncated>`+
+				`<Upload><Key>object</Key><UploadId>upload-id</UploadId><Initiated>2016-01-02T15:04:05.000Z</Initiated></Upload>`+
+				`</ListMultipartUploadsResult>`)
+		case r.Method == "GET" && query.Get("uploadId") != "":
+			// First part was uploaded by a previous session.
+			fmt.Fprintf(w, `<ListPartsResult><IsTruncated>false</IsTruncated>`+
+				`<Part><PartNumber>1</PartNumber><ETag>"%s"</ETag><Size>%d</Size></Part></ListPartsResult>`,
+				partETag(data[:minPartSize]), minPartSize)
+		case r.Method == "PUT" && query.Get("uploadId") != "":
+			body, _ := ioutil.ReadAll(r.Body)
+			w.Header().Set("ETag", `"`+partETag(body)+`"`)
+		case r.Method == "POST" && query.Get("uploadId") != "":
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><ETag>"etag-3"</ETag></CompleteMultipartUploadResult>`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	type completedPart struct {
+		partNumber int
+		etag       string
+		size       int64
+	}
+	expected := []completedPart{
+		{1, partETag(data[:minPartSize]), minPartSize},
+		{2, partETag(data[minPartSize : 2*minPartSize]), minPartSize},
+		{3, partETag(data[2*minPartSize:]), 10},
+	}
+	for _, concurrency := range []int{1, 2} {
+		var mutex sync.Mutex
+		var completed []completedPart
+		uploader := NewUploader(*c)
+		uploader.PartSize = minPartSize
+		uploader.Concurrency = concurrency
+		uploader.Resumable = true
+		uploader.OnPartComplete = func(partNumber int, etag string, size int64) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			completed = append(completed, completedPart{partNumber, etag, size})
+		}
+		if _, err = uploader.Upload("bucket", "object", bytes.NewReader(data)); err != nil {
+			t.Fatal("Error:", err)
+		}
+		sort.Slice(completed, func(i, j int) bool {
+			return completed[i].partNumber < completed[j].partNumber
+		})
+		if !reflect.DeepEqual(completed, expected) {
+			t.Fatalf("Concurrency %d: Error: expected completed parts %v, got %v", concurrency, expected, completed)
+		}
+	}
+}
+
+// Tests concurrent requests are limited once the server throttles them
+// and the limit is lifted again after successful requests.
+func TestAdaptiveThrottle(t *testing.T) {
+	var limits []int
+	throttle := newAdaptiveThrottle(func(limit int) {
+		limits = append(limits, limit)
+	})
+
+	// Requests sent together are throttled together, the limit is
+	// halved only once.
+	var generations []int
+	for i := 0; i < 8; i++ {
+		generations = append(generations, throttle.Acquire())
+	}
+	throttle.Release(generations[0], http.StatusServiceUnavailable)
+	throttle.Release(generations[1], http.StatusServiceUnavailable)
+	for _, generation := range generations[2:] {
+		throttle.Release(generation, http.StatusOK)
+	}
+	if !reflect.DeepEqual(limits, []int{4}) {
+		t.Fatalf("Error: expected limit 4, got %v", limits)
+	}
+
+	// Requests over the limit wait.
+	for i := 0; i < 4; i++ {
+		generations[i] = throttle.Acquire()
+	}
+	acquiredCh := make(chan int)
+	go func() {
+		acquiredCh <- throttle.Acquire()
+	}()
+	select {
+	case <-acquiredCh:
+		t.Fatal("Error: request over the limit should wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+	throttle.Release(generations[0], http.StatusOK)
+	generations[0] = <-acquiredCh
+	for _, generation := range generations[:4] {
+		throttle.Release(generation, http.StatusOK)
+	}
+
+	// Failed requests do not raise the limit.
+	for i := 0; i < throttleRampUpRequests; i++ {
+		throttle.Release(throttle.Acquire(), 0)
+	}
+	// Successful requests raise the limit back to the concurrency
+	// throttling engaged at, then lift it.
+	for i := 0; i < 5*throttleRampUpRequests; i++ {
+		throttle.Release(throttle.Acquire(), http.StatusOK)
+	}
+	if !reflect.DeepEqual(limits, []int{4, 5, 6, 7, 8, 0}) {
+		t.Fatalf("Error: expected limits raised back to 8 and lifted, got %v", limits)
+	}
+
+	// Throttled requests engage the throttle of the client.
+	var throttled int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&throttled, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `<Error><Code>SlowDown</Code><Message>Please reduce your request rate.</Message></Error>`)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	var mutex sync.Mutex
+	limits = nil
+	c.SetAdaptiveThrottling(true, func(limit int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		limits = append(limits, limit)
+	})
+	for i := 0; i <= throttleRampUpRequests; i++ {
+		if err = c.BucketExists("bucket"); err != nil {
+			t.Fatal("Error:", err)
+		}
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if !reflect.DeepEqual(limits, []int{1, 0}) {
+		t.Fatalf("Error: expected throttling engaged and lifted, got %v", limits)
+	}
+}
+
+// metricsRecorder - records the metrics of all the requests.
+type metricsRecorder struct {
+	sync.Mutex
+	requests []RequestMetrics
+}
+
+func (r *metricsRecorder) ObserveRequest(metrics RequestMetrics) {
+	r.Lock()
+	defer r.Unlock()
+	r.requests = append(r.requests, metrics)
+}
+
+// Tests the metrics of requests are reported with their operation.
+func TestMetricsCollector(t *testing.T) {
+	copyHeader := make(http.Header)
+	copyHeader.Set("x-amz-copy-source", "/bucket/source")
+	testCases := []struct {
+		method     string
+		bucketName string
+		objectName string
+		query      string
+		header     http.Header
+		operation  string
+	}{
+		{"GET", "", "", "", nil, "ListBuckets"},
+		{"PUT", "bucket", "", "", nil, "MakeBucket"},
+		{"GET", "bucket", "", "prefix=a", nil, "ListObjects"},
+		{"GET", "bucket", "", "versions", nil, "ListObjectVersions"},
+		{"POST", "bucket", "", "delete", nil, "RemoveObjects"},
+		{"PUT", "bucket", "", "cors", nil, "SetBucketCORS"},
+		{"DELETE", "bucket", "", "lifecycle", nil, "RemoveBucketLifecycle"},
+		{"GET", "bucket", "object", "tagging", nil, "GetObjectTagging"},
+		{"GET", "bucket", "object", "attributes", nil, "GetObjectAttributes"},
+		{"GET", "bucket", "object", "", nil, "GetObject"},
+		{"HEAD", "bucket", "object", "", nil, "StatObject"},
+		{"PUT", "bucket", "object", "", nil, "PutObject"},
+		{"PUT", "bucket", "object", "", copyHeader, "CopyObject"},
+		{"POST", "bucket", "object", "uploads", nil, "NewMultipartUpload"},
+		{"PUT", "bucket", "object", "partNumber=1&uploadId=id", nil, "PutObjectPart"},
+		{"POST", "bucket", "object", "uploadId=id", nil, "CompleteMultipartUpload"},
+		{"DELETE", "bucket", "object", "uploadId=id", nil, "AbortMultipartUpload"},
+		{"DELETE", "bucket", "object", "", nil, "RemoveObject"},
+	}
+	for i, testCase := range testCases {
+		query, err := url.ParseQuery(testCase.query)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		operation := requestOperation(testCase.method, requestMetadata{
+			bucketName:   testCase.bucketName,
+			objectName:   testCase.objectName,
+			queryValues:  query,
+			customHeader: testCase.header,
+		})
+		if operation != testCase.operation {
+			t.Fatalf("Test %d: Error: expected operation %s, got %s", i+1, testCase.operation, operation)
+		}
+	}
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// First attempt fails, it is retried.
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		ioutil.ReadAll(r.Body)
+		w.Header().Set("ETag", `"etag"`)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	recorder := &metricsRecorder{}
+	c.SetMetricsCollector(recorder)
+	if _, err = c.PutObject("bucket", "object", bytes.NewReader(make([]byte, 11)), ""); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(recorder.requests) != 1 {
+		t.Fatalf("Error: expected 1 request, got %d", len(recorder.requests))
+	}
+	metrics := recorder.requests[0]
+	if metrics.Operation != "PutObject" || metrics.BucketName != "bucket" || metrics.ObjectName != "object" ||
+		metrics.BytesSent != 11 || metrics.Retries != 1 || metrics.StatusCode != http.StatusOK ||
+		metrics.Err != nil || metrics.Duration <= 0 {
+		t.Fatalf("Error: unexpected metrics %#v", metrics)
+	}
+
+	// Unset collector stops reporting.
+	c.SetMetricsCollector(nil)
+	if _, err = c.PutObject("bucket", "object", bytes.NewReader(make([]byte, 11)), ""); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(recorder.requests) != 1 {
+		t.Fatalf("Error: expected no more requests, got %d", len(recorder.requests))
+	}
+}
+
+// Tests objects are read ahead in ranges fetched in the background.
+func TestReadAhead(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	var mutex sync.Mutex
+	var ranges []string
+	cancelledCh := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mutex.Unlock()
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", "Fri, 23 Dec 2016 00:00:00 GMT")
+		// Ranges of 'slow' after the first one never finish.
+		if strings.HasSuffix(r.URL.Path, "/slow") && r.Header.Get("Range") != "bytes=0-99" {
+			w.Header().Set("Content-Range", "bytes 100-199/1000")
+			w.Header().Set("Content-Length", "100")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(data[100:110])
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			close(cancelledCh)
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SetReadAhead(-1); err == nil {
+		t.Fatal("Error: negative read-ahead should be rejected")
+	}
+	if err = c.SetReadAhead(100); err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	object, err := c.GetObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer object.Close()
+	objInfo, err := object.Stat()
+	if err != nil || objInfo.Size != int64(len(data)) {
+		t.Fatalf("Error: expected size %d, got %d, %v", len(data), objInfo.Size, err)
+	}
+
+	// Next range is fetched before it is read.
+	for i := 0; ; i++ {
+		mutex.Lock()
+		n := len(ranges)
+		mutex.Unlock()
+		if n == 2 {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("Error: expected the next range fetched ahead, got %d requests", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	readData := make([]byte, len(data))
+	if _, err = io.ReadFull(object, readData); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !bytes.Equal(readData, data) {
+		t.Fatal("Error: read data does not match the object")
+	}
+	mutex.Lock()
+	if len(ranges) != 10 || ranges[0] != "bytes=0-99" || ranges[9] != "bytes=900-999" {
+		t.Fatalf("Error: unexpected ranges %v", ranges)
+	}
+	mutex.Unlock()
+
+	// Seeking reads ahead from the new offset.
+	if _, err = object.Seek(550, 0); err != nil {
+		t.Fatal("Error:", err)
+	}
+	buf := make([]byte, 100)
+	if _, err = io.ReadFull(object, buf); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !bytes.Equal(buf, data[550:650]) {
+		t.Fatal("Error: read data after seek does not match the object")
+	}
+
+	// Close stops fetching ranges.
+	slow, err := c.GetObject("bucket", "slow")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if _, err = io.ReadFull(slow, buf[:10]); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = slow.Close(); err != nil {
+		t.Fatal("Error:", err)
+	}
+	select {
+	case <-cancelledCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Error: range fetched ahead should be cancelled by Close")
+	}
+}
+
+// Tests detecting the type of server from the Server header.
+func TestServerType(t *testing.T) {
+	testCases := []struct {
+		server   string
+		expected string
+	}{
+		{"MinIO/RELEASE.2018-06-29T02-11-29Z", ServerTypeMinio},
+		{"Minio/DEVELOPMENT.GOGET", ServerTypeMinio},
+		{"AmazonS3", ServerTypeAmazon},
+		{"Ceph Object Gateway (nautilus)", ServerTypeCeph},
+		{"nginx/1.14.0", ServerTypeUnknown},
+		{"", ServerTypeUnknown},
+	}
+	for i, testCase := range testCases {
+		if serverType := parseServerType(testCase.server); serverType != testCase.expected {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.expected, serverType)
+		}
+	}
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// Only the second response tells the server type.
+		if requests == 2 {
+			w.Header().Set("Server", "MinIO/RELEASE.2018-06-29T02-11-29Z")
+		}
+		if requests == 3 {
+			w.Header().Set("Server", "AmazonS3")
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if serverType := c.ServerType(); serverType != ServerTypeUnknown {
+		t.Fatalf("Expected %q before the first request, got %q", ServerTypeUnknown, serverType)
+	}
+	for i, expected := range []string{ServerTypeUnknown, ServerTypeMinio, ServerTypeMinio} {
+		if err = c.BucketExists("bucket"); err != nil {
+			t.Fatal(err)
+		}
+		if serverType := c.ServerType(); serverType != expected {
+			t.Fatalf("Request %d: expected %q, got %q", i+1, expected, serverType)
+		}
+	}
+}
+
+// Tests uploads signed with signature version '2' and anonymous
+// uploads do not compute the sha256 sum of their payload.
+func TestPayloadSHA256(t *testing.T) {
+	var mutex sync.Mutex
+	var sha256Headers, md5Headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+		mutex.Lock()
+		sha256Headers = append(sha256Headers, r.Header.Get("X-Amz-Content-Sha256"))
+		md5Headers = append(md5Headers, r.Header.Get("Content-Md5"))
+		mutex.Unlock()
+		w.Header().Set("ETag", `"etag"`)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("a"), 1024)
+	md5Sum := base64.StdEncoding.EncodeToString(sumMD5(data))
+	sha256Sum := hex.EncodeToString(sum256(data))
+
+	testCases := []struct {
+		signature SignatureType
+		accessKey string
+		expected  string
+	}{
+		{SignatureV2, "access", ""},
+		{SignatureV4, "", ""},
+		{SignatureV4, "access", sha256Sum},
+	}
+	for i, testCase := range testCases {
+		c, err := New(u.Host, testCase.accessKey, "secret", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = c.SetSignatureType(testCase.signature); err != nil {
+			t.Fatal(err)
+		}
+		c.bucketLocCache.Set("bucket", "us-east-1")
+
+		_, sha256Bytes, _, err := c.hashCopyN(ioutil.Discard, bytes.NewReader(data), int64(len(data)))
+		if err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(sha256Bytes) != testCase.expected {
+			t.Errorf("Test %d: expected sha256 sum %q, got %x", i+1, testCase.expected, sha256Bytes)
+		}
+		if hex.EncodeToString(c.payloadSHA256(data)) != testCase.expected {
+			t.Errorf("Test %d: expected payload sha256 sum %q", i+1, testCase.expected)
+		}
+
+		mutex.Lock()
+		sha256Headers, md5Headers = nil, nil
+		mutex.Unlock()
+		if _, err = c.PutObject("bucket", "object", bytes.NewReader(data), ""); err != nil {
+			t.Fatal(err)
+		}
+		mutex.Lock()
+		if len(sha256Headers) != 1 || sha256Headers[0] != testCase.expected {
+			t.Errorf("Test %d: expected X-Amz-Content-Sha256 %q, got %v", i+1, testCase.expected, sha256Headers)
+		}
+		if len(md5Headers) != 1 || md5Headers[0] != md5Sum {
+			t.Errorf("Test %d: expected Content-Md5 %q, got %v", i+1, md5Sum, md5Headers)
+		}
+		mutex.Unlock()
+	}
+}
+
+// Benchmarks the CPU used by uploads signed with signature version '2'
+// and '4', only the latter computes the sha256 sum of payloads.
+func BenchmarkPutObjectSignature(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+		w.Header().Set("ETag", `"etag"`)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		b.Fatal("Error:", err)
+	}
+	data := bytes.Repeat([]byte("a"), 1024*1024)
+
+	for _, signature := range []SignatureType{SignatureV2, SignatureV4} {
+		b.Run(signature.String(), func(b *testing.B) {
+			c, err := New(u.Host, "access", "secret", true)
+			if err != nil {
+				b.Fatal("Error:", err)
+			}
+			if err = c.SetSignatureType(signature); err != nil {
+				b.Fatal("Error:", err)
+			}
+			c.bucketLocCache.Set("bucket", "us-east-1")
+
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err = c.PutObject("bucket", "object", bytes.NewReader(data), ""); err != nil {
+					b.Fatal("Error:", err)
+				}
+			}
+		})
+	}
+}
+
+// Tests file uploads are cancelled by their context mid-transfer and
+// the multipart upload is aborted.
+func TestFPutObjectWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mutex sync.Mutex
+	var parts []string
+	var aborts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == "GET" && len(query["uploads"]) > 0:
+			fmt.Fprint(w, `<ListMultipartUploadsResult><IsTruncated>false</IsTruncated></ListMultipartUploadsResult>`)
+		case r.Method == "POST" && len(query["uploads"]) > 0:
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><UploadId>upload-id</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == "PUT" && query.Get("uploadId") != "":
+			ioutil.ReadAll(r.Body)
+			mutex.Lock()
+			parts = append(parts, query.Get("partNumber"))
+			mutex.Unlock()
+			// Second part is cancelled while waiting for the response.
+			if query.Get("partNumber") == "2" {
+				cancel()
+				select {
+				case <-r.Context().Done():
+				case <-time.After(10 * time.Second):
+				}
+				return
+			}
+			w.Header().Set("ETag", `"part-etag"`)
+		case r.Method == "DELETE" && query.Get("uploadId") != "":
+			mutex.Lock()
+			aborts++
+			mutex.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	file, err := ioutil.TempFile("", "minio-go-ctx")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err = file.Write(make([]byte, minPartSize+1)); err != nil {
+		t.Fatal("Error:", err)
+	}
+	file.Close()
+
+	if _, err = c.FPutObjectWithContext(nil, "bucket", "object", file.Name(), ""); err == nil {
+		t.Fatal("Error: expected nil context to fail")
+	}
+	if _, err = c.FPutObjectWithContext(ctx, "bucket", "object", file.Name(), ""); err != context.Canceled {
+		t.Fatal("Error: expected context.Canceled, got", err)
+	}
+	mutex.Lock()
+	if strings.Join(parts, ",") != "1,2" || aborts != 1 {
+		t.Fatalf("Error: expected parts 1,2 and 1 abort, got parts %v and %d aborts", parts, aborts)
+	}
+	parts = nil
+	mutex.Unlock()
+
+	// Cancelled contexts send no requests.
+	if _, err = c.FPutObjectWithContext(ctx, "bucket", "object", file.Name(), ""); err != context.Canceled {
+		t.Fatal("Error: expected context.Canceled, got", err)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(parts) != 0 || aborts != 1 {
+		t.Fatalf("Error: unexpected parts %v and %d aborts", parts, aborts)
+	}
+}
+
+// Tests file downloads are cancelled by their context mid-transfer and
+// the part file is removed.
+func TestFGetObjectWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", "Fri, 23 Dec 2016 00:00:00 GMT")
+		w.Header().Set("Content-Length", "1000")
+		if r.Method == "HEAD" {
+			return
+		}
+		w.Write(make([]byte, 100))
+		w.(http.Flusher).Flush()
+		cancel()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	dir, err := ioutil.TempDir("", "minio-go-ctx")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "object")
+
+	if err = c.FGetObjectWithContext(nil, "bucket", "object", filePath); err == nil {
+		t.Fatal("Error: expected nil context to fail")
+	}
+	if err = c.FGetObjectWithContext(ctx, "bucket", "object", filePath); err != context.Canceled {
+		t.Fatal("Error: expected context.Canceled, got", err)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("Error: expected no files left, got %s", files[0].Name())
+	}
+}
+
+// Tests listing directories returns only the common prefixes of all
+// the pages.
+func TestListDirectories(t *testing.T) {
+	var markers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("prefix") != "photos/" || query.Get("delimiter") != "/" || query.Get("max-keys") != "1000" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		markers = append(markers, query.Get("marker"))
+		switch query.Get("marker") {
+		case "":
+			fmt.Fprint(w, `<ListBucketResult><IsTruncated>true</IsTruncated><NextMarker>photos/2016/</NextMarker>`+
+				`<Contents><Key>photos/cover.jpg</Key></Contents>`+
+				`<CommonPrefixes><Prefix>photos/2015/</Prefix></CommonPrefixes>`+
+				`<CommonPrefixes><Prefix>photos/2016/</Prefix></CommonPrefixes></ListBucketResult>`)
+		case "photos/2016/":
+			// Next marker is missing, the last prefix is the marker.
+			fmt.Fprint(w, `<ListBucketResult><IsTruncated>true</IsTruncated>`+
+				`<Contents><Key>photos/2017.jpg</Key></Contents>`+
+				`<CommonPrefixes><Prefix>photos/2017/</Prefix></CommonPrefixes></ListBucketResult>`)
+		case "photos/2017/":
+			fmt.Fprint(w, `<ListBucketResult><IsTruncated>false</IsTruncated>`+
+				`<Contents><Key>photos/index.html</Key></Contents></ListBucketResult>`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	dirs, err := c.ListDirectories("bucket", "photos/")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	expected := []string{"photos/2015/", "photos/2016/", "photos/2017/"}
+	if !reflect.DeepEqual(dirs, expected) {
+		t.Fatalf("Error: expected directories %v, got %v", expected, dirs)
+	}
+	if strings.Join(markers, ",") != ",photos/2016/,photos/2017/" {
+		t.Fatalf("Error: unexpected markers %v", markers)
+	}
+}
+
+// Tests restoring archived objects and parsing their restore status.
+func TestRestoreObject(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && len(r.URL.Query()["restore"]) > 0:
+			body, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			switch r.URL.Path {
+			case "/bucket/archived":
+				w.WriteHeader(http.StatusAccepted)
+			case "/bucket/restored":
+				w.WriteHeader(http.StatusOK)
+			default:
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprint(w, `<Error><Code>RestoreAlreadyInProgress</Code><Message>Object restore is already in progress</Message></Error>`)
+			}
+		case r.Method == "HEAD":
+			w.Header().Set("ETag", `"etag"`)
+			w.Header().Set("Last-Modified", "Fri, 23 Dec 2016 00:00:00 GMT")
+			w.Header().Set("Content-Length", "100")
+			w.Header().Set("x-amz-storage-class", "GLACIER")
+			if r.URL.Path == "/bucket/restored" {
+				w.Header().Set("x-amz-restore", `ongoing-request="false", expiry-date="Fri, 30 Dec 2016 00:00:00 GMT"`)
+			} else if r.URL.Path == "/bucket/restoring" {
+				w.Header().Set("x-amz-restore", `ongoing-request="true"`)
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	if err = c.RestoreObject("bucket", "archived", 0, RestoreTierStandard); err == nil {
+		t.Fatal("Error: expected invalid days to fail")
+	}
+	if err = c.RestoreObject("bucket", "archived", 1, "Fast"); err == nil {
+		t.Fatal("Error: expected invalid tier to fail")
+	}
+	if len(bodies) != 0 {
+		t.Fatal("Error: invalid restores were sent")
+	}
+	if err = c.RestoreObject("bucket", "archived", 7, RestoreTierBulk); err != nil {
+		t.Fatal("Error:", err)
+	}
+	expected := `<RestoreRequest><Days>7</Days><GlacierJobParameters><Tier>Bulk</Tier></GlacierJobParameters></RestoreRequest>`
+	if bodies[0] != expected {
+		t.Fatalf("Error: expected body %s, got %s", expected, bodies[0])
+	}
+	if err = c.RestoreObject("bucket", "restored", 7, RestoreTierExpedited); err != nil {
+		t.Fatal("Error:", err)
+	}
+	err = c.RestoreObject("bucket", "restoring", 7, RestoreTierStandard)
+	if ToErrorResponse(err).Code != "RestoreAlreadyInProgress" {
+		t.Fatal("Error: expected RestoreAlreadyInProgress, got", err)
+	}
+
+	testCases := []struct {
+		objectName string
+		restore    *RestoreInfo
+	}{
+		{"archived", nil},
+		{"restoring", &RestoreInfo{OngoingRequest: true}},
+		{"restored", &RestoreInfo{ExpiryDate: time.Date(2016, 12, 30, 0, 0, 0, 0, time.UTC)}},
+	}
+	for _, testCase := range testCases {
+		objInfo, err := c.StatObject("bucket", testCase.objectName)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if objInfo.StorageClass != "GLACIER" {
+			t.Fatalf("Error: unexpected storage class %q", objInfo.StorageClass)
+		}
+		if !reflect.DeepEqual(objInfo.Restore, testCase.restore) {
+			t.Fatalf("Error: %s: expected restore %+v, got %+v", testCase.objectName, testCase.restore, objInfo.Restore)
+		}
+	}
+}
+
+// Tests requests fail once they exceed the request deadline, zero
+// removes the deadline.
+func TestSetRequestDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SetRequestDeadline(-time.Second); err == nil {
+		t.Fatal("Error: expected negative deadline to fail")
+	}
+
+	if err = c.SetRequestDeadline(50 * time.Millisecond); err != nil {
+		t.Fatal("Error:", err)
+	}
+	start := time.Now()
+	if err = c.BucketExists("bucket"); err == nil {
+		t.Fatal("Error: expected request to exceed the deadline")
+	}
+	if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+		t.Fatalf("Error: request took %s, longer than its deadline", elapsed)
+	}
+
+	if err = c.SetRequestDeadline(0); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.BucketExists("bucket"); err != nil {
+		t.Fatal("Error:", err)
+	}
+}
+
+// Tests the tags of copied objects are copied or replaced, also by
+// multipart copies.
+func TestCopyObjectTagging(t *testing.T) {
+	var header, initHeader http.Header
+	var taggingRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case r.Method == "HEAD":
+			size := int64(1024)
+			if r.URL.Path == "/bucket/large" {
+				size = maxSinglePutObjectSize + 1024
+			}
+			w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+			w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+			w.Header().Set("x-amz-tagging-count", "2")
+		case r.Method == "GET" && len(query["tagging"]) > 0:
+			taggingRequests++
+			fmt.Fprint(w, `<Tagging><TagSet><Tag><Key>project</Key><Value>a b</Value></Tag><Tag><Key>team</Key><Value>x&amp;y</Value></Tag></TagSet></Tagging>`)
+		case r.Method == "POST" && len(query["uploads"]) > 0:
+			initHeader = r.Header
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><UploadId>upload-id</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == "PUT" && query.Get("uploadId") != "":
+			fmt.Fprintf(w, `<CopyPartResult><ETag>"etag-%s"</ETag></CopyPartResult>`, query.Get("partNumber"))
+		case r.Method == "POST" && query.Get("uploadId") != "":
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><ETag>"etag-2"</ETag></CompleteMultipartUploadResult>`)
+		case r.Method == "PUT" && strings.HasSuffix(r.Header.Get("x-amz-copy-source"), "/large"):
+			// Single copies of more than 5GiB are refused.
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `<Error><Code>InvalidRequest</Code><Message>The specified copy source is larger than the maximum allowable size for a copy source: 5368709120</Message></Error>`)
+		case r.Method == "PUT":
+			header = r.Header
+			fmt.Fprint(w, `<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	source, err := NewSource("bucket", "source")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	large, err := NewSource("bucket", "large")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	// Tags are copied by default.
+	dest, err := NewDestination("bucket", "object", "")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.CopyObject(dest, source); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if header.Get("x-amz-tagging-directive") != "COPY" || header.Get("x-amz-tagging") != "" {
+		t.Fatalf("Error: unexpected copy headers %v", header)
+	}
+	if err = c.CopyObject(dest, large); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if taggingRequests != 1 || initHeader.Get("x-amz-tagging") != "project=a%20b&team=x%26y" {
+		t.Fatalf("Error: source tags not carried over, got %v", initHeader)
+	}
+
+	// Replacing tags requires valid tags.
+	if err = dest.ReplaceTags(nil); err == nil {
+		t.Fatal("Error: replacing tags without tags should fail")
+	}
+	if err = dest.ReplaceTags(map[string]string{"": "empty"}); err == nil {
+		t.Fatal("Error: replacing tags with an empty key should fail")
+	}
+	if err = dest.ReplaceTags(map[string]string{"team": "storage", "env": "prod"}); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.CopyObject(dest, source); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if header.Get("x-amz-tagging-directive") != "REPLACE" || header.Get("x-amz-tagging") != "env=prod&team=storage" {
+		t.Fatalf("Error: unexpected replace headers %v", header)
+	}
+	if err = c.CopyObject(dest, large); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if taggingRequests != 1 || initHeader.Get("x-amz-tagging") != "env=prod&team=storage" {
+		t.Fatalf("Error: unexpected multipart tags %v", initHeader)
+	}
+}
+
+// Tests objects cut short by the server fail with UnexpectedEOF instead
+// of ending early.
+func TestGetObjectTruncated(t *testing.T) {
+	data := make([]byte, 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", "Fri, 23 Dec 2016 00:00:00 GMT")
+		if r.Header.Get("Range") != "" {
+			w.Header().Set("Content-Range", "bytes 100-199/1000")
+			w.Header().Set("Content-Length", "100")
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", "1000")
+		}
+		// Close the connection after half the body.
+		w.Write(data[:50])
+		w.(http.Flusher).Flush()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	object, err := c.GetObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer object.Close()
+
+	buf, err := ioutil.ReadAll(object)
+	if ToErrorResponse(err).Code != "UnexpectedEOF" {
+		t.Fatal("Error: expected UnexpectedEOF, got", err)
+	}
+	if len(buf) != 50 {
+		t.Fatalf("Error: expected 50 bytes before the error, got %d", len(buf))
+	}
+	if _, err = object.Read(buf); ToErrorResponse(err).Code != "UnexpectedEOF" {
+		t.Fatal("Error: expected the error to be kept, got", err)
+	}
+
+	object, err = c.GetObject("bucket", "object")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	defer object.Close()
+	if _, err = object.ReadAt(make([]byte, 100), 100); ToErrorResponse(err).Code != "UnexpectedEOF" {
+		t.Fatal("Error: expected UnexpectedEOF, got", err)
+	}
+}
+
+// Tests presigned URLs keep non-default ports of the endpoint and are
+// signed for the host clients send.
+func TestPresignedURLPort(t *testing.T) {
+	testCases := []struct {
+		endpoint string
+		insecure bool
+		host     string
+	}{
+		{"play.minio.io:9002", false, "play.minio.io:9002"},
+		{"play.minio.io:443", false, "play.minio.io"},
+		{"play.minio.io:80", false, "play.minio.io:80"},
+		{"localhost:80", true, "localhost"},
+		{"[::1]:443", false, "[::1]"},
+	}
+	for i, testCase := range testCases {
+		c, err := New(testCase.endpoint, "ACCESS-KEY", "SECRET-KEY", testCase.insecure)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		presignedURL, err := c.PresignedGetObjectWithRegion("bucket", "object", time.Hour, nil, "us-east-1")
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		u, err := url.Parse(presignedURL)
+		if err != nil {
+			t.Fatal("Error:", err)
+		}
+		if u.Host != testCase.host {
+			t.Errorf("Test %d: expected host %s, got %s", i+1, testCase.host, u.Host)
+		}
+	}
+
+	// Verify the signature for the host of the received request.
+	var verified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		signature := query.Get("X-Amz-Signature")
+		signingTime, err := time.Parse(iso8601DateFormat, query.Get("X-Amz-Date"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		query.Del("X-Amz-Signature")
+		req, err := http.NewRequest(r.Method, "http://"+r.Host+r.URL.Path+"?"+query.Encode(), nil)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		canonicalRequest := getCanonicalRequest(*req, presignIgnoredHeaders)
+		stringToSign := getStringToSignV4(signingTime, "us-east-1", canonicalRequest, serviceTypeS3)
+		signingKey := getSigningKey("SECRET-KEY", "us-east-1", signingTime, serviceTypeS3)
+		if getSignature(signingKey, stringToSign) != signature {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		verified++
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	getURL, err := c.PresignedGetObjectWithRegion("bucket", "object", time.Hour, nil, "us-east-1")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	putURL, err := c.PresignedPutObjectWithRegion("bucket", "object", time.Hour, "us-east-1")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !strings.Contains(getURL, u.Host) {
+		t.Fatalf("Error: port of %s missing in %s", u.Host, getURL)
+	}
+	resp, err := http.Get(getURL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Error: presigned GET failed with %s", resp.Status)
+	}
+	req, err := http.NewRequest("PUT", putURL, strings.NewReader("data"))
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || verified != 2 {
+		t.Fatalf("Error: presigned PUT failed with %s", resp.Status)
+	}
+}
+
+// Tests unsigned object URLs follow the addressing style of the client
+// and escape object names.
+func TestPublicURL(t *testing.T) {
+	minio, err := New("play.minio.io:9000", "ACCESS-KEY", "SECRET-KEY", false)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	s3, err := New("s3.amazonaws.com", "ACCESS-KEY", "SECRET-KEY", false)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	s3.bucketLocCache.Set("bucket", "eu-west-1")
+	s3.bucketLocCache.Set("my.bucket", "us-east-1")
+
+	testCases := []struct {
+		c          *Client
+		bucketName string
+		objectName string
+		expected   string
+	}{
+		{minio, "bucket", "object", "https://play.minio.io:9000/bucket/object"},
+		{minio, "bucket", "photos/a b?#.jpg", "https://play.minio.io:9000/bucket/photos/a%20b%3F%23.jpg"},
+		{s3, "bucket", "photos/1.jpg", "https://bucket." + getS3Endpoint("eu-west-1") + "/photos/1.jpg"},
+		{s3, "my.bucket", "object", "https://s3.amazonaws.com/my.bucket/object"},
+	}
+	for i, testCase := range testCases {
+		u, err := testCase.c.PublicURL(testCase.bucketName, testCase.objectName)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if u.String() != testCase.expected {
+			t.Errorf("Test %d: expected %s, got %s", i+1, testCase.expected, u)
+		}
+		if u.RawQuery != "" {
+			t.Errorf("Test %d: unexpected query %s", i+1, u.RawQuery)
+		}
+	}
+	if _, err = minio.PublicURL("bucket", ""); err == nil {
+		t.Fatal("Error: expected empty object name to fail")
+	}
+}
+
+// Tests CopyObject copying the source ACL only if preserved.
+func TestCopyObjectPreserveACL(t *testing.T) {
+	var requests []string
+	var aclBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		_, isACL := query["acl"]
+		switch {
+		case r.Method == "HEAD":
+			w.Header().Set("Content-Length", "1024")
+			w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		case r.Method == "GET" && isACL:
+			requests = append(requests, "GET "+r.URL.Path+"?acl")
+			fmt.Fprint(w, `<AccessControlPolicy><Owner><ID>owner-id</ID></Owner><AccessControlList><Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser"><ID>owner-id</ID></Grantee><Permission>FULL_CONTROL</Permission></Grant><Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="Group"><URI>http://acs.amazonaws.com/groups/global/AllUsers</URI></Grantee><Permission>READ</Permission></Grant></AccessControlList></AccessControlPolicy>`)
+		case r.Method == "PUT" && isACL:
+			requests = append(requests, "PUT "+r.URL.Path+"?acl")
+			aclBody, _ = ioutil.ReadAll(r.Body)
+		case r.Method == "PUT":
+			requests = append(requests, "PUT "+r.URL.Path)
+			fmt.Fprint(w, `<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`)
+		case r.Method == "DELETE":
+			requests = append(requests, "DELETE "+r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	source, err := NewSource("bucket", "source")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	dest, err := NewDestination("bucket", "object", "")
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	// ACLs are not copied by default.
+	if err = c.CopyObject(dest, source); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if !reflect.DeepEqual(requests, []string{"PUT /bucket/object"}) {
+		t.Fatalf("Error: unexpected requests %v", requests)
+	}
+
+	// Preserved ACLs are read before and set after copying, before
+	// moves remove the source.
+	requests = nil
+	dest.PreserveACL()
+	if err = c.MoveObject(dest, source); err != nil {
+		t.Fatal("Error:", err)
+	}
+	expected := []string{"GET /bucket/source?acl", "PUT /bucket/object", "PUT /bucket/object?acl", "DELETE /bucket/source"}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Fatalf("Error: expected requests %v, got %v", expected, requests)
+	}
+	if !bytes.Contains(aclBody, []byte("http://acs.amazonaws.com/groups/global/AllUsers")) || !bytes.Contains(aclBody, []byte("owner-id")) {
+		t.Fatalf("Error: source ACL not set on the destination, got %s", aclBody)
+	}
+}
+
+// Tests ListObjects requesting pages of the configured size.
+func TestSetListMaxKeys(t *testing.T) {
+	keys := []string{"a", "b", "c", "d", "e"}
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		requested = append(requested, query.Get("max-keys"))
+		maxKeys, err := strconv.Atoi(query.Get("max-keys"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var contents string
+		var count int
+		truncated := false
+		for _, key := range keys {
+			if key <= query.Get("marker") {
+				continue
+			}
+			if count == maxKeys {
+				truncated = true
+				break
+			}
+			contents += "<Contents><Key>" + key + "</Key><Size>1</Size></Contents>"
+			count++
+		}
+		fmt.Fprintf(w, "<ListBucketResult><IsTruncated>%t</IsTruncated>%s</ListBucketResult>", truncated, contents)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	if err = c.SetListMaxKeys(0); err == nil {
+		t.Fatal("Error: zero max keys should fail")
+	}
+	if err = c.SetListMaxKeys(1001); err == nil {
+		t.Fatal("Error: more than 1000 max keys should fail")
+	}
+
+	list := func() []string {
+		doneCh := make(chan struct{})
+		defer close(doneCh)
+		var listed []string
+		for object := range c.ListObjects("bucket", "", true, doneCh) {
+			if object.Err != nil {
+				t.Fatal("Error:", object.Err)
+			}
+			listed = append(listed, object.Key)
+		}
+		return listed
+	}
+
+	// Defaults to pages of 1000 objects.
+	if listed := list(); !reflect.DeepEqual(listed, keys) || !reflect.DeepEqual(requested, []string{"1000"}) {
+		t.Fatalf("Error: listed %v with max keys %v", listed, requested)
+	}
+
+	// Smaller pages list the same objects with more requests.
+	requested = nil
+	if err = c.SetListMaxKeys(2); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if listed := list(); !reflect.DeepEqual(listed, keys) || !reflect.DeepEqual(requested, []string{"2", "2", "2"}) {
+		t.Fatalf("Error: listed %v with max keys %v", listed, requested)
+	}
+}
+
+// Tests ListObjects sending failures after the first page as the last
+// value.
+func TestListObjectsError(t *testing.T) {
+	var truncatedWithoutMarker bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case truncatedWithoutMarker:
+			fmt.Fprint(w, "<ListBucketResult><IsTruncated>true</IsTruncated></ListBucketResult>")
+		case r.URL.Query().Get("marker") == "":
+			fmt.Fprint(w, "<ListBucketResult><IsTruncated>true</IsTruncated><Contents><Key>a</Key><Size>1</Size></Contents></ListBucketResult>")
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, "<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>")
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := New(u.Host, "", "", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+
+	list := func() []ObjectInfo {
+		doneCh := make(chan struct{})
+		defer close(doneCh)
+		var listed []ObjectInfo
+		for object := range c.ListObjects("bucket", "", true, doneCh) {
+			listed = append(listed, object)
+		}
+		return listed
+	}
+
+	// Objects of the first page are listed before the error.
+	listed := list()
+	if len(listed) != 2 || listed[0].Key != "a" || listed[0].Err != nil {
+		t.Fatalf("Error: unexpected listing %v", listed)
+	}
+	if ToErrorResponse(listed[1].Err).Code != "AccessDenied" {
+		t.Fatal("Error: expected AccessDenied, got", listed[1].Err)
+	}
+
+	// Truncated pages which do not advance the listing fail instead of
+	// listing forever.
+	truncatedWithoutMarker = true
+	listed = list()
+	if len(listed) != 1 || listed[0].Err == nil {
+		t.Fatalf("Error: expected a single error, got %v", listed)
+	}
+}
+
+// Tests requests failing for buckets of unexpected owners.
+func TestExpectedBucketOwner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if owner := r.Header.Get("x-amz-expected-bucket-owner"); owner != "" {
+			if owner != "111122223333" || !strings.Contains(r.Header.Get("Authorization"), "x-amz-expected-bucket-owner") {
+				w.WriteHeader(http.StatusForbidden)
+				if r.Method != "HEAD" {
+					fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+				}
+				return
+			}
+		}
+		w.Header().Set("Content-Length", "5")
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == "GET" {
+			w.Write([]byte("hello"))
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := NewV4(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	// Owners are checked only once expected.
+	if _, err = c.StatObject("bucket", "object"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	if _, err = c.WithExpectedBucketOwner("111122223333").StatObject("bucket", "object"); err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.SetExpectedBucketOwner("444455556666")
+	if _, err = c.StatObject("bucket", "object"); ToErrorResponse(err).Code != "AccessDenied" {
+		t.Fatal("Error: expected AccessDenied, got", err)
+	}
+	if !strings.Contains(err.Error(), "444455556666") {
+		t.Fatal("Error: expected the owner in the error, got", err)
+	}
+	if err = c.BucketExists("bucket"); ToErrorResponse(err).Code != "AccessDenied" {
+		t.Fatal("Error: expected AccessDenied, got", err)
+	}
+	c.SetExpectedBucketOwner("")
+	if err = c.BucketExists("bucket"); err != nil {
+		t.Fatal("Error:", err)
+	}
+}
+
+// Tests getting object attributes, with parts listed over several
+// requests.
+func TestGetObjectAttributes(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["attributes"]; !ok || r.Method != "GET" {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		requested = append(requested, r.Header.Get("x-amz-object-attributes"))
+		w.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+		w.Header().Set("x-amz-version-id", "v1")
+		switch r.Header.Get("x-amz-part-number-marker") {
+		case "":
+			fmt.Fprint(w, `<GetObjectAttributesResponse><ETag>"etag-3"</ETag><Checksum><ChecksumSHA256>c3VtLTM=</ChecksumSHA256></Checksum>`+
+				`<ObjectParts><IsTruncated>true</IsTruncated><NextPartNumberMarker>2</NextPartNumberMarker><PartsCount>3</PartsCount>`+
+				`<Part><ChecksumSHA256>cDE=</ChecksumSHA256><PartNumber>1</PartNumber><Size>5242880</Size></Part>`+
+				`<Part><ChecksumSHA256>cDI=</ChecksumSHA256><PartNumber>2</PartNumber><Size>5242880</Size></Part></ObjectParts>`+
+				`<StorageClass>STANDARD</StorageClass><ObjectSize>10485861</ObjectSize></GetObjectAttributesResponse>`)
+		case "2":
+			fmt.Fprint(w, `<GetObjectAttributesResponse><ObjectParts><IsTruncated>false</IsTruncated><PartsCount>3</PartsCount>`+
+				`<Part><ChecksumSHA256>cDM=</ChecksumSHA256><PartNumber>3</PartNumber><Size>101</Size></Part></ObjectParts></GetObjectAttributesResponse>`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c, err := NewV4(u.Host, "ACCESS-KEY", "SECRET-KEY", true)
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	c.bucketLocCache.Set("bucket", "us-east-1")
+
+	if _, err = c.GetObjectAttributes("bucket", "object", nil); err == nil {
+		t.Fatal("Error: getting no attributes should fail")
+	}
+	if _, err = c.GetObjectAttributes("bucket", "object", []string{"Owner"}); err == nil {
+		t.Fatal("Error: getting an unknown attribute should fail")
+	}
+
+	attrs, err := c.GetObjectAttributes("bucket", "object", []string{ObjectAttributeETag, ObjectAttributeChecksum, ObjectAttributeObjectParts, ObjectAttributeStorageClass, ObjectAttributeObjectSize})
+	if err != nil {
+		t.Fatal("Error:", err)
+	}
+	expected := ObjectAttributes{
+		ETag:       "etag-3",
+		Checksum:   ObjectChecksum{SHA256: "c3VtLTM="},
+		PartsCount: 3,
+		Parts: []ObjectAttributePart{
+			{PartNumber: 1, Size: 5242880, Checksum: ObjectChecksum{SHA256: "cDE="}},
+			{PartNumber: 2, Size: 5242880, Checksum: ObjectChecksum{SHA256: "cDI="}},
+			{PartNumber: 3, Size: 101, Checksum: ObjectChecksum{SHA256: "cDM="}},
+		},
+		StorageClass: "STANDARD",
+		ObjectSize:   10485861,
+		LastModified: time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC),
+		VersionID:    "v1",
+	}
+	if !reflect.DeepEqual(attrs, expected) {
+		t.Fatalf("Error: expected %+v, got %+v", expected, attrs)
+	}
+	all := "ETag,Checksum,ObjectParts,StorageClass,ObjectSize"
+	if !reflect.DeepEqual(requested, []string{all, all}) {
+		t.Fatalf("Error: unexpected requested attributes %v", requested)
+	}
+}
+
+// Tests sub-resources signed with signature version '2', sub-resources
+// missing from the string to sign fail with SignatureDoesNotMatch.
+func TestStringToSignV2SubResources(t *testing.T) {
+	testCases := []struct {
+		method   string
+		url      string
+		resource string
+	}{
+		{"GET", "http://localhost:9000/bucket?cors=", "/bucket?cors"},
+		{"PUT", "http://localhost:9000/bucket?cors=", "/bucket?cors"},
+		{"DELETE", "http://localhost:9000/bucket?cors=", "/bucket?cors"},
+		{"POST", "http://localhost:9000/bucket/object?select=&select-type=2", "/bucket/object?select&select-type=2"},
+		{"PUT", "http://localhost:9000/bucket?object-lock=", "/bucket?object-lock"},
+		{"POST", "http://localhost:9000/bucket/object?restore=", "/bucket/object?restore"},
+		{"GET", "http://localhost:9000/bucket/object?attributes=", "/bucket/object?attributes"},
+	}
+	if !sort.StringsAreSorted(resourceList) {
+		t.Fatal("Error: sub-resources are not sorted")
+	}
+	for i, testCase := range testCases {
+		req, err := http.NewRequest(testCase.method, testCase.url, nil)
+		if err != nil {
+			t.Fatalf("Test %d: Error: %s", i+1, err)
+		}
+		if stringToSign := getStringToSignV2(*req); !strings.HasSuffix(stringToSign, "\n"+testCase.resource) {
+			t.Fatalf("Test %d: Error: expected resource %s, got string to sign %q", i+1, testCase.resource, stringToSign)
+		}
+	}
+}
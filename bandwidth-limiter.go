@@ -0,0 +1,105 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// bandwidthLimiter - token bucket shared by all the transfers of a
+// client, keeps the aggregate rate of concurrent requests under the
+// limit.
+type bandwidthLimiter struct {
+	// mutex is used for handling the concurrent reservations of
+	// tokens.
+	sync.Mutex
+
+	// Limit in bytes per second, also the size of the bucket.
+	bytesPerSec int64
+
+	// Available tokens, negative once reserved ahead of time.
+	tokens float64
+
+	// updated is the local time tokens were last refilled.
+	updated time.Time
+}
+
+// newBandwidthLimiter - Provides a new limiter with a full bucket.
+func newBandwidthLimiter(bytesPerSec int64) *bandwidthLimiter {
+	return &bandwidthLimiter{
+		bytesPerSec: bytesPerSec,
+		tokens:      float64(bytesPerSec),
+		updated:     time.Now(),
+	}
+}
+
+// Wait - Reserves n tokens and blocks until they are available.
+// Reservations are made in order, concurrent callers wait for the
+// tokens reserved before them.
+func (l *bandwidthLimiter) Wait(n int) {
+	l.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.updated).Seconds() * float64(l.bytesPerSec)
+	if l.tokens > float64(l.bytesPerSec) {
+		l.tokens = float64(l.bytesPerSec)
+	}
+	l.updated = now
+	l.tokens -= float64(n)
+	deficit := -l.tokens
+	l.Unlock()
+
+	if deficit > 0 {
+		time.Sleep(time.Duration(deficit / float64(l.bytesPerSec) * float64(time.Second)))
+	}
+}
+
+// limitedReader - reads from reader no faster than the limiter
+// allows.
+type limitedReader struct {
+	reader  io.Reader
+	limiter *bandwidthLimiter
+}
+
+// newLimitedReader - wraps reader, reader is returned as is for a nil
+// limiter.
+func newLimitedReader(reader io.Reader, limiter *bandwidthLimiter) io.Reader {
+	if limiter == nil {
+		return reader
+	}
+	return &limitedReader{reader: reader, limiter: limiter}
+}
+
+// Read - reads at most a bucket worth of bytes and waits for their
+// tokens before returning them.
+func (r *limitedReader) Read(p []byte) (n int, err error) {
+	if int64(len(p)) > r.limiter.bytesPerSec {
+		p = p[:r.limiter.bytesPerSec]
+	}
+	n, err = r.reader.Read(p)
+	if n > 0 {
+		r.limiter.Wait(n)
+	}
+	return n, err
+}
+
+// limitedReadCloser - limited response body, closes the wrapped body.
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
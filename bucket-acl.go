@@ -16,6 +16,11 @@
 
 package minio
 
+import (
+	"encoding/xml"
+	"fmt"
+)
+
 // BucketACL - Bucket level access control.
 type BucketACL string
 
@@ -73,3 +78,156 @@ func (b BucketACL) isPublic() bool {
 func (b BucketACL) isAuthenticated() bool {
 	return b == bucketAuthenticated
 }
+
+// GranteeType - type of the grantee of an ACL grant.
+type GranteeType string
+
+// Different types of grantees.
+const (
+	// Grantee identified by the canonical user ID of an account.
+	GranteeCanonicalUser = GranteeType("CanonicalUser")
+	// Predefined group of users identified by URI, e.g. AllUsersURI.
+	GranteeGroup = GranteeType("Group")
+	// Grantee identified by the email address of an account.
+	GranteeAmazonCustomerByEmail = GranteeType("AmazonCustomerByEmail")
+)
+
+// URIs of the predefined groups of grantees.
+const (
+	AllUsersURI           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	AuthenticatedUsersURI = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+	LogDeliveryURI        = "http://acs.amazonaws.com/groups/s3/LogDelivery"
+)
+
+// Permission - permission granted by an ACL grant.
+type Permission string
+
+// Different permissions of ACL grants.
+const (
+	PermissionFullControl = Permission("FULL_CONTROL")
+	PermissionRead        = Permission("READ")
+	PermissionWrite       = Permission("WRITE")
+	PermissionReadACP     = Permission("READ_ACP")
+	PermissionWriteACP    = Permission("WRITE_ACP")
+)
+
+// AccessControlPolicy - container for the owner and the explicit grants
+// of an access control list.
+type AccessControlPolicy struct {
+	XMLName xml.Name `xml:"AccessControlPolicy" json:"-"`
+	Owner   Owner
+	Grants  []Grant `xml:"AccessControlList>Grant"`
+}
+
+// Owner - owner of a bucket or object.
+type Owner struct {
+	ID          string
+	DisplayName string `xml:",omitempty"`
+}
+
+// Grant - a single permission granted to a grantee.
+type Grant struct {
+	Grantee    Grantee
+	Permission Permission
+}
+
+// Grantee - grantee of a grant. ID is set for canonical users, URI for
+// groups and EmailAddress for grantees by email.
+type Grantee struct {
+	Type         GranteeType `xml:"type,attr"`
+	ID           string
+	DisplayName  string
+	EmailAddress string
+	URI          string
+}
+
+// MarshalXML - encodes the grantee type as 'xsi:type' attribute, as
+// required by Amazon S3.
+func (g Grantee) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = append(start.Attr,
+		xml.Attr{Name: xml.Name{Local: "xmlns:xsi"}, Value: "http://www.w3.org/2001/XMLSchema-instance"},
+		xml.Attr{Name: xml.Name{Local: "xsi:type"}, Value: string(g.Type)},
+	)
+	return e.EncodeElement(struct {
+		ID           string `xml:",omitempty"`
+		DisplayName  string `xml:",omitempty"`
+		EmailAddress string `xml:",omitempty"`
+		URI          string `xml:",omitempty"`
+	}{g.ID, g.DisplayName, g.EmailAddress, g.URI}, start)
+}
+
+// isValidAccessControlPolicy - verify every grant names its grantee
+// as required by the grantee type and has a known permission.
+func isValidAccessControlPolicy(policy AccessControlPolicy) error {
+	for i, g := range policy.Grants {
+		switch g.Permission {
+		case PermissionFullControl, PermissionRead, PermissionWrite, PermissionReadACP, PermissionWriteACP:
+		default:
+			return ErrInvalidArgument(fmt.Sprintf("Grant %d has unrecognized permission %s.", i+1, g.Permission))
+		}
+		switch g.Grantee.Type {
+		case GranteeCanonicalUser:
+			if g.Grantee.ID == "" {
+				return ErrInvalidArgument(fmt.Sprintf("Grant %d to a canonical user has no ID.", i+1))
+			}
+		case GranteeGroup:
+			if g.Grantee.URI == "" {
+				return ErrInvalidArgument(fmt.Sprintf("Grant %d to a group has no URI.", i+1))
+			}
+		case GranteeAmazonCustomerByEmail:
+			if g.Grantee.EmailAddress == "" {
+				return ErrInvalidArgument(fmt.Sprintf("Grant %d to a customer by email has no email address.", i+1))
+			}
+		default:
+			return ErrInvalidArgument(fmt.Sprintf("Grant %d has unrecognized grantee type %s.", i+1, g.Grantee.Type))
+		}
+	}
+	return nil
+}
+
+// cannedACL - summarizes the grants of the policy as the canned ACL
+// granting the same permissions, ok is false if there is none.
+func (p AccessControlPolicy) cannedACL() (acl BucketACL, ok bool) {
+	// Boolean cues to indentify right canned acls.
+	var publicRead, publicWrite, authenticatedRead bool
+
+	// Handle grants.
+	for _, g := range p.Grants {
+		if g.Grantee.URI == "" && g.Permission == PermissionFullControl {
+			continue
+		}
+		if g.Grantee.URI == AuthenticatedUsersURI && g.Permission == PermissionRead {
+			authenticatedRead = true
+			break
+		} else if g.Grantee.URI == AllUsersURI && g.Permission == PermissionWrite {
+			publicWrite = true
+		} else if g.Grantee.URI == AllUsersURI && g.Permission == PermissionRead {
+			publicRead = true
+		}
+	}
+
+	switch {
+	case authenticatedRead:
+		return bucketAuthenticated, true
+	case !publicWrite && !publicRead:
+		return bucketPrivate, true
+	case !publicWrite && publicRead:
+		return bucketReadOnly, true
+	case publicRead && publicWrite:
+		return bucketPublic, true
+	}
+	return "", false
+}
+
+// isPublicRead - verify if the grants allow anonymous users to read.
+func (p AccessControlPolicy) isPublicRead() bool {
+	for _, g := range p.Grants {
+		if g.Grantee.URI != AllUsersURI {
+			continue
+		}
+		if g.Permission == PermissionRead || g.Permission == PermissionFullControl {
+			return true
+		}
+	}
+	return false
+}
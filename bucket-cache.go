@@ -65,16 +65,26 @@ func (r *bucketLocationCache) Delete(bucketName string) {
 	delete(r.items, bucketName)
 }
 
+// withBucketLocation - returns a copy of the client with a bucket
+// location cache of its own, which has region as the location of
+// bucketName. Requests to bucketName are then signed for region
+// without looking up its location, the client itself is unchanged.
+func (c Client) withBucketLocation(bucketName, region string) Client {
+	c.bucketLocCache = newBucketLocationCache()
+	c.bucketLocCache.Set(bucketName, region)
+	return c
+}
+
 // getBucketLocation - Get location for the bucketName from location map cache.
 func (c Client) getBucketLocation(bucketName string) (string, error) {
+	if location, ok := c.bucketLocCache.Get(bucketName); ok {
+		return location, nil
+	}
 	// For anonymous requests, default to "us-east-1" and let other calls
 	// move forward.
 	if c.anonymous {
 		return "us-east-1", nil
 	}
-	if location, ok := c.bucketLocCache.Get(bucketName); ok {
-		return location, nil
-	}
 
 	// Initialize a new request.
 	req, err := c.getBucketLocationRequest(bucketName)
@@ -138,8 +148,9 @@ func (c Client) getBucketLocationRequest(bucketName string) (*http.Request, erro
 	urlValues := make(url.Values)
 	urlValues.Set("location", "")
 
-	// Set get bucket location always as path style.
-	targetURL := c.endpointURL
+	// Set get bucket location always as path style, on a copy of the
+	// endpoint shared by concurrent requests.
+	targetURL := *c.endpointURL
 	targetURL.Path = path.Join(bucketName, "") + "/"
 	targetURL.RawQuery = urlValues.Encode()
 
@@ -151,17 +162,27 @@ func (c Client) getBucketLocationRequest(bucketName string) (*http.Request, erro
 
 	// Set UserAgent for the request.
 	c.setUserAgent(req)
+	c.setCustomHeader(req)
 
 	// Set sha256 sum for signature calculation only with signature version '4'.
 	if c.signature.isV4() {
 		req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sum256([]byte{})))
 	}
 
+	// Get credentials, retrieved again if expired.
+	accessKeyID, secretAccessKey, sessionToken, err := c.getCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	// Adjust signing time for server clock offset if enabled.
+	c.setSigningTime(req)
+
 	// Sign the request.
 	if c.signature.isV4() {
-		req = signV4(*req, c.accessKeyID, c.secretAccessKey, "us-east-1")
+		req = signV4(*req, accessKeyID, secretAccessKey, sessionToken, "us-east-1")
 	} else if c.signature.isV2() {
-		req = signV2(*req, c.accessKeyID, c.secretAccessKey)
+		req = signV2(*req, accessKeyID, secretAccessKey, sessionToken)
 	}
 	return req, nil
 }
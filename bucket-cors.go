@@ -0,0 +1,92 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// maxCORSRules - maximum number of rules in a CORS configuration.
+const maxCORSRules = 100
+
+// BucketCORS - container for bucket CORS configuration.
+type BucketCORS struct {
+	XMLName xml.Name   `xml:"CORSConfiguration" json:"-"`
+	Rules   []CORSRule `xml:"CORSRule"`
+}
+
+// CORSRule - a single CORS rule, cross origin requests are allowed if
+// their origin, method and headers match.
+type CORSRule struct {
+	// Optional unique identifier for the rule.
+	ID string `xml:"ID,omitempty"`
+	// Origins allowed, each may contain at most one '*' wildcard.
+	AllowedOrigins []string `xml:"AllowedOrigin"`
+	// Methods allowed, 'GET', 'PUT', 'POST', 'DELETE' or 'HEAD'.
+	AllowedMethods []string `xml:"AllowedMethod"`
+	// Headers allowed in preflight requests, each may contain at most
+	// one '*' wildcard.
+	AllowedHeaders []string `xml:"AllowedHeader,omitempty"`
+	// Response headers readable by browser applications.
+	ExposeHeaders []string `xml:"ExposeHeader,omitempty"`
+	// Seconds browsers may cache the preflight response.
+	MaxAgeSeconds int `xml:"MaxAgeSeconds,omitempty"`
+}
+
+// isValidBucketCORS - verify CORS configuration in accordance with
+//  - http://docs.aws.amazon.com/AmazonS3/latest/dev/cors.html
+func isValidBucketCORS(cors BucketCORS) error {
+	if len(cors.Rules) == 0 {
+		return ErrInvalidArgument("CORS configuration should have at least one rule.")
+	}
+	if len(cors.Rules) > maxCORSRules {
+		return ErrInvalidArgument("CORS configuration cannot have more than 100 rules.")
+	}
+	for _, rule := range cors.Rules {
+		if len(rule.ID) > 255 {
+			return ErrInvalidArgument("CORS rule ID cannot be greater than 255 characters.")
+		}
+		if len(rule.AllowedOrigins) == 0 {
+			return ErrInvalidArgument("CORS rule should have at least one allowed origin.")
+		}
+		if len(rule.AllowedMethods) == 0 {
+			return ErrInvalidArgument("CORS rule should have at least one allowed method.")
+		}
+		for _, method := range rule.AllowedMethods {
+			switch method {
+			case "GET", "PUT", "POST", "DELETE", "HEAD":
+			default:
+				return ErrInvalidArgument("CORS rule method '" + method + "' is not supported.")
+			}
+		}
+		for _, origin := range rule.AllowedOrigins {
+			if strings.Count(origin, "*") > 1 {
+				return ErrInvalidArgument("CORS rule origin '" + origin + "' can contain at most one wildcard.")
+			}
+		}
+		for _, header := range rule.AllowedHeaders {
+			if strings.Count(header, "*") > 1 {
+				return ErrInvalidArgument("CORS rule header '" + header + "' can contain at most one wildcard.")
+			}
+		}
+		if rule.MaxAgeSeconds < 0 {
+			return ErrInvalidArgument("CORS rule max age cannot be negative.")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// maxLifecycleRules - maximum number of rules in a lifecycle
+// configuration.
+const maxLifecycleRules = 1000
+
+// Lifecycle rule status values.
+const (
+	LifecycleEnabled  = "Enabled"
+	LifecycleDisabled = "Disabled"
+)
+
+// BucketLifecycle - container for bucket lifecycle configuration.
+type BucketLifecycle struct {
+	XMLName xml.Name        `xml:"LifecycleConfiguration" json:"-"`
+	Rules   []LifecycleRule `xml:"Rule"`
+}
+
+// LifecycleRule - a single lifecycle rule applied to all objects
+// matching Prefix.
+type LifecycleRule struct {
+	// Optional unique identifier for the rule.
+	ID string `xml:"ID,omitempty"`
+	// Rule applies to all objects with this key prefix.
+	Prefix string `xml:"Prefix"`
+	// Status is either LifecycleEnabled or LifecycleDisabled.
+	Status string `xml:"Status"`
+
+	// Actions, at least one of them must be set.
+	Expiration                     *LifecycleExpiration            `xml:"Expiration,omitempty"`
+	Transition                     *LifecycleTransition            `xml:"Transition,omitempty"`
+	AbortIncompleteMultipartUpload *AbortIncompleteMultipartUpload `xml:"AbortIncompleteMultipartUpload,omitempty"`
+}
+
+// LifecycleExpiration - expire objects after Days since creation or
+// on a given Date.
+type LifecycleExpiration struct {
+	Days int        `xml:"Days,omitempty"`
+	Date *time.Time `xml:"Date,omitempty"`
+}
+
+// LifecycleTransition - transition objects to StorageClass after Days
+// since creation or on a given Date.
+type LifecycleTransition struct {
+	Days         int        `xml:"Days,omitempty"`
+	Date         *time.Time `xml:"Date,omitempty"`
+	StorageClass string     `xml:"StorageClass"`
+}
+
+// AbortIncompleteMultipartUpload - abort incomplete multipart uploads
+// DaysAfterInitiation days after they were initiated.
+type AbortIncompleteMultipartUpload struct {
+	DaysAfterInitiation int `xml:"DaysAfterInitiation"`
+}
+
+// isValidBucketLifecycle - verify lifecycle configuration in accordance with
+//  - http://docs.aws.amazon.com/AmazonS3/latest/dev/intro-lifecycle-rules.html
+func isValidBucketLifecycle(lifecycle BucketLifecycle) error {
+	if len(lifecycle.Rules) == 0 {
+		return ErrInvalidArgument("Lifecycle configuration should have at least one rule.")
+	}
+	if len(lifecycle.Rules) > maxLifecycleRules {
+		return ErrInvalidArgument("Lifecycle configuration cannot have more than 1000 rules.")
+	}
+	for _, rule := range lifecycle.Rules {
+		if len(rule.ID) > 255 {
+			return ErrInvalidArgument("Lifecycle rule ID cannot be greater than 255 characters.")
+		}
+		if rule.Status != LifecycleEnabled && rule.Status != LifecycleDisabled {
+			return ErrInvalidArgument("Lifecycle rule status should be either 'Enabled' or 'Disabled'.")
+		}
+		if rule.Expiration == nil && rule.Transition == nil && rule.AbortIncompleteMultipartUpload == nil {
+			return ErrInvalidArgument("Lifecycle rule should have at least one action.")
+		}
+		if rule.Expiration != nil {
+			if rule.Expiration.Days < 0 {
+				return ErrInvalidArgument("Lifecycle expiration days cannot be negative.")
+			}
+			if (rule.Expiration.Days == 0) == (rule.Expiration.Date == nil) {
+				return ErrInvalidArgument("Lifecycle expiration should have either days or date.")
+			}
+		}
+		if rule.Transition != nil {
+			if rule.Transition.Days < 0 {
+				return ErrInvalidArgument("Lifecycle transition days cannot be negative.")
+			}
+			if (rule.Transition.Days == 0) == (rule.Transition.Date == nil) {
+				return ErrInvalidArgument("Lifecycle transition should have either days or date.")
+			}
+			if rule.Transition.StorageClass == "" {
+				return ErrInvalidArgument("Lifecycle transition storage class cannot be empty.")
+			}
+		}
+		if rule.AbortIncompleteMultipartUpload != nil {
+			if rule.AbortIncompleteMultipartUpload.DaysAfterInitiation <= 0 {
+				return ErrInvalidArgument("Lifecycle abort incomplete multipart upload days should be positive.")
+			}
+		}
+	}
+	return nil
+}
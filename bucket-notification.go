@@ -0,0 +1,202 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import "encoding/xml"
+
+// NotificationEventType is a S3 notification event associated to the bucket notification configuration
+type NotificationEventType string
+
+// The role of all event types are described in :
+// 	http://docs.aws.amazon.com/AmazonS3/latest/dev/NotificationHowTo.html#notification-how-to-event-types-and-destinations
+const (
+	ObjectCreatedAll                     NotificationEventType = "s3:ObjectCreated:*"
+	ObjectCreatedPut                     NotificationEventType = "s3:ObjectCreated:Put"
+	ObjectCreatedPost                    NotificationEventType = "s3:ObjectCreated:Post"
+	ObjectCreatedCopy                    NotificationEventType = "s3:ObjectCreated:Copy"
+	ObjectCreatedCompleteMultipartUpload NotificationEventType = "s3:ObjectCreated:CompleteMultipartUpload"
+	ObjectRemovedAll                     NotificationEventType = "s3:ObjectRemoved:*"
+	ObjectRemovedDelete                  NotificationEventType = "s3:ObjectRemoved:Delete"
+	ObjectRemovedDeleteMarkerCreated     NotificationEventType = "s3:ObjectRemoved:DeleteMarkerCreated"
+	ObjectReducedRedundancyLostObject    NotificationEventType = "s3:ReducedRedundancyLostObject"
+)
+
+// FilterRule - child of S3Key, a tag in the notification xml which
+// carries suffix/prefix filters
+type FilterRule struct {
+	Name  string `xml:"Name"`
+	Value string `xml:"Value"`
+}
+
+// S3Key - child of Filter, a tag in the notification xml which
+// carries suffix/prefix filters
+type S3Key struct {
+	FilterRules []FilterRule `xml:"FilterRule,omitempty"`
+}
+
+// Filter - a tag in the notification xml structure which carries
+// suffix/prefix filters
+type Filter struct {
+	S3Key S3Key `xml:"S3Key,omitempty"`
+}
+
+// NotificationConfig - represents one single notification configuration
+// such as topic, queue or lambda configuration.
+type NotificationConfig struct {
+	ID     string                  `xml:"Id,omitempty"`
+	Events []NotificationEventType `xml:"Event"`
+	Filter *Filter                 `xml:"Filter,omitempty"`
+}
+
+// AddEvents - adds events to the notification configuration.
+func (t *NotificationConfig) AddEvents(events ...NotificationEventType) {
+	t.Events = append(t.Events, events...)
+}
+
+// AddFilterPrefix - sets the prefix filter rule, only objects with
+// this key prefix trigger a notification.
+func (t *NotificationConfig) AddFilterPrefix(prefix string) {
+	t.addFilterRule(FilterRule{Name: "prefix", Value: prefix})
+}
+
+// AddFilterSuffix - sets the suffix filter rule, only objects with
+// this key suffix trigger a notification.
+func (t *NotificationConfig) AddFilterSuffix(suffix string) {
+	t.addFilterRule(FilterRule{Name: "suffix", Value: suffix})
+}
+
+// addFilterRule - adds a new filter rule or replaces an existing
+// rule with the same name.
+func (t *NotificationConfig) addFilterRule(newFilterRule FilterRule) {
+	if t.Filter == nil {
+		t.Filter = &Filter{}
+	}
+	for i, filterRule := range t.Filter.S3Key.FilterRules {
+		if filterRule.Name == newFilterRule.Name {
+			t.Filter.S3Key.FilterRules[i] = newFilterRule
+			return
+		}
+	}
+	t.Filter.S3Key.FilterRules = append(t.Filter.S3Key.FilterRules, newFilterRule)
+}
+
+// TopicConfig carries one single topic notification configuration
+type TopicConfig struct {
+	NotificationConfig
+	Topic string `xml:"Topic"`
+}
+
+// QueueConfig carries one single queue notification configuration
+type QueueConfig struct {
+	NotificationConfig
+	Queue string `xml:"Queue"`
+}
+
+// LambdaConfig carries one single cloudfunction notification configuration
+type LambdaConfig struct {
+	NotificationConfig
+	Lambda string `xml:"CloudFunction"`
+}
+
+// BucketNotification - the struct that represents the whole XML to be sent to the web service
+type BucketNotification struct {
+	XMLName       xml.Name       `xml:"NotificationConfiguration" json:"-"`
+	LambdaConfigs []LambdaConfig `xml:"CloudFunctionConfiguration"`
+	TopicConfigs  []TopicConfig  `xml:"TopicConfiguration"`
+	QueueConfigs  []QueueConfig  `xml:"QueueConfiguration"`
+}
+
+// isValidNotificationConfig - verify if notification configuration
+// has a target arn and at least one event.
+func isValidNotificationConfig(arn string, config NotificationConfig) error {
+	if arn == "" {
+		return ErrInvalidArgument("Notification configuration target arn cannot be empty.")
+	}
+	if len(config.Events) == 0 {
+		return ErrInvalidArgument("Notification configuration should have at least one event.")
+	}
+	return nil
+}
+
+// isValidBucketNotification - verify all notification configurations.
+func isValidBucketNotification(notification BucketNotification) error {
+	for _, config := range notification.LambdaConfigs {
+		if err := isValidNotificationConfig(config.Lambda, config.NotificationConfig); err != nil {
+			return err
+		}
+	}
+	for _, config := range notification.TopicConfigs {
+		if err := isValidNotificationConfig(config.Topic, config.NotificationConfig); err != nil {
+			return err
+		}
+	}
+	for _, config := range notification.QueueConfigs {
+		if err := isValidNotificationConfig(config.Queue, config.NotificationConfig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// identity represents the user id, this is a compliance field.
+type identity struct {
+	PrincipalID string `json:"principalId"`
+}
+
+// Notification event bucket metadata.
+type bucketMeta struct {
+	Name          string   `json:"name"`
+	OwnerIdentity identity `json:"ownerIdentity"`
+	ARN           string   `json:"arn"`
+}
+
+// Notification event object metadata.
+type objectMeta struct {
+	Key       string `json:"key"`
+	Size      int64  `json:"size,omitempty"`
+	ETag      string `json:"eTag,omitempty"`
+	VersionID string `json:"versionId,omitempty"`
+	Sequencer string `json:"sequencer"`
+}
+
+// Notification event server specific metadata.
+type eventMeta struct {
+	SchemaVersion   string     `json:"s3SchemaVersion"`
+	ConfigurationID string     `json:"configurationId"`
+	Bucket          bucketMeta `json:"bucket"`
+	Object          objectMeta `json:"object"`
+}
+
+// NotificationEvent represents an Amazon an S3 bucket notification event.
+type NotificationEvent struct {
+	EventVersion      string            `json:"eventVersion"`
+	EventSource       string            `json:"eventSource"`
+	AwsRegion         string            `json:"awsRegion"`
+	EventTime         string            `json:"eventTime"`
+	EventName         string            `json:"eventName"`
+	UserIdentity      identity          `json:"userIdentity"`
+	RequestParameters map[string]string `json:"requestParameters"`
+	ResponseElements  map[string]string `json:"responseElements"`
+	S3                eventMeta         `json:"s3"`
+}
+
+// NotificationInfo - represents the collection of notification events, additionally
+// also reports errors if any while listening on bucket notifications.
+type NotificationInfo struct {
+	Records []NotificationEvent
+	Err     error
+}
@@ -0,0 +1,59 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import "encoding/xml"
+
+// VersioningStatus - Bucket versioning state.
+type VersioningStatus string
+
+// Different versioning states of a bucket.
+const (
+	// Bucket was never configured for versioning.
+	VersioningUnversioned = VersioningStatus("")
+	VersioningEnabled     = VersioningStatus("Enabled")
+	VersioningSuspended   = VersioningStatus("Suspended")
+)
+
+// Stringify versioning status.
+func (v VersioningStatus) String() string {
+	if string(v) == "" {
+		return "Unversioned"
+	}
+	return string(v)
+}
+
+// isValidVersioningStatus - Is provided status allowed to be set on a
+// bucket, a bucket cannot be set back to unversioned.
+func (v VersioningStatus) isValidVersioningStatus() bool {
+	return v == VersioningEnabled || v == VersioningSuspended
+}
+
+// BucketVersioningConfiguration - container for bucket versioning
+// configuration.
+type BucketVersioningConfiguration struct {
+	XMLName xml.Name         `xml:"VersioningConfiguration" json:"-"`
+	Status  VersioningStatus `xml:"Status,omitempty"`
+	// MFADelete is either "Enabled" or "Disabled", only present if
+	// bucket was ever configured with MFA delete.
+	MFADelete string `xml:"MfaDelete,omitempty"`
+}
+
+// IsMFADeleteEnabled - Is MFA delete enabled on the bucket.
+func (b BucketVersioningConfiguration) IsMFADeleteEnabled() bool {
+	return b.MFADelete == "Enabled"
+}
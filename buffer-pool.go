@@ -0,0 +1,91 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"bytes"
+	"sync"
+)
+
+// bufferPool - Provides staging buffers reused across uploads to
+// reduce allocations, buffers are reset before they are reused so
+// that no data of an object bleeds into another.
+type bufferPool struct {
+	// Buffers larger than maxSize are not kept in the pool, pooling
+	// is disabled if maxSize is zero.
+	maxSize int64
+
+	// buffers holds *bytes.Buffer used to stage payloads.
+	buffers sync.Pool
+
+	// readBuffers holds []byte of optimalReadBufferSize used to read
+	// at offsets.
+	readBuffers sync.Pool
+}
+
+// newBufferPool - Provides a new buffer pool to be used internally
+// with the client object, keeping buffers of up to maxSize bytes.
+func newBufferPool(maxSize int64) *bufferPool {
+	return &bufferPool{maxSize: maxSize}
+}
+
+// Get - Returns an empty buffer.
+func (p *bufferPool) Get() *bytes.Buffer {
+	if buf, ok := p.buffers.Get().(*bytes.Buffer); ok {
+		return buf
+	}
+	return new(bytes.Buffer)
+}
+
+// Put - Resets the buffer and returns it to the pool, the buffer must
+// not be used afterwards.
+func (p *bufferPool) Put(buf *bytes.Buffer) {
+	if int64(buf.Cap()) > p.maxSize {
+		return
+	}
+	buf.Reset()
+	p.buffers.Put(buf)
+}
+
+// GetReadBuffer - Returns a read buffer of optimalReadBufferSize.
+func (p *bufferPool) GetReadBuffer() []byte {
+	if buf, ok := p.readBuffers.Get().([]byte); ok {
+		return buf
+	}
+	return make([]byte, optimalReadBufferSize)
+}
+
+// PutReadBuffer - Returns the read buffer to the pool, the buffer
+// must not be used afterwards.
+func (p *bufferPool) PutReadBuffer(buf []byte) {
+	if int64(len(buf)) > p.maxSize {
+		return
+	}
+	p.readBuffers.Put(buf)
+}
+
+// SetBufferPoolSize - set the maximum size of the staging buffers kept
+// for reuse across uploads, defaults to 5MiB which covers all objects
+// uploaded with a single PUT. Raise it to the part size to also reuse
+// buffers of multipart uploads, a size of zero disables reuse.
+func (c *Client) SetBufferPoolSize(size int64) error {
+	if size < 0 {
+		return ErrInvalidArgument("Buffer pool size cannot be negative.")
+	}
+	c.bufferPool = newBufferPool(size)
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// clockSkewRefreshInterval - interval after which the cached clock
+// offset is measured again from the next server response.
+const clockSkewRefreshInterval = 15 * time.Minute
+
+// clockSkew - Provides simple mechanism to hold the measured offset
+// between server and local clock in memory.
+type clockSkew struct {
+	// mutex is used for handling the concurrent
+	// read/write requests for offset.
+	sync.RWMutex
+
+	// Set to 'true' to adjust signing time by the offset.
+	enabled bool
+
+	// offset is server time minus local time.
+	offset time.Duration
+
+	// updated is the local time offset was last measured.
+	updated time.Time
+}
+
+// newClockSkew - Provides a new clock skew cache to be used
+// internally with the client object.
+func newClockSkew() *clockSkew {
+	return &clockSkew{}
+}
+
+// Enable - Enables or disables adjusting the signing time.
+func (s *clockSkew) Enable(enabled bool) {
+	s.Lock()
+	defer s.Unlock()
+	s.enabled = enabled
+}
+
+// IsEnabled - Returns true if signing time is adjusted.
+func (s *clockSkew) IsEnabled() bool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.enabled
+}
+
+// IsStale - Returns true if offset was never measured or is older
+// than clockSkewRefreshInterval.
+func (s *clockSkew) IsStale() bool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.updated.IsZero() || time.Since(s.updated) > clockSkewRefreshInterval
+}
+
+// Now - Returns current local time in UTC adjusted by the offset if
+// enabled.
+func (s *clockSkew) Now() time.Time {
+	s.RLock()
+	defer s.RUnlock()
+	if !s.enabled {
+		return time.Now().UTC()
+	}
+	return time.Now().UTC().Add(s.offset)
+}
+
+// Set - Persists the offset measured at localTime.
+func (s *clockSkew) Set(offset time.Duration, localTime time.Time) time.Duration {
+	s.Lock()
+	defer s.Unlock()
+	s.offset = offset
+	s.updated = localTime
+	return offset
+}
+
+// Update - Measures the offset from the 'Date' header of a server
+// response received at localTime and persists it.
+func (s *clockSkew) Update(resp *http.Response, localTime time.Time) error {
+	serverTime, err := parseServerTime(resp)
+	if err != nil {
+		return err
+	}
+	s.Set(serverTime.Sub(localTime), localTime)
+	return nil
+}
+
+// parseServerTime - parses server time from the 'Date' header of a
+// server response.
+func parseServerTime(resp *http.Response) (time.Time, error) {
+	date := resp.Header.Get("Date")
+	if date == "" {
+		return time.Time{}, ErrInvalidArgument("Server response is missing the Date header.")
+	}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return time.Time{}, ErrInvalidArgument("Server response has an invalid Date header " + date + ".")
+	}
+	return serverTime.UTC(), nil
+}
+
+// getServerTime - reads the server time from the 'Date' header of a
+// response to an unsigned service request, any response of the server
+// is sufficient. Returns the server time along with the local time
+// half way through the round trip.
+func (c Client) getServerTime() (serverTime time.Time, localTime time.Time, err error) {
+	req, err := http.NewRequest("HEAD", c.endpointURL.String(), nil)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	c.setUserAgent(req)
+	start := time.Now().UTC()
+	resp, err := c.do(req)
+	defer closeResponse(resp)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrEndpointUnreachable(c.endpointURL.Host, err)
+	}
+	localTime = start.Add(time.Now().UTC().Sub(start) / 2)
+	serverTime, err = parseServerTime(resp)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return serverTime, localTime, nil
+}
+
+// GetServerTime - returns the current time of the server, accurate to
+// a second.
+func (c Client) GetServerTime() (time.Time, error) {
+	serverTime, _, err := c.getServerTime()
+	return serverTime, err
+}
+
+// GetClockSkew - measures the offset of the server clock against the
+// local clock, positive if the server clock is ahead. The measured
+// offset is cached and used to adjust the signing time if enabled
+// with SetClockSkewAdjustment.
+func (c Client) GetClockSkew() (time.Duration, error) {
+	serverTime, localTime, err := c.getServerTime()
+	if err != nil {
+		return 0, err
+	}
+	return c.clockSkew.Set(serverTime.Sub(localTime), localTime), nil
+}
+
+// SetClockSkewAdjustment - adjust the signing time of all requests
+// by the offset between server and local clock, so that requests
+// are not rejected with 'RequestTimeTooSkewed' on hosts with a
+// drifted clock.
+//
+// The offset is measured from the 'Date' header of server responses
+// and refreshed every 15 minutes, or immediately when a request is
+// rejected with 'RequestTimeTooSkewed'. Presigned requests are not
+// adjusted.
+func (c *Client) SetClockSkewAdjustment(enabled bool) {
+	c.clockSkew.Enable(enabled)
+}
+
+// setSigningTime - sets the adjusted signing time on the request if
+// clock skew adjustment is enabled, honored by signV2 and signV4.
+func (c Client) setSigningTime(req *http.Request) {
+	if !c.clockSkew.IsEnabled() {
+		return
+	}
+	t := c.clockSkew.Now()
+	if c.signature.isV2() {
+		req.Header.Set("Date", t.Format(http.TimeFormat))
+	} else if c.signature.isV4() {
+		req.Header.Set("X-Amz-Date", t.Format(iso8601DateFormat))
+	}
+}
@@ -40,3 +40,15 @@ const maxMultipartPutObjectSize = 1024 * 1024 * 1024 * 1024 * 5
 // optimalReadBufferSize - optimal buffer 5MiB used for reading
 // through Read operation.
 const optimalReadBufferSize = 1024 * 1024 * 5
+
+// downloadChunkSize - default size 16MiB of the ranges downloaded in
+// parallel by Downloader.
+const downloadChunkSize = 1024 * 1024 * 16
+
+// maxDownloadRestarts - maximum number of times Downloader restarts a
+// download of an object overwritten meanwhile.
+const maxDownloadRestarts = 3
+
+// throttleRampUpRequests - number of successful requests after which
+// adaptive throttling allows one more concurrent request.
+const throttleRampUpRequests = 10
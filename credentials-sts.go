@@ -0,0 +1,168 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// defaultSTSEndpoint - AWS Security Token Service endpoint.
+const defaultSTSEndpoint = "https://sts.amazonaws.com"
+
+// defaultSTSDuration - default lifetime of assumed role credentials.
+const defaultSTSDuration = time.Hour
+
+// STSAssumeRoleProvider - provides temporary credentials of an IAM
+// role assumed with the AWS Security Token Service. The temporary
+// credentials are retrieved again once they expire.
+type STSAssumeRoleProvider struct {
+	expiry
+
+	// Endpoint of the Security Token Service, defaults to
+	// https://sts.amazonaws.com.
+	Endpoint string
+
+	// Location used to sign the request, defaults to 'us-east-1'.
+	Location string
+
+	// Credentials allowed to assume the role.
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// Role to assume.
+	RoleARN string
+
+	// Name identifying the session, defaults to 'minio-go'.
+	RoleSessionName string
+
+	// Lifetime of the credentials, defaults to an hour.
+	Duration time.Duration
+}
+
+// assumeRoleResponse - container for AssumeRole response.
+type assumeRoleResponse struct {
+	XMLName xml.Name `xml:"AssumeRoleResponse" json:"-"`
+	Result  struct {
+		Credentials struct {
+			AccessKeyID     string `xml:"AccessKeyId"`
+			SecretAccessKey string
+			SessionToken    string
+			Expiration      time.Time
+		}
+	} `xml:"AssumeRoleResult"`
+}
+
+// stsErrorResponse - container for Security Token Service error
+// response.
+type stsErrorResponse struct {
+	XMLName xml.Name `xml:"ErrorResponse" json:"-"`
+	Error   struct {
+		Code    string
+		Message string
+	}
+	RequestID string `xml:"RequestId"`
+}
+
+// Retrieve - returns temporary credentials of the assumed role.
+func (p *STSAssumeRoleProvider) Retrieve() (string, string, string, error) {
+	if p.AccessKeyID == "" || p.SecretAccessKey == "" {
+		return "", "", "", ErrInvalidArgument("Access key and secret key cannot be empty.")
+	}
+	if p.RoleARN == "" {
+		return "", "", "", ErrInvalidArgument("Role ARN cannot be empty.")
+	}
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = defaultSTSEndpoint
+	}
+	location := p.Location
+	if location == "" {
+		location = "us-east-1"
+	}
+	roleSessionName := p.RoleSessionName
+	if roleSessionName == "" {
+		roleSessionName = "minio-go"
+	}
+	duration := p.Duration
+	if duration == 0 {
+		duration = defaultSTSDuration
+	}
+
+	// Prepare AssumeRole form.
+	form := make(url.Values)
+	form.Set("Action", "AssumeRole")
+	form.Set("Version", "2011-06-15")
+	form.Set("RoleArn", p.RoleARN)
+	form.Set("RoleSessionName", roleSessionName)
+	form.Set("DurationSeconds", strconv.Itoa(int(duration/time.Second)))
+	formBytes := []byte(form.Encode())
+
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(formBytes))
+	if err != nil {
+		return "", "", "", err
+	}
+	req.ContentLength = int64(len(formBytes))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sum256(formBytes)))
+	req = signV4STS(*req, p.AccessKeyID, p.SecretAccessKey, location)
+
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	resp, err := httpClient.Do(req)
+	defer closeResponse(resp)
+	if err != nil {
+		return "", "", "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", "", httpRespToSTSErrorResponse(resp)
+	}
+
+	response := assumeRoleResponse{}
+	if err = xmlDecoder(resp.Body, &response); err != nil {
+		return "", "", "", err
+	}
+	creds := response.Result.Credentials
+	p.SetExpiration(creds.Expiration)
+	return creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken, nil
+}
+
+// httpRespToSTSErrorResponse - returns a new encoded ErrorResponse
+// structure from a Security Token Service error response.
+func httpRespToSTSErrorResponse(resp *http.Response) error {
+	errBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	stsErr := stsErrorResponse{}
+	if err = xml.Unmarshal(errBody, &stsErr); err != nil || stsErr.Error.Code == "" {
+		return ErrorResponse{
+			Code:    resp.Status,
+			Message: "Security Token Service request failed.",
+		}
+	}
+	return ErrorResponse{
+		Code:      stsErr.Error.Code,
+		Message:   stsErr.Error.Message,
+		RequestID: stsErr.RequestID,
+	}
+}
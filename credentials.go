@@ -0,0 +1,386 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2015 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialsProvider - interface implemented by every source of
+// credentials.
+type CredentialsProvider interface {
+	// Retrieve returns the access and secret keys along with the
+	// session token of temporary credentials, or an error if the
+	// provider has no credentials.
+	Retrieve() (accessKeyID, secretAccessKey, sessionToken string, err error)
+
+	// IsExpired returns true if the retrieved credentials have
+	// expired and have to be retrieved again.
+	IsExpired() bool
+}
+
+// Credentials - resolves credentials from a chain of providers, the
+// first provider returning credentials wins. Credentials are cached
+// until they expire, safe for concurrent use.
+type Credentials struct {
+	// mutex is used for handling the concurrent
+	// retrieval of credentials.
+	sync.Mutex
+
+	providers []CredentialsProvider
+
+	// provider of the cached credentials.
+	provider        CredentialsProvider
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// NewCredentials - instantiate credentials resolved from providers in
+// the given order.
+func NewCredentials(providers ...CredentialsProvider) *Credentials {
+	return &Credentials{providers: providers}
+}
+
+// NewDefaultCredentials - instantiate credentials resolved in the
+// same order as the AWS SDK. Explicit keys if not empty, then the
+// environment, then the shared credentials file and finally the
+// EC2/ECS instance metadata.
+func NewDefaultCredentials(accessKeyID, secretAccessKey string) *Credentials {
+	return NewCredentials(
+		&StaticProvider{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey},
+		&EnvProvider{},
+		&FileProvider{},
+		&IAMProvider{},
+	)
+}
+
+// Get - returns the cached credentials, or credentials of the first
+// provider in the chain which has credentials if the cached ones have
+// expired.
+func (c *Credentials) Get() (accessKeyID, secretAccessKey, sessionToken string, err error) {
+	c.Lock()
+	defer c.Unlock()
+	if c.provider != nil && !c.provider.IsExpired() {
+		return c.accessKeyID, c.secretAccessKey, c.sessionToken, nil
+	}
+	c.provider = nil
+	for _, provider := range c.providers {
+		accessKeyID, secretAccessKey, sessionToken, err = provider.Retrieve()
+		if err == nil && accessKeyID != "" && secretAccessKey != "" {
+			c.provider = provider
+			c.accessKeyID, c.secretAccessKey, c.sessionToken = accessKeyID, secretAccessKey, sessionToken
+			return accessKeyID, secretAccessKey, sessionToken, nil
+		}
+	}
+	return "", "", "", ErrInvalidArgument("No credentials found in any of the credential providers.")
+}
+
+// Expire - expires the cached credentials, they are retrieved again
+// on the next Get.
+func (c *Credentials) Expire() {
+	c.Lock()
+	defer c.Unlock()
+	c.provider = nil
+}
+
+// defaultExpiryWindow - temporary credentials are retrieved again
+// this long before they expire, so that requests in flight are not
+// signed with expired credentials.
+const defaultExpiryWindow = time.Minute
+
+// expiry - tracks the expiration of temporary credentials, embedded
+// by providers of temporary credentials.
+type expiry struct {
+	sync.RWMutex
+	expiration time.Time
+}
+
+// SetExpiration - sets the expiration of retrieved credentials, a
+// zero expiration never expires.
+func (e *expiry) SetExpiration(expiration time.Time) {
+	e.Lock()
+	defer e.Unlock()
+	if !expiration.IsZero() {
+		expiration = expiration.Add(-defaultExpiryWindow)
+	}
+	e.expiration = expiration
+}
+
+// IsExpired - returns true if the credentials have expired.
+func (e *expiry) IsExpired() bool {
+	e.RLock()
+	defer e.RUnlock()
+	return !e.expiration.IsZero() && time.Now().After(e.expiration)
+}
+
+// getCredentials - returns the credentials to sign requests with,
+// retrieved again from the credential providers once expired.
+func (c Client) getCredentials() (accessKeyID, secretAccessKey, sessionToken string, err error) {
+	if c.credentials == nil {
+		return c.accessKeyID, c.secretAccessKey, c.sessionToken, nil
+	}
+	return c.credentials.Get()
+}
+
+// StaticProvider - provides explicitly set credentials, SessionToken
+// is only set for temporary credentials.
+type StaticProvider struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// Retrieve - returns the explicitly set credentials.
+func (p *StaticProvider) Retrieve() (string, string, string, error) {
+	if p.AccessKeyID == "" || p.SecretAccessKey == "" {
+		return "", "", "", ErrInvalidArgument("Access key and secret key cannot be empty.")
+	}
+	return p.AccessKeyID, p.SecretAccessKey, p.SessionToken, nil
+}
+
+// IsExpired - explicitly set credentials never expire.
+func (p *StaticProvider) IsExpired() bool {
+	return false
+}
+
+// EnvProvider - provides credentials from the environment variables
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, or AWS_ACCESS_KEY and
+// AWS_SECRET_KEY, along with AWS_SESSION_TOKEN for temporary
+// credentials.
+type EnvProvider struct{}
+
+// Retrieve - returns credentials from the environment.
+func (p *EnvProvider) Retrieve() (string, string, string, error) {
+	accessKeyID := os.Getenv("AWS_ACCESS_KEY_ID")
+	if accessKeyID == "" {
+		accessKeyID = os.Getenv("AWS_ACCESS_KEY")
+	}
+	secretAccessKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if secretAccessKey == "" {
+		secretAccessKey = os.Getenv("AWS_SECRET_KEY")
+	}
+	if accessKeyID == "" || secretAccessKey == "" {
+		return "", "", "", ErrInvalidArgument("Environment has no AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.")
+	}
+	return accessKeyID, secretAccessKey, os.Getenv("AWS_SESSION_TOKEN"), nil
+}
+
+// IsExpired - credentials from the environment never expire.
+func (p *EnvProvider) IsExpired() bool {
+	return false
+}
+
+// FileProvider - provides credentials of a profile in the AWS shared
+// credentials file.
+type FileProvider struct {
+	// Path of the shared credentials file, defaults to the value of
+	// AWS_SHARED_CREDENTIALS_FILE or ~/.aws/credentials.
+	Filename string
+
+	// Profile to read, defaults to the value of AWS_PROFILE or
+	// 'default'.
+	Profile string
+}
+
+// Retrieve - returns credentials of the profile in the shared
+// credentials file.
+func (p *FileProvider) Retrieve() (string, string, string, error) {
+	filename := p.Filename
+	if filename == "" {
+		filename = os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	}
+	if filename == "" {
+		homeDir := os.Getenv("HOME")
+		if runtime.GOOS == "windows" {
+			homeDir = os.Getenv("USERPROFILE")
+		}
+		if homeDir == "" {
+			return "", "", "", ErrInvalidArgument("Unable to find home directory for the shared credentials file.")
+		}
+		filename = filepath.Join(homeDir, ".aws", "credentials")
+	}
+	profile := p.Profile
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+
+	values, err := readCredentialsFileProfile(filename, profile)
+	if err != nil {
+		return "", "", "", err
+	}
+	accessKeyID := values["aws_access_key_id"]
+	secretAccessKey := values["aws_secret_access_key"]
+	if accessKeyID == "" || secretAccessKey == "" {
+		return "", "", "", ErrInvalidArgument("Profile " + profile + " in " + filename + " has no aws_access_key_id and aws_secret_access_key.")
+	}
+	return accessKeyID, secretAccessKey, values["aws_session_token"], nil
+}
+
+// IsExpired - credentials from the shared credentials file never
+// expire.
+func (p *FileProvider) IsExpired() bool {
+	return false
+}
+
+// readCredentialsFileProfile - reads all the key value pairs of a
+// profile section from an ini formatted credentials file.
+func readCredentialsFileProfile(filename, profile string) (map[string]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	found := false
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// Skip empty lines and comments.
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section == profile {
+				found = true
+			}
+			continue
+		}
+		if section != profile {
+			continue
+		}
+		if i := strings.Index(line, "="); i > 0 {
+			values[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrInvalidArgument("Profile " + profile + " not found in " + filename + ".")
+	}
+	return values, nil
+}
+
+/// IAM instance metadata.
+
+// defaultIAMEndpoint - EC2 instance metadata endpoint.
+const defaultIAMEndpoint = "http://169.254.169.254"
+
+// defaultECSEndpoint - ECS task metadata endpoint.
+const defaultECSEndpoint = "http://169.254.170.2"
+
+// iamSecurityCredentialsPath - path of the EC2 instance metadata
+// listing the IAM role of the instance.
+const iamSecurityCredentialsPath = "/latest/meta-data/iam/security-credentials/"
+
+// IAMProvider - provides credentials of the IAM role of an EC2
+// instance or ECS task from its metadata endpoint. The temporary
+// credentials are retrieved again once they expire.
+type IAMProvider struct {
+	expiry
+
+	// Endpoint of the instance metadata, defaults to the ECS endpoint
+	// if AWS_CONTAINER_CREDENTIALS_RELATIVE_URI is set and to the EC2
+	// endpoint otherwise.
+	Endpoint string
+}
+
+// iamCredentials - credentials returned by the instance metadata.
+type iamCredentials struct {
+	Code            string
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string
+	Token           string
+	Expiration      time.Time
+}
+
+// Retrieve - returns credentials from the instance metadata.
+func (p *IAMProvider) Retrieve() (string, string, string, error) {
+	// Instance metadata is only reachable from within the instance,
+	// fail fast elsewhere.
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+
+	var credentialsURL string
+	if relativeURI := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relativeURI != "" {
+		endpoint := p.Endpoint
+		if endpoint == "" {
+			endpoint = defaultECSEndpoint
+		}
+		credentialsURL = endpoint + relativeURI
+	} else {
+		endpoint := p.Endpoint
+		if endpoint == "" {
+			endpoint = defaultIAMEndpoint
+		}
+		// Find the IAM role of the instance.
+		roles, err := getIAMMetadata(httpClient, endpoint+iamSecurityCredentialsPath)
+		if err != nil {
+			return "", "", "", err
+		}
+		// Multiple roles are listed one per line, use the first.
+		roleName := strings.TrimSpace(strings.SplitN(string(roles), "\n", 2)[0])
+		if roleName == "" {
+			return "", "", "", ErrInvalidArgument("Instance has no IAM role.")
+		}
+		credentialsURL = endpoint + iamSecurityCredentialsPath + roleName
+	}
+
+	data, err := getIAMMetadata(httpClient, credentialsURL)
+	if err != nil {
+		return "", "", "", err
+	}
+	creds := iamCredentials{}
+	if err = json.Unmarshal(data, &creds); err != nil {
+		return "", "", "", err
+	}
+	// ECS responses carry no code.
+	if creds.Code != "" && creds.Code != "Success" {
+		return "", "", "", ErrInvalidArgument("Failed to retrieve IAM credentials, " + creds.Code + ".")
+	}
+	p.SetExpiration(creds.Expiration)
+	return creds.AccessKeyID, creds.SecretAccessKey, creds.Token, nil
+}
+
+// getIAMMetadata - returns the body of a successful instance metadata
+// request.
+func getIAMMetadata(httpClient *http.Client, metadataURL string) ([]byte, error) {
+	resp, err := httpClient.Get(metadataURL)
+	defer closeResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, httpRespToErrorResponse(resp, "", "")
+	}
+	return ioutil.ReadAll(resp.Body)
+}
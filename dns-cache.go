@@ -0,0 +1,121 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// dialFunc - dials connections, same as net.Dialer.DialContext.
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// dnsCache - caches the addresses of the hosts dialed by a client,
+// new connections are then opened without a DNS lookup.
+type dnsCache struct {
+	// mutex is used for handling the concurrent
+	// read/write requests for cache.
+	sync.Mutex
+
+	// Addresses are looked up again once older than ttl.
+	ttl time.Duration
+
+	// Dials the looked up addresses.
+	dial dialFunc
+
+	// Looks up the addresses of a host, net.DefaultResolver by
+	// default.
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+
+	// items holds the cached addresses by host.
+	items map[string]dnsCacheItem
+}
+
+// dnsCacheItem - addresses of a host and the time they expire.
+type dnsCacheItem struct {
+	addrs   []string
+	expires time.Time
+}
+
+// newDNSCache - Provides a new empty cache dialing with dial.
+func newDNSCache(ttl time.Duration, dial dialFunc) *dnsCache {
+	return &dnsCache{
+		ttl:        ttl,
+		dial:       dial,
+		lookupHost: net.DefaultResolver.LookupHost,
+		items:      make(map[string]dnsCacheItem),
+	}
+}
+
+// lookup - returns the cached addresses of host, looks them up if
+// missing or expired.
+func (d *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	d.Lock()
+	item, ok := d.items[host]
+	d.Unlock()
+	if ok && time.Now().Before(item.expires) {
+		return item.addrs, nil
+	}
+
+	addrs, err := d.lookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	d.Lock()
+	d.items[host] = dnsCacheItem{
+		addrs:   addrs,
+		expires: time.Now().Add(d.ttl),
+	}
+	d.Unlock()
+	return addrs, nil
+}
+
+// Delete - Deletes the addresses of host from cache.
+func (d *dnsCache) Delete(host string) {
+	d.Lock()
+	defer d.Unlock()
+	delete(d.items, host)
+}
+
+// DialContext - dials addr through the cached addresses of its host,
+// trying one after the other. Once all of them fail they are deleted,
+// the next connection looks them up again.
+func (d *dnsCache) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return d.dial(ctx, network, addr)
+	}
+	addrs, err := d.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range addrs {
+		var conn net.Conn
+		conn, err = d.dial(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	// The addresses may be stale.
+	d.Delete(host)
+	if err == nil {
+		err = &net.DNSError{Err: "no such host", Name: host}
+	}
+	return nil, err
+}
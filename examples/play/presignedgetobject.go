@@ -43,8 +43,9 @@ func main() {
 	reqParams := make(url.Values)
 	reqParams.Set("response-content-disposition", "attachment; filename=\"your-filename.txt\"")
 
-	// Gernerate presigned get object url.
-	presignedURL, err := s3Client.PresignedGetObject("my-bucketname", "my-objectname", time.Duration(1000)*time.Second, reqParams)
+	// Gernerate presigned get object url, signed for 'us-east-1'
+	// without looking up the location of the bucket.
+	presignedURL, err := s3Client.PresignedGetObjectWithRegion("my-bucketname", "my-objectname", time.Duration(1000)*time.Second, reqParams, "us-east-1")
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -0,0 +1,67 @@
+// +build ignore
+
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"log"
+
+	"github.com/minio/minio-go"
+)
+
+func main() {
+	// Note: YOUR-ACCESSKEYID, YOUR-SECRETACCESSKEY, my-bucketname, my-objectname, my-sourcebucketname,
+	// my-sourceobjectname1 and my-sourceobjectname2 are dummy values, please replace them with original values.
+
+	// Requests are always secure (HTTPS) by default. Set insecure=true to enable insecure (HTTP) access.
+	// This boolean value is the last argument for New().
+
+	// New returns an Amazon S3 compatible client object. API copatibality (v2 or v4) is automatically
+	// determined based on the Endpoint value.
+	s3Client, err := minio.New("s3.amazonaws.com", "YOUR-ACCESSKEYID", "YOUR-SECRETACCESSKEY", false)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// Prepare the sources, all sources except the last one must be at least 5MiB.
+	src1, err := minio.NewSource("my-sourcebucketname", "my-sourceobjectname1")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	src2, err := minio.NewSource("my-sourcebucketname", "my-sourceobjectname2")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	// Only copy the first 1MiB of the second source.
+	if err = src2.SetRange(0, 1024*1024-1); err != nil {
+		log.Fatalln(err)
+	}
+
+	// Prepare the destination.
+	dst, err := minio.NewDestination("my-bucketname", "my-objectname", "")
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// Compose the sources into the destination object.
+	err = s3Client.ComposeObject(dst, []minio.Source{src1, src2})
+	if err != nil {
+		log.Fatalln(err)
+	}
+	log.Println("Success")
+}
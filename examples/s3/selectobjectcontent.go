@@ -0,0 +1,61 @@
+//go:build ignore
+// +build ignore
+
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+
+	"github.com/minio/minio-go"
+)
+
+func main() {
+	// Note: YOUR-ACCESSKEYID, YOUR-SECRETACCESSKEY, my-bucketname and my-objectname
+	// are dummy values, please replace them with original values.
+
+	// Requests are always secure (HTTPS) by default. Set insecure=true to enable insecure (HTTP) access.
+	// This boolean value is the last argument for New().
+
+	// New returns an Amazon S3 compatible client object. API copatibality (v2 or v4) is automatically
+	// determined based on the Endpoint value.
+	s3Client, err := minio.New("s3.amazonaws.com", "YOUR-ACCESSKEYID", "YOUR-SECRETACCESSKEY", false)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	opts := minio.SelectOptions{
+		Expression: "select s._1 from S3Object s",
+		InputSerialization: minio.SelectInputSerialization{
+			CSV: &minio.CSVInputOptions{FileHeaderInfo: "NONE"},
+		},
+		OutputSerialization: minio.SelectOutputSerialization{
+			CSV: &minio.CSVOutputOptions{},
+		},
+	}
+	results, err := s3Client.SelectObjectContent("my-bucketname", "my-objectname.csv", opts)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer results.Close()
+	if _, err = io.Copy(os.Stdout, results); err != nil {
+		log.Fatalln(err)
+	}
+	log.Println(results.Stats())
+}
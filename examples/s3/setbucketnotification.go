@@ -0,0 +1,56 @@
+// +build ignore
+
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"log"
+
+	"github.com/minio/minio-go"
+)
+
+func main() {
+	// Note: YOUR-ACCESSKEYID, YOUR-SECRETACCESSKEY and my-bucketname are
+	// dummy values, please replace them with original values.
+
+	// Requests are always secure (HTTPS) by default. Set insecure=true to enable insecure (HTTP) access.
+	// This boolean value is the last argument for New().
+
+	// New returns an Amazon S3 compatible client object. API copatibality (v2 or v4) is automatically
+	// determined based on the Endpoint value.
+	s3Client, err := minio.New("s3.amazonaws.com", "YOUR-ACCESSKEYID", "YOUR-SECRETACCESSKEY", false)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	// ARN represents a notification channel that needs to be created in your S3 provider
+	// (e.g. http://docs.aws.amazon.com/sns/latest/dg/CreateTopic.html)
+	queueConfig := minio.QueueConfig{Queue: "arn:aws:sqs:us-east-1:444455556666:s3notificationqueue"}
+	queueConfig.AddEvents(minio.ObjectCreatedAll, minio.ObjectRemovedAll)
+	queueConfig.AddFilterPrefix("photos/")
+	queueConfig.AddFilterSuffix(".jpg")
+
+	bucketNotification := minio.BucketNotification{}
+	bucketNotification.QueueConfigs = append(bucketNotification.QueueConfigs, queueConfig)
+
+	err = s3Client.SetBucketNotification("my-bucketname", bucketNotification)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	log.Println("Success")
+}
@@ -0,0 +1,191 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RequestMetrics - container for the metrics of a request, including
+// all its retries.
+type RequestMetrics struct {
+	// Name of the S3 operation, e.g. 'PutObject', 'GetObject' or
+	// 'ListObjects'. Parts of multipart uploads are 'PutObjectPart'.
+	Operation  string
+	BucketName string
+	ObjectName string
+
+	// Time from sending the first attempt until the response headers
+	// of the last one, reading the response body is not included.
+	Duration time.Duration
+
+	// Bytes of the request body, and of the response body as sent in
+	// its Content-Length, '-1' if unknown.
+	BytesSent     int64
+	BytesReceived int64
+
+	// Number of retries after the first attempt.
+	Retries int
+
+	// Status of the last response, '0' if it failed without one.
+	StatusCode int
+	// Error of requests failed without a response.
+	Err error
+}
+
+// MetricsCollector - collects the metrics of all the requests of a
+// client, e.g. to export them to Prometheus or statsd.
+//
+// ObserveRequest is called once per request after its response headers
+// are received, concurrently by concurrent requests. It must not block.
+type MetricsCollector interface {
+	ObserveRequest(metrics RequestMetrics)
+}
+
+// observeRequest - reports the metrics of a request to collector.
+func observeRequest(collector MetricsCollector, method string, metadata requestMetadata, start time.Time, attempts int, res *http.Response, err error) {
+	metrics := RequestMetrics{
+		Operation:     requestOperation(method, metadata),
+		BucketName:    metadata.bucketName,
+		ObjectName:    metadata.objectName,
+		Duration:      time.Since(start),
+		BytesSent:     metadata.contentLength,
+		BytesReceived: -1,
+		Err:           err,
+	}
+	if metadata.contentBody == nil {
+		metrics.BytesSent = 0
+	}
+	if attempts > 1 {
+		metrics.Retries = attempts - 1
+	}
+	if res != nil {
+		metrics.StatusCode = res.StatusCode
+		metrics.BytesReceived = res.ContentLength
+	}
+	collector.ObserveRequest(metrics)
+}
+
+// Names of the configurations of buckets and objects set by their
+// sub-resource queries.
+var subresourceNames = map[string]string{
+	"acl":          "ACL",
+	"attributes":   "Attributes",
+	"cors":         "CORS",
+	"legal-hold":   "LegalHold",
+	"lifecycle":    "Lifecycle",
+	"location":     "Location",
+	"notification": "Notification",
+	"object-lock":  "ObjectLock",
+	"policy":       "Policy",
+	"retention":    "Retention",
+	"tagging":      "Tagging",
+	"versioning":   "Versioning",
+}
+
+// requestOperation - returns the name of the S3 operation of a request
+// by its method and query.
+func requestOperation(method string, metadata requestMetadata) string {
+	query := metadata.queryValues
+	_, isCopy := metadata.customHeader["X-Amz-Copy-Source"]
+	if hasQuery(query, "heal") {
+		return "Heal"
+	}
+
+	// Configurations are get, set and removed.
+	for subresource, name := range subresourceNames {
+		if !hasQuery(query, subresource) {
+			continue
+		}
+		if metadata.objectName != "" {
+			name = "Object" + name
+		} else {
+			name = "Bucket" + name
+		}
+		switch method {
+		case "PUT":
+			return "Set" + name
+		case "DELETE":
+			return "Remove" + name
+		}
+		return "Get" + name
+	}
+
+	if metadata.objectName == "" {
+		switch {
+		case metadata.bucketName == "":
+			return "ListBuckets"
+		case method == "HEAD":
+			return "BucketExists"
+		case method == "PUT":
+			return "MakeBucket"
+		case method == "DELETE":
+			return "RemoveBucket"
+		case method == "POST" && hasQuery(query, "delete"):
+			return "RemoveObjects"
+		case hasQuery(query, "uploads"):
+			return "ListIncompleteUploads"
+		case hasQuery(query, "versions"):
+			return "ListObjectVersions"
+		case hasQuery(query, "events"):
+			return "ListenBucketNotification"
+		}
+		return "ListObjects"
+	}
+
+	switch {
+	case hasQuery(query, "uploads"):
+		return "NewMultipartUpload"
+	case query.Get("uploadId") != "":
+		switch method {
+		case "PUT":
+			if isCopy {
+				return "CopyObjectPart"
+			}
+			return "PutObjectPart"
+		case "POST":
+			return "CompleteMultipartUpload"
+		case "DELETE":
+			return "AbortMultipartUpload"
+		}
+		return "ListObjectParts"
+	case hasQuery(query, "select"):
+		return "SelectObjectContent"
+	}
+	switch method {
+	case "HEAD":
+		return "StatObject"
+	case "PUT":
+		if isCopy {
+			return "CopyObject"
+		}
+		return "PutObject"
+	case "DELETE":
+		return "RemoveObject"
+	case "POST":
+		return "PostObject"
+	}
+	return "GetObject"
+}
+
+// hasQuery - verify if query has key, with or without a value.
+func hasQuery(query url.Values, key string) bool {
+	_, ok := query[key]
+	return ok
+}
@@ -0,0 +1,106 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Attributes of objects returned by GetObjectAttributes.
+const (
+	ObjectAttributeETag         = "ETag"
+	ObjectAttributeChecksum     = "Checksum"
+	ObjectAttributeObjectParts  = "ObjectParts"
+	ObjectAttributeStorageClass = "StorageClass"
+	ObjectAttributeObjectSize   = "ObjectSize"
+)
+
+// isValidObjectAttribute - Is provided attribute supported.
+func isValidObjectAttribute(attr string) bool {
+	switch attr {
+	case ObjectAttributeETag, ObjectAttributeChecksum, ObjectAttributeObjectParts,
+		ObjectAttributeStorageClass, ObjectAttributeObjectSize:
+		return true
+	}
+	return false
+}
+
+// ObjectChecksum - container for the base64 encoded checksums of an
+// object or of its parts, only the algorithm the object was uploaded
+// with is set.
+type ObjectChecksum struct {
+	CRC32  string `xml:"ChecksumCRC32" json:"crc32,omitempty"`
+	CRC32C string `xml:"ChecksumCRC32C" json:"crc32c,omitempty"`
+	SHA1   string `xml:"ChecksumSHA1" json:"sha1,omitempty"`
+	SHA256 string `xml:"ChecksumSHA256" json:"sha256,omitempty"`
+}
+
+// ObjectAttributePart - container for a part of a multipart object.
+type ObjectAttributePart struct {
+	PartNumber int   `json:"partNumber"`
+	Size       int64 `json:"size"`
+
+	// Checksum of the part, empty unless uploaded with one.
+	Checksum ObjectChecksum `json:"checksum"`
+}
+
+// ObjectAttributes - container for the attributes of an object, only
+// the requested attributes are set.
+type ObjectAttributes struct {
+	// ETag of the object, without quotes.
+	ETag string `json:"etag,omitempty"`
+
+	Checksum ObjectChecksum `json:"checksum"`
+
+	// Number of parts and the parts of multipart objects, '0' and
+	// empty for objects uploaded with a single PUT. Parts are only
+	// listed for objects uploaded with checksums.
+	PartsCount int                   `json:"partsCount,omitempty"`
+	Parts      []ObjectAttributePart `json:"parts,omitempty"`
+
+	StorageClass string `json:"storageClass,omitempty"`
+	ObjectSize   int64  `json:"objectSize,omitempty"`
+
+	// Always set.
+	LastModified time.Time `json:"lastModified"`
+	VersionID    string    `json:"versionId,omitempty"`
+}
+
+// objectAttributesPart container for a part in the object attributes
+// response.
+type objectAttributesPart struct {
+	ObjectChecksum
+	PartNumber int
+	Size       int64
+}
+
+// getObjectAttributesResponse container for get object attributes
+// response.
+type getObjectAttributesResponse struct {
+	XMLName      xml.Name `xml:"GetObjectAttributesResponse" json:"-"`
+	ETag         string
+	Checksum     ObjectChecksum
+	StorageClass string
+	ObjectSize   int64
+	ObjectParts  struct {
+		PartsCount           int
+		IsTruncated          bool
+		NextPartNumberMarker int
+		Parts                []objectAttributesPart `xml:"Part"`
+	}
+}
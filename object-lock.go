@@ -0,0 +1,127 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// RetentionMode - Object lock retention mode.
+type RetentionMode string
+
+// Different retention modes of an object.
+const (
+	// Governance mode, users with special permissions can still
+	// remove the object or shorten its retention.
+	RetentionGovernance = RetentionMode("GOVERNANCE")
+	// Compliance mode, nobody can remove the object or shorten its
+	// retention until it expires.
+	RetentionCompliance = RetentionMode("COMPLIANCE")
+)
+
+// isValidRetentionMode - Is provided mode supported.
+func (r RetentionMode) isValidRetentionMode() bool {
+	return r == RetentionGovernance || r == RetentionCompliance
+}
+
+// LegalHoldStatus - Object lock legal hold status.
+type LegalHoldStatus string
+
+// Different legal hold states of an object.
+const (
+	LegalHoldOn  = LegalHoldStatus("ON")
+	LegalHoldOff = LegalHoldStatus("OFF")
+)
+
+// isValidLegalHoldStatus - Is provided status supported.
+func (l LegalHoldStatus) isValidLegalHoldStatus() bool {
+	return l == LegalHoldOn || l == LegalHoldOff
+}
+
+// objectRetention container for object retention request and response.
+type objectRetention struct {
+	XMLName         xml.Name      `xml:"Retention" json:"-"`
+	Mode            RetentionMode `xml:"Mode,omitempty"`
+	RetainUntilDate *time.Time    `xml:"RetainUntilDate,omitempty"`
+}
+
+// ObjectLockConfiguration - container for the object lock configuration
+// of a bucket. New objects without retention of their own are retained
+// by the default retention of Rule, if set.
+type ObjectLockConfiguration struct {
+	XMLName xml.Name `xml:"ObjectLockConfiguration" json:"-"`
+	// Always 'Enabled', object lock cannot be disabled.
+	ObjectLockEnabled string          `xml:"ObjectLockEnabled"`
+	Rule              *ObjectLockRule `xml:"Rule,omitempty"`
+}
+
+// ObjectLockRule - container for the default retention of a bucket.
+type ObjectLockRule struct {
+	DefaultRetention DefaultRetention `xml:"DefaultRetention"`
+}
+
+// DefaultRetention - retention mode and period of new objects, the
+// period is set either in Days or in Years.
+type DefaultRetention struct {
+	Mode  RetentionMode `xml:"Mode"`
+	Days  int           `xml:"Days,omitempty"`
+	Years int           `xml:"Years,omitempty"`
+}
+
+// isValidObjectLockConfiguration - verify object lock configuration in
+// accordance with
+//  - https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObjectLockConfiguration.html
+func isValidObjectLockConfiguration(config ObjectLockConfiguration) error {
+	if config.ObjectLockEnabled != "" && config.ObjectLockEnabled != "Enabled" {
+		return ErrInvalidArgument("Object lock enabled can only be 'Enabled'.")
+	}
+	if config.Rule == nil {
+		return nil
+	}
+	retention := config.Rule.DefaultRetention
+	if !retention.Mode.isValidRetentionMode() {
+		return ErrInvalidArgument("Unrecognized retention mode " + string(retention.Mode) + ".")
+	}
+	if retention.Days < 0 || retention.Years < 0 {
+		return ErrInvalidArgument("Default retention period cannot be negative.")
+	}
+	if (retention.Days == 0) == (retention.Years == 0) {
+		return ErrInvalidArgument("Default retention should set either days or years.")
+	}
+	return nil
+}
+
+// objectLegalHold container for object legal hold request and response.
+type objectLegalHold struct {
+	XMLName xml.Name        `xml:"LegalHold" json:"-"`
+	Status  LegalHoldStatus `xml:"Status,omitempty"`
+}
+
+// objectLockError - object lock requests on buckets without versioning
+// are rejected with 'InvalidRequest', report missing versioning clearly
+// in that case.
+func (c Client) objectLockError(bucketName string, err error) error {
+	if ToErrorResponse(err).Code != "InvalidRequest" {
+		return err
+	}
+	status, verr := c.GetBucketVersioning(bucketName)
+	if verr == nil && status != VersioningEnabled {
+		return ErrInvalidArgument("Object lock requires versioning to be enabled on bucket " + bucketName + ".")
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Retrieval tiers of archived objects, faster tiers cost more.
+const (
+	// Restored within minutes.
+	RestoreTierExpedited = "Expedited"
+	// Restored within hours, the default.
+	RestoreTierStandard = "Standard"
+	// Restored within half a day, for large amounts of data.
+	RestoreTierBulk = "Bulk"
+)
+
+// isValidRestoreTier - Is provided tier supported.
+func isValidRestoreTier(tier string) bool {
+	return tier == RestoreTierExpedited || tier == RestoreTierStandard || tier == RestoreTierBulk
+}
+
+// restoreRequest container for restore object request.
+type restoreRequest struct {
+	XMLName              xml.Name `xml:"RestoreRequest" json:"-"`
+	Days                 int      `xml:"Days"`
+	GlacierJobParameters struct {
+		Tier string `xml:"Tier"`
+	} `xml:"GlacierJobParameters"`
+}
+
+// RestoreInfo - container for the restore status of an archived object.
+type RestoreInfo struct {
+	// Set while the object is being restored.
+	OngoingRequest bool `json:"ongoingRequest"`
+	// Time the restored copy is removed again, only set once restored.
+	ExpiryDate time.Time `json:"expiryDate,omitempty"`
+}
+
+// parseRestoreInfo - parses the 'x-amz-restore' header, e.g.
+// 'ongoing-request="false", expiry-date="Fri, 23 Dec 2016 00:00:00 GMT"',
+// returns nil if the header is not set.
+func parseRestoreInfo(header string) *RestoreInfo {
+	if header == "" {
+		return nil
+	}
+	info := &RestoreInfo{}
+	for header != "" {
+		// Values are quoted and may contain commas.
+		var key, value string
+		i := strings.Index(header, "=\"")
+		if i < 0 {
+			break
+		}
+		key = strings.TrimSpace(strings.TrimLeft(header[:i], ", "))
+		header = header[i+2:]
+		j := strings.Index(header, "\"")
+		if j < 0 {
+			break
+		}
+		value, header = header[:j], header[j+1:]
+		switch key {
+		case "ongoing-request":
+			info.OngoingRequest = value == "true"
+		case "expiry-date":
+			if expiry, err := time.Parse(http.TimeFormat, value); err == nil {
+				info.ExpiryDate = expiry
+			}
+		}
+	}
+	return info
+}
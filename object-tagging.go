@@ -0,0 +1,120 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+/// Object tagging limits.
+
+// maxTagCount - maximum number of tags allowed per object.
+const maxTagCount = 10
+
+// maxTagKeyLength - maximum length of a tag key in unicode characters.
+const maxTagKeyLength = 128
+
+// maxTagValueLength - maximum length of a tag value in unicode
+// characters.
+const maxTagValueLength = 256
+
+// tag container for a single key value pair.
+type tag struct {
+	Key   string
+	Value string
+}
+
+// tagging container for object tagging request and response.
+type tagging struct {
+	XMLName xml.Name `xml:"Tagging" json:"-"`
+	TagSet  struct {
+		Tag []tag
+	}
+}
+
+// isValidObjectTags - verify tags in accordance with
+//  - http://docs.aws.amazon.com/AmazonS3/latest/dev/object-tagging.html
+func isValidObjectTags(tags map[string]string) error {
+	if len(tags) > maxTagCount {
+		return ErrInvalidArgument(fmt.Sprintf("Object cannot have more than %d tags.", maxTagCount))
+	}
+	for key, value := range tags {
+		if strings.TrimSpace(key) == "" {
+			return ErrInvalidArgument("Tag key cannot be empty.")
+		}
+		if !utf8.ValidString(key) || !utf8.ValidString(value) {
+			return ErrInvalidArgument("Tag with non UTF-8 strings are not supported.")
+		}
+		if utf8.RuneCountInString(key) > maxTagKeyLength {
+			return ErrInvalidArgument(fmt.Sprintf("Tag key cannot be greater than %d characters.", maxTagKeyLength))
+		}
+		if utf8.RuneCountInString(value) > maxTagValueLength {
+			return ErrInvalidArgument(fmt.Sprintf("Tag value cannot be greater than %d characters.", maxTagValueLength))
+		}
+	}
+	return nil
+}
+
+// newTagging - converts tags map into tagging container, tags are
+// sorted by key to keep the request body deterministic.
+func newTagging(tags map[string]string) tagging {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	t := tagging{}
+	for _, key := range keys {
+		t.TagSet.Tag = append(t.TagSet.Tag, tag{Key: key, Value: tags[key]})
+	}
+	return t
+}
+
+// encodeTags - encodes tags as URL query parameters for the
+// 'x-amz-tagging' header, sorted by key.
+func encodeTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	encoded := make([]string, 0, len(keys))
+	for _, key := range keys {
+		encoded = append(encoded, queryEscape(key)+"="+queryEscape(tags[key]))
+	}
+	return strings.Join(encoded, "&")
+}
+
+// queryEscape - escapes s for a URL query, spaces as '%20' instead of
+// '+' which S3 does not decode.
+func queryEscape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+// toMap - converts tagging container into tags map.
+func (t tagging) toMap() map[string]string {
+	tags := make(map[string]string)
+	for _, tg := range t.TagSet.Tag {
+		tags[tg.Key] = tg.Value
+	}
+	return tags
+}
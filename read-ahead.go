@@ -0,0 +1,189 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// readAheadChunk - a range of an object fetched in the background.
+type readAheadChunk struct {
+	data []byte
+	err  error
+}
+
+// readAheadReader - reads an object sequentially in ranges of
+// chunkSize, the next range is fetched in the background while the
+// current one is read.
+type readAheadReader struct {
+	bucketName string
+	objectName string
+
+	// Returns the range of the object starting at offset.
+	getRange func(offset, length int64) (io.ReadCloser, error)
+
+	// Size of the object and of its ranges.
+	size      int64
+	chunkSize int64
+
+	// Range being read, its length and the number of its bytes not
+	// read yet.
+	current       io.ReadCloser
+	currentLength int64
+	currentLeft   int64
+
+	// Range fetched in the background, nil once the object is
+	// fetched to the end.
+	nextCh     chan readAheadChunk
+	nextOffset int64
+
+	// mutex is used for handling the body of the range fetched in
+	// the background, closed by Close.
+	mutex    sync.Mutex
+	nextBody io.ReadCloser
+	isClosed bool
+
+	// Previous error saved for future calls.
+	err error
+}
+
+// newReadAheadReader - reads the object from the range current of
+// currentLength bytes to the end, the range starting at nextOffset is
+// fetched right away.
+func newReadAheadReader(bucketName, objectName string, current io.ReadCloser, currentLength, nextOffset, size, chunkSize int64, getRange func(offset, length int64) (io.ReadCloser, error)) *readAheadReader {
+	r := &readAheadReader{
+		bucketName:    bucketName,
+		objectName:    objectName,
+		getRange:      getRange,
+		size:          size,
+		chunkSize:     chunkSize,
+		current:       current,
+		currentLength: currentLength,
+		currentLeft:   currentLength,
+		nextOffset:    nextOffset,
+	}
+	r.prefetch()
+	return r
+}
+
+// prefetch - fetches the next range in the background.
+func (r *readAheadReader) prefetch() {
+	r.nextCh = nil
+	if r.nextOffset >= r.size {
+		return
+	}
+	offset, length := r.nextOffset, r.chunkSize
+	if offset+length > r.size {
+		length = r.size - offset
+	}
+	r.nextOffset += length
+
+	// Buffered, the routine never blocks once closed.
+	nextCh := make(chan readAheadChunk, 1)
+	r.nextCh = nextCh
+	go func() {
+		body, err := r.getRange(offset, length)
+		if err != nil {
+			nextCh <- readAheadChunk{err: err}
+			return
+		}
+		r.mutex.Lock()
+		if r.isClosed {
+			r.mutex.Unlock()
+			body.Close()
+			return
+		}
+		r.nextBody = body
+		r.mutex.Unlock()
+
+		data := make([]byte, length)
+		n, err := io.ReadFull(body, data)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = ErrUnexpectedEOF(int64(n), length, r.bucketName, r.objectName)
+		}
+
+		r.mutex.Lock()
+		r.nextBody = nil
+		r.mutex.Unlock()
+		body.Close()
+		nextCh <- readAheadChunk{data: data, err: err}
+	}()
+}
+
+// Read - reads the current range, then waits for the next one.
+func (r *readAheadReader) Read(p []byte) (n int, err error) {
+	for r.current == nil {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.nextCh == nil {
+			return 0, io.EOF
+		}
+		chunk := <-r.nextCh
+		if chunk.err != nil {
+			r.err = chunk.err
+			return 0, r.err
+		}
+		r.current = ioutil.NopCloser(bytes.NewReader(chunk.data))
+		r.currentLength = int64(len(chunk.data))
+		r.currentLeft = r.currentLength
+		r.prefetch()
+	}
+
+	n, err = r.current.Read(p)
+	r.currentLeft -= int64(n)
+	if err == io.EOF {
+		// Ranges cut short would silently lose data.
+		if r.currentLeft > 0 {
+			r.err = ErrUnexpectedEOF(r.currentLength-r.currentLeft, r.currentLength, r.bucketName, r.objectName)
+			return n, r.err
+		}
+		r.current.Close()
+		r.current = nil
+		return n, nil
+	}
+	if err != nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// Close - closes the current range and stops fetching the next one.
+func (r *readAheadReader) Close() error {
+	r.mutex.Lock()
+	r.isClosed = true
+	if r.nextBody != nil {
+		r.nextBody.Close()
+	}
+	r.mutex.Unlock()
+	if r.current != nil {
+		return r.current.Close()
+	}
+	return nil
+}
+
+// closeStream - closes the stream of an object. Read-ahead streams are
+// closed right away, others are drained first to reuse the connection.
+func closeStream(reader io.ReadCloser) error {
+	if r, ok := reader.(*readAheadReader); ok {
+		return r.Close()
+	}
+	return drainAndClose(reader)
+}
@@ -60,7 +60,10 @@ func encodeURL2Path(u *url.URL) (path string) {
 
 // preSignV2 - presign the request in following style.
 // https://${S3_BUCKET}.s3.amazonaws.com/${S3_OBJECT}?AWSAccessKeyId=${S3_ACCESS_KEY}&Expires=${TIMESTAMP}&Signature=${SIGNATURE}.
-func preSignV2(req http.Request, accessKeyID, secretAccessKey string, expires int64) *http.Request {
+//
+// Temporary credentials add their session token as
+// x-amz-security-token to the query.
+func preSignV2(req http.Request, accessKeyID, secretAccessKey, sessionToken string, expires int64) *http.Request {
 	// Presign is not needed for anonymous credentials.
 	if accessKeyID == "" || secretAccessKey == "" {
 		return &req
@@ -82,10 +85,19 @@ func preSignV2(req http.Request, accessKeyID, secretAccessKey string, expires in
 	// Find epoch expires when the request will expire.
 	epochExpires := d.Unix() + expires
 
-	// Get string to sign.
-	stringToSign := fmt.Sprintf("%s\n\n\n%d\n%s", req.Method, epochExpires, path)
+	// Session token is signed as a canonicalized amz header.
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	// Get string to sign, headers set on the request are signed and
+	// have to be sent with the same values by the user of the URL.
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "%s\n%s\n%s\n%d\n", req.Method, req.Header.Get("Content-MD5"), req.Header.Get("Content-Type"), epochExpires)
+	writeCanonicalizedHeaders(buf, req)
+	buf.WriteString(path)
 	hm := hmac.New(sha1.New, []byte(secretAccessKey))
-	hm.Write([]byte(stringToSign))
+	hm.Write(buf.Bytes())
 
 	// Calculate signature.
 	signature := base64.StdEncoding.EncodeToString(hm.Sum(nil))
@@ -101,6 +113,11 @@ func preSignV2(req http.Request, accessKeyID, secretAccessKey string, expires in
 	// Fill in Expires for presigned query.
 	query.Set("Expires", strconv.FormatInt(epochExpires, 10))
 
+	// Set session token if available.
+	if sessionToken != "" {
+		query.Set("x-amz-security-token", sessionToken)
+	}
+
 	// Encode query and save.
 	req.URL.RawQuery = queryEncode(query)
 
@@ -137,7 +154,10 @@ func postPresignSignatureV2(policyBase64, secretAccessKey string) string {
 // CanonicalizedProtocolHeaders = <described below>
 
 // signV2 sign the request before Do() (AWS Signature Version 2).
-func signV2(req http.Request, accessKeyID, secretAccessKey string) *http.Request {
+//
+// Temporary credentials add their session token as the header
+// x-amz-security-token.
+func signV2(req http.Request, accessKeyID, secretAccessKey, sessionToken string) *http.Request {
 	// Signature calculation is not needed for anonymous credentials.
 	if accessKeyID == "" || secretAccessKey == "" {
 		return &req
@@ -151,6 +171,11 @@ func signV2(req http.Request, accessKeyID, secretAccessKey string) *http.Request
 		req.Header.Set("Date", d.Format(http.TimeFormat))
 	}
 
+	// Set session token if available.
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
 	// Calculate HMAC for secretAccessKey.
 	stringToSign := getStringToSignV2(req)
 	hm := hmac.New(sha1.New, []byte(secretAccessKey))
@@ -238,18 +263,29 @@ func writeCanonicalizedHeaders(buf *bytes.Buffer, req http.Request) {
 // Must be sorted:
 var resourceList = []string{
 	"acl",
+	"attributes",
+	"cors",
+	"delete",
+	"legal-hold",
+	"lifecycle",
 	"location",
 	"logging",
 	"notification",
+	"object-lock",
 	"partNumber",
 	"policy",
-	"response-content-type",
-	"response-content-language",
-	"response-expires",
+	"requestPayment",
 	"response-cache-control",
 	"response-content-disposition",
 	"response-content-encoding",
-	"requestPayment",
+	"response-content-language",
+	"response-content-type",
+	"response-expires",
+	"restore",
+	"retention",
+	"select",
+	"select-type",
+	"tagging",
 	"torrent",
 	"uploadId",
 	"uploads",
@@ -271,7 +307,6 @@ func writeCanonicalizedResource(buf *bytes.Buffer, req http.Request) {
 	path := encodeURL2Path(requestURL)
 	buf.WriteString(path)
 
-	sort.Strings(resourceList)
 	if requestURL.RawQuery != "" {
 		var n int
 		vals, _ := url.ParseQuery(requestURL.RawQuery)
@@ -289,10 +324,12 @@ func writeCanonicalizedResource(buf *bytes.Buffer, req http.Request) {
 					buf.WriteByte('&')
 				}
 				buf.WriteString(resource)
-				// Request parameters
+				// Request parameters are signed as is, without URL
+				// encoding, e.g. response-content-disposition values
+				// with spaces and quotes.
 				if len(vv[0]) > 0 {
 					buf.WriteByte('=')
-					buf.WriteString(strings.Replace(url.QueryEscape(vv[0]), "+", "%20", -1))
+					buf.WriteString(vv[0])
 				}
 			}
 		}
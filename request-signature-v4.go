@@ -31,6 +31,13 @@ const (
 	signV4Algorithm   = "AWS4-HMAC-SHA256"
 	iso8601DateFormat = "20060102T150405Z"
 	yyyymmdd          = "20060102"
+	unsignedPayload   = "UNSIGNED-PAYLOAD"
+)
+
+// Services signed with signature version '4'.
+const (
+	serviceTypeS3  = "s3"
+	serviceTypeSTS = "sts"
 )
 
 ///
@@ -68,18 +75,34 @@ const (
 ///
 ///      Is skipped for obvious reasons
 ///
+///  Accept-Encoding:
+///
+///      Proxies may modify or strip this header, it has no effect on
+///      the object data returned by the server.
+///
 var ignoredHeaders = map[string]bool{
-	"Authorization":  true,
-	"Content-Type":   true,
-	"Content-Length": true,
-	"User-Agent":     true,
+	"Accept-Encoding": true,
+	"Authorization":   true,
+	"Content-Type":    true,
+	"Content-Length":  true,
+	"User-Agent":      true,
+}
+
+// presignIgnoredHeaders - headers ignored while presigning. Headers
+// of a presigned request are declared by the caller to be sent at use
+// time, so Content-Type is signed if declared.
+var presignIgnoredHeaders = map[string]bool{
+	"Accept-Encoding": true,
+	"Authorization":   true,
+	"Content-Length":  true,
+	"User-Agent":      true,
 }
 
 // getSigningKey hmac seed to calculate final signature.
-func getSigningKey(secret, loc string, t time.Time) []byte {
+func getSigningKey(secret, loc string, t time.Time, serviceType string) []byte {
 	date := sumHMAC([]byte("AWS4"+secret), []byte(t.Format(yyyymmdd)))
 	location := sumHMAC(date, []byte(loc))
-	service := sumHMAC(location, []byte("s3"))
+	service := sumHMAC(location, []byte(serviceType))
 	signingKey := sumHMAC(service, []byte("aws4_request"))
 	return signingKey
 }
@@ -91,19 +114,19 @@ func getSignature(signingKey []byte, stringToSign string) string {
 
 // getScope generate a string of a specific date, an AWS region, and a
 // service.
-func getScope(location string, t time.Time) string {
+func getScope(location string, t time.Time, serviceType string) string {
 	scope := strings.Join([]string{
 		t.Format(yyyymmdd),
 		location,
-		"s3",
+		serviceType,
 		"aws4_request",
 	}, "/")
 	return scope
 }
 
 // getCredential generate a credential string.
-func getCredential(accessKeyID, location string, t time.Time) string {
-	scope := getScope(location, t)
+func getCredential(accessKeyID, location string, t time.Time, serviceType string) string {
+	scope := getScope(location, t, serviceType)
 	return accessKeyID + "/" + scope
 }
 
@@ -113,14 +136,14 @@ func getHashedPayload(req http.Request) string {
 	hashedPayload := req.Header.Get("X-Amz-Content-Sha256")
 	if hashedPayload == "" {
 		// Presign does not have a payload, use S3 recommended value.
-		hashedPayload = "UNSIGNED-PAYLOAD"
+		hashedPayload = unsignedPayload
 	}
 	return hashedPayload
 }
 
 // getCanonicalHeaders generate a list of request headers for
 // signature.
-func getCanonicalHeaders(req http.Request) string {
+func getCanonicalHeaders(req http.Request, ignoredHeaders map[string]bool) string {
 	var headers []string
 	vals := make(map[string][]string)
 	for k, vv := range req.Header {
@@ -141,7 +164,12 @@ func getCanonicalHeaders(req http.Request) string {
 		buf.WriteByte(':')
 		switch {
 		case k == "host":
-			buf.WriteString(req.URL.Host)
+			// Host header overrides the host of the URL.
+			if req.Host != "" {
+				buf.WriteString(req.Host)
+			} else {
+				buf.WriteString(req.URL.Host)
+			}
 			fallthrough
 		default:
 			for idx, v := range vals[k] {
@@ -159,7 +187,7 @@ func getCanonicalHeaders(req http.Request) string {
 // getSignedHeaders generate all signed request headers.
 // i.e lexically sorted, semicolon-separated list of lowercase
 // request header names.
-func getSignedHeaders(req http.Request) string {
+func getSignedHeaders(req http.Request, ignoredHeaders map[string]bool) string {
 	var headers []string
 	for k := range req.Header {
 		if _, ok := ignoredHeaders[http.CanonicalHeaderKey(k)]; ok {
@@ -181,30 +209,36 @@ func getSignedHeaders(req http.Request) string {
 //  <CanonicalHeaders>\n
 //  <SignedHeaders>\n
 //  <HashedPayload>
-func getCanonicalRequest(req http.Request) string {
+func getCanonicalRequest(req http.Request, ignoredHeaders map[string]bool) string {
 	req.URL.RawQuery = strings.Replace(req.URL.Query().Encode(), "+", "%20", -1)
 	canonicalRequest := strings.Join([]string{
 		req.Method,
 		urlEncodePath(req.URL.Path),
 		req.URL.RawQuery,
-		getCanonicalHeaders(req),
-		getSignedHeaders(req),
+		getCanonicalHeaders(req, ignoredHeaders),
+		getSignedHeaders(req, ignoredHeaders),
 		getHashedPayload(req),
 	}, "\n")
 	return canonicalRequest
 }
 
 // getStringToSign a string based on selected query values.
-func getStringToSignV4(t time.Time, location, canonicalRequest string) string {
+func getStringToSignV4(t time.Time, location, canonicalRequest, serviceType string) string {
 	stringToSign := signV4Algorithm + "\n" + t.Format(iso8601DateFormat) + "\n"
-	stringToSign = stringToSign + getScope(location, t) + "\n"
+	stringToSign = stringToSign + getScope(location, t, serviceType) + "\n"
 	stringToSign = stringToSign + hex.EncodeToString(sum256([]byte(canonicalRequest)))
 	return stringToSign
 }
 
 // preSignV4 presign the request, in accordance with
 // http://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-query-string-auth.html.
-func preSignV4(req http.Request, accessKeyID, secretAccessKey, location string, expires int64) *http.Request {
+//
+// Temporary credentials add their session token as
+// X-Amz-Security-Token to the query.
+//
+// Headers set on the request, including Content-Type, are signed and
+// have to be sent with the same values by the user of the URL.
+func preSignV4(req http.Request, accessKeyID, secretAccessKey, sessionToken, location string, expires int64) *http.Request {
 	// Presign is not needed for anonymous credentials.
 	if accessKeyID == "" || secretAccessKey == "" {
 		return &req
@@ -214,10 +248,10 @@ func preSignV4(req http.Request, accessKeyID, secretAccessKey, location string,
 	t := time.Now().UTC()
 
 	// Get credential string.
-	credential := getCredential(accessKeyID, location, t)
+	credential := getCredential(accessKeyID, location, t, serviceTypeS3)
 
 	// Get all signed headers.
-	signedHeaders := getSignedHeaders(req)
+	signedHeaders := getSignedHeaders(req, presignIgnoredHeaders)
 
 	// Set URL query.
 	query := req.URL.Query()
@@ -226,16 +260,20 @@ func preSignV4(req http.Request, accessKeyID, secretAccessKey, location string,
 	query.Set("X-Amz-Expires", strconv.FormatInt(expires, 10))
 	query.Set("X-Amz-SignedHeaders", signedHeaders)
 	query.Set("X-Amz-Credential", credential)
+	// Set session token if available.
+	if sessionToken != "" {
+		query.Set("X-Amz-Security-Token", sessionToken)
+	}
 	req.URL.RawQuery = query.Encode()
 
 	// Get canonical request.
-	canonicalRequest := getCanonicalRequest(req)
+	canonicalRequest := getCanonicalRequest(req, presignIgnoredHeaders)
 
 	// Get string to sign from canonical request.
-	stringToSign := getStringToSignV4(t, location, canonicalRequest)
+	stringToSign := getStringToSignV4(t, location, canonicalRequest, serviceTypeS3)
 
 	// Gext hmac signing key.
-	signingKey := getSigningKey(secretAccessKey, location, t)
+	signingKey := getSigningKey(secretAccessKey, location, t, serviceTypeS3)
 
 	// Calculate signature.
 	signature := getSignature(signingKey, stringToSign)
@@ -250,7 +288,7 @@ func preSignV4(req http.Request, accessKeyID, secretAccessKey, location string,
 // requests.
 func postPresignSignatureV4(policyBase64 string, t time.Time, secretAccessKey, location string) string {
 	// Get signining key.
-	signingkey := getSigningKey(secretAccessKey, location, t)
+	signingkey := getSigningKey(secretAccessKey, location, t, serviceTypeS3)
 	// Calculate signature.
 	signature := getSignature(signingkey, policyBase64)
 	return signature
@@ -258,7 +296,21 @@ func postPresignSignatureV4(policyBase64 string, t time.Time, secretAccessKey, l
 
 // signV4 sign the request before Do(), in accordance with
 // http://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-authenticating-requests.html.
-func signV4(req http.Request, accessKeyID, secretAccessKey, location string) *http.Request {
+//
+// Temporary credentials add their session token as the signed header
+// x-amz-security-token.
+func signV4(req http.Request, accessKeyID, secretAccessKey, sessionToken, location string) *http.Request {
+	return signV4Service(req, accessKeyID, secretAccessKey, sessionToken, location, serviceTypeS3)
+}
+
+// signV4STS sign the STS request before Do(), STS requests are signed
+// for the 'sts' service instead of 's3'.
+func signV4STS(req http.Request, accessKeyID, secretAccessKey, location string) *http.Request {
+	return signV4Service(req, accessKeyID, secretAccessKey, "", location, serviceTypeSTS)
+}
+
+// signV4Service sign the request for the given service.
+func signV4Service(req http.Request, accessKeyID, secretAccessKey, sessionToken, location, serviceType string) *http.Request {
 	// Signature calculation is not needed for anonymous credentials.
 	if accessKeyID == "" || secretAccessKey == "" {
 		return &req
@@ -267,23 +319,32 @@ func signV4(req http.Request, accessKeyID, secretAccessKey, location string) *ht
 	// Initial time.
 	t := time.Now().UTC()
 
-	// Set x-amz-date.
-	req.Header.Set("X-Amz-Date", t.Format(iso8601DateFormat))
+	// Honor x-amz-date if already present, set it otherwise.
+	if date, err := time.Parse(iso8601DateFormat, req.Header.Get("X-Amz-Date")); err == nil {
+		t = date
+	} else {
+		req.Header.Set("X-Amz-Date", t.Format(iso8601DateFormat))
+	}
+
+	// Set session token if available.
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
 
 	// Get canonical request.
-	canonicalRequest := getCanonicalRequest(req)
+	canonicalRequest := getCanonicalRequest(req, ignoredHeaders)
 
 	// Get string to sign from canonical request.
-	stringToSign := getStringToSignV4(t, location, canonicalRequest)
+	stringToSign := getStringToSignV4(t, location, canonicalRequest, serviceType)
 
 	// Get hmac signing key.
-	signingKey := getSigningKey(secretAccessKey, location, t)
+	signingKey := getSigningKey(secretAccessKey, location, t, serviceType)
 
 	// Get credential string.
-	credential := getCredential(accessKeyID, location, t)
+	credential := getCredential(accessKeyID, location, t, serviceType)
 
 	// Get all signed headers.
-	signedHeaders := getSignedHeaders(req)
+	signedHeaders := getSignedHeaders(req, ignoredHeaders)
 
 	// Calculate signature.
 	signature := getSignature(signingKey, stringToSign)
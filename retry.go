@@ -34,8 +34,8 @@ const MaxJitter = 1.0
 const NoJitter = 0.0
 
 // newRetryTimer creates a timer with exponentially increasing delays
-// until the maximum retry attempts are reached.
-func (c Client) newRetryTimer(maxRetry int, unit time.Duration, cap time.Duration, jitter float64) <-chan int {
+// until the maximum retry attempts are reached or doneCh is closed.
+func (c Client) newRetryTimer(maxRetry int, unit time.Duration, cap time.Duration, jitter float64, doneCh <-chan struct{}) <-chan int {
 	attemptCh := make(chan int)
 
 	// computes the exponential backoff duration according to
@@ -63,8 +63,72 @@ func (c Client) newRetryTimer(maxRetry int, unit time.Duration, cap time.Duratio
 	go func() {
 		defer close(attemptCh)
 		for i := 0; i < maxRetry; i++ {
-			attemptCh <- i + 1 // Attempts start from 1.
-			time.Sleep(exponentialBackoffWait(i))
+			select {
+			case attemptCh <- i + 1: // Attempts start from 1.
+			case <-doneCh:
+				// Stop the routine.
+				return
+			}
+			select {
+			case <-time.After(exponentialBackoffWait(i)):
+			case <-doneCh:
+				// Stop the routine.
+				return
+			}
+		}
+	}()
+	return attemptCh
+}
+
+// newRetryTimerContinous creates a timer with exponentially increasing
+// delays capped at cap, attempts continue until doneCh is closed.
+func (c Client) newRetryTimerContinous(unit time.Duration, cap time.Duration, jitter float64, doneCh <-chan struct{}) <-chan int {
+	attemptCh := make(chan int)
+
+	// normalize jitter to the range [0, 1.0]
+	if jitter < NoJitter {
+		jitter = NoJitter
+	}
+	if jitter > MaxJitter {
+		jitter = MaxJitter
+	}
+
+	// computes the exponential backoff duration according to
+	// https://www.awsarchitectureblog.com/2015/03/backoff.html
+	exponentialBackoffWait := func(attempt int) time.Duration {
+		// avoid overflowing the shift for large attempts.
+		if attempt > 30 {
+			attempt = 30
+		}
+		//sleep = random_between(0, min(cap, base * 2 ** attempt))
+		sleep := unit * time.Duration(1<<uint(attempt))
+		if sleep > cap {
+			sleep = cap
+		}
+		if jitter != NoJitter {
+			sleep -= time.Duration(c.random.Float64() * float64(sleep) * jitter)
+		}
+		return sleep
+	}
+
+	go func() {
+		defer close(attemptCh)
+		var nextBackoff int
+		for {
+			select {
+			// Attempts starts.
+			case attemptCh <- nextBackoff:
+				nextBackoff++
+			case <-doneCh:
+				// Stop the routine.
+				return
+			}
+			select {
+			case <-time.After(exponentialBackoffWait(nextBackoff)):
+			case <-doneCh:
+				// Stop the routine.
+				return
+			}
 		}
 	}()
 	return attemptCh
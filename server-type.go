@@ -0,0 +1,91 @@
+/*
+ * Minio Go Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package minio
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Server types returned by ServerType.
+const (
+	ServerTypeMinio   = "MinIO"
+	ServerTypeAmazon  = "AWS S3"
+	ServerTypeCeph    = "Ceph RGW"
+	ServerTypeUnknown = "unknown"
+)
+
+// serverType - Provides simple mechanism to hold the type of server
+// detected from its responses in memory.
+type serverType struct {
+	// mutex is used for handling the concurrent
+	// read/write requests for the type.
+	sync.RWMutex
+
+	// Detected type, empty until detected.
+	name string
+}
+
+// newServerType - Provides a new server type cache to be used
+// internally with the client object.
+func newServerType() *serverType {
+	return &serverType{}
+}
+
+// Get - Returns the detected type, ServerTypeUnknown until detected.
+func (s *serverType) Get() string {
+	s.RLock()
+	defer s.RUnlock()
+	if s.name == "" {
+		return ServerTypeUnknown
+	}
+	return s.name
+}
+
+// Update - Detects the type from the Server header of resp, once
+// detected later responses are ignored.
+func (s *serverType) Update(resp *http.Response) {
+	s.RLock()
+	detected := s.name != ""
+	s.RUnlock()
+	if detected {
+		return
+	}
+	name := parseServerType(resp.Header.Get("Server"))
+	if name == ServerTypeUnknown {
+		return
+	}
+	s.Lock()
+	s.name = name
+	s.Unlock()
+}
+
+// parseServerType - returns the server type of a Server header, e.g.
+// 'MinIO/RELEASE.2018-...', 'AmazonS3' or 'Ceph Object Gateway'.
+func parseServerType(server string) string {
+	server = strings.ToLower(server)
+	switch {
+	case strings.HasPrefix(server, "minio"):
+		return ServerTypeMinio
+	case strings.HasPrefix(server, "amazons3"):
+		return ServerTypeAmazon
+	case strings.Contains(server, "ceph"):
+		return ServerTypeCeph
+	}
+	return ServerTypeUnknown
+}
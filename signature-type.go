@@ -24,8 +24,27 @@ const (
 	Latest SignatureType = iota
 	SignatureV4
 	SignatureV2
+	SignatureAnonymous
 )
 
+// String - returns the name of the signature type.
+func (s SignatureType) String() string {
+	switch s {
+	case Latest, SignatureV4:
+		return "SignatureV4"
+	case SignatureV2:
+		return "SignatureV2"
+	case SignatureAnonymous:
+		return "SignatureAnonymous"
+	}
+	return "SignatureUnknown"
+}
+
+// isValid - is signature a known signature type?
+func (s SignatureType) isValid() bool {
+	return s >= Latest && s <= SignatureAnonymous
+}
+
 // isV2 - is signature SignatureV2?
 func (s SignatureType) isV2() bool {
 	return s == SignatureV2
@@ -35,3 +54,8 @@ func (s SignatureType) isV2() bool {
 func (s SignatureType) isV4() bool {
 	return s == SignatureV4 || s == Latest
 }
+
+// isAnonymous - is signature SignatureAnonymous?
+func (s SignatureType) isAnonymous() bool {
+	return s == SignatureAnonymous
+}
@@ -19,6 +19,7 @@ package minio
 import (
 	"bytes"
 	"crypto/hmac"
+	"crypto/md5"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/xml"
@@ -27,8 +28,10 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"path"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -47,6 +50,13 @@ func sum256(data []byte) []byte {
 	return hash.Sum(nil)
 }
 
+// sumMD5 calculate md5 sum for an input byte array.
+func sumMD5(data []byte) []byte {
+	hash := md5.New()
+	hash.Write(data)
+	return hash.Sum(nil)
+}
+
 // sumHMAC calculate hmac between two input byte array.
 func sumHMAC(key []byte, data []byte) []byte {
 	hash := hmac.New(sha256.New, key)
@@ -88,6 +98,12 @@ func getEndpointURL(endpoint string, inSecure bool) (*url.URL, error) {
 	if err := isValidEndpointURL(endpointURL); err != nil {
 		return nil, err
 	}
+
+	// Default ports are left out of the Host header by browsers and
+	// other clients of presigned URLs, they must not be signed either.
+	if port := endpointURL.Port(); (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		endpointURL.Host = strings.TrimSuffix(endpointURL.Host, ":"+port)
+	}
 	return endpointURL, nil
 }
 
@@ -124,6 +140,21 @@ func isValidIP(ip string) bool {
 	return net.ParseIP(ip) != nil
 }
 
+// maxDrainSize - remaining data of object streams up to this size is
+// drained on close to re-use the connection, the connection of larger
+// remainders is closed instead.
+const maxDrainSize = 256 * 1024
+
+// drainAndClose drains up to maxDrainSize of the remaining body and
+// closes it. Unlike closeResponse it never reads the rest of a large
+// object, the connection is closed instead and not leaked either way.
+// Recent Go versions drain small remainders on close themselves, older
+// versions always close the connection.
+func drainAndClose(body io.ReadCloser) error {
+	io.CopyN(ioutil.Discard, body, maxDrainSize)
+	return body.Close()
+}
+
 // closeResponse close non nil response with any response Body.
 // convenient wrapper to drain any remaining data on response body.
 //
@@ -218,6 +249,10 @@ var validBucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9\.\-]{1,61}[a-z0-9]$`)
 
 // isValidBucketName - verify bucket name in accordance with
 //  - http://docs.aws.amazon.com/AmazonS3/latest/dev/UsingBucket.html
+//
+// Names are DNS compliant, 3 to 63 lowercase letters, numbers, dots
+// and dashes, which cannot be formatted as an IP address. Invalid
+// names fail before any request is sent.
 func isValidBucketName(bucketName string) error {
 	if strings.TrimSpace(bucketName) == "" {
 		return ErrInvalidBucketName("Bucket name cannot be empty.")
@@ -234,9 +269,15 @@ func isValidBucketName(bucketName string) error {
 	if match, _ := regexp.MatchString("\\.\\.", bucketName); match == true {
 		return ErrInvalidBucketName("Bucket name cannot have successive periods.")
 	}
+	if strings.Contains(bucketName, ".-") || strings.Contains(bucketName, "-.") {
+		return ErrInvalidBucketName("Bucket name cannot have a '-' dash next to a '.' dot.")
+	}
 	if !validBucketName.MatchString(bucketName) {
 		return ErrInvalidBucketName("Bucket name contains invalid characters.")
 	}
+	if net.ParseIP(bucketName) != nil {
+		return ErrInvalidBucketName("Bucket name cannot be an IP address.")
+	}
 	return nil
 }
 
@@ -247,10 +288,10 @@ func isValidObjectName(objectName string) error {
 		return ErrInvalidObjectName("Object name cannot be empty.")
 	}
 	if len(objectName) > 1024 {
-		return ErrInvalidObjectName("Object name cannot be greater than 1024 characters.")
+		return ErrInvalidObjectName("Object name cannot be greater than 1024 bytes in UTF-8.")
 	}
 	if !utf8.ValidString(objectName) {
-		return ErrInvalidBucketName("Object name with non UTF-8 strings are not supported.")
+		return ErrInvalidObjectName("Object name with non UTF-8 strings are not supported.")
 	}
 	return nil
 }
@@ -291,6 +332,17 @@ func queryEncode(v url.Values) string {
 	return buf.String()
 }
 
+// normalizeObjectName - removes leading and duplicate slashes and
+// resolves '.' and '..' segments of an object name, '..' never goes
+// above the top level. A trailing slash is kept.
+func normalizeObjectName(objectName string) string {
+	normalized := strings.TrimPrefix(path.Clean("/"+objectName), "/")
+	if normalized != "" && strings.HasSuffix(objectName, "/") {
+		normalized += "/"
+	}
+	return normalized
+}
+
 // urlEncodePath encode the strings from UTF-8 byte representations to HTML hex escape sequences
 //
 // This is necessary since regular url.Parse() and url.Encode() functions do not support UTF-8
@@ -330,3 +382,18 @@ func urlEncodePath(pathName string) string {
 	}
 	return encodedPathname
 }
+
+// parseContentRangeSize - parses the total object size from a
+// Content-Range header value of the form 'bytes start-end/size'.
+func parseContentRangeSize(contentRange string) (int64, error) {
+	i := strings.LastIndex(contentRange, "/")
+	if !strings.HasPrefix(contentRange, "bytes ") || i == -1 {
+		return -1, ErrInvalidArgument("Invalid Content-Range " + contentRange + ".")
+	}
+	// Total size may be unknown, represented as '*'.
+	size, err := strconv.ParseInt(contentRange[i+1:], 10, 64)
+	if err != nil || size < 0 {
+		return -1, ErrInvalidArgument("Content-Range " + contentRange + " has no valid total size.")
+	}
+	return size, nil
+}